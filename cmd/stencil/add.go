@@ -0,0 +1,70 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains the stencil add command, which adds a
+// module to the service manifest and renders it.
+
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+
+	"github.com/getoutreach/stencil/pkg/configuration"
+)
+
+// NewAddCommand returns a new urfave/cli.Command for the add command.
+func NewAddCommand(log logrus.FieldLogger) *cli.Command {
+	return &cli.Command{
+		Name:      "add",
+		Usage:     "Add a module to the service.yaml and render it",
+		ArgsUsage: "<module>[@version]",
+		Description: "Adds the module to the service.yaml, keeping its comments, and runs stencil. Any required " +
+			"arguments of the module that aren't set are prompted for. If the module is already in the service.yaml " +
+			"its version and channel are updated. " +
+			"The service.yaml is restored if rendering fails, and isn't changed with --dry-run.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "channel",
+				Usage: "The channel to use for updates to the module, e.g. rc",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.NArg() != 1 {
+				return errors.New("expected exactly one argument, the module to add")
+			}
+			if err := checkWorktree(ctx, c, ""); err != nil {
+				return err
+			}
+
+			original, err := os.ReadFile(serviceManifestFile)
+			if err != nil {
+				return errors.Wrap(err, "service.yaml must exist")
+			}
+
+			doc, err := configuration.ParseServiceManifestDocument(original)
+			if err != nil {
+				return err
+			}
+
+			m := parseModuleArg(c.Args().First())
+			m.Channel = c.String("channel")
+			if err := doc.AddModule(m); err != nil {
+				return err
+			}
+
+			log.Infof("Adding module %s to %s", m.Name, serviceManifestFile)
+			return renderEditedManifest(ctx, log, c, doc, original)
+		},
+	}
+}
+
+// parseModuleArg parses a module in the form of <name>[@version].
+func parseModuleArg(arg string) *configuration.TemplateRepository {
+	name, version, _ := strings.Cut(arg, "@")
+	return &configuration.TemplateRepository{Name: name, Version: version}
+}
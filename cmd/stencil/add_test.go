@@ -0,0 +1,182 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: Tests for the stencil add and remove commands.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/getoutreach/gobox/pkg/app"
+	"github.com/urfave/cli/v3"
+	"gotest.tools/v3/assert"
+
+	"github.com/getoutreach/stencil/internal/git"
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/getoutreach/stencil/pkg/stencil"
+)
+
+func TestParseModuleArg(t *testing.T) {
+	assert.DeepEqual(t, parseModuleArg("github.com/getoutreach/stencil-base"),
+		&configuration.TemplateRepository{Name: "github.com/getoutreach/stencil-base"})
+	assert.DeepEqual(t, parseModuleArg("github.com/getoutreach/stencil-base@v1.2.3"),
+		&configuration.TemplateRepository{Name: "github.com/getoutreach/stencil-base", Version: "v1.2.3"})
+}
+
+func TestRemoveModuleFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, name := range []string{"a.go", "b.go", "shared.go"} {
+		assert.NilError(t, os.WriteFile(name, []byte("package main\n"), 0o644))
+	}
+
+	oldLock := &stencil.Lockfile{Files: []*stencil.LockfileFileEntry{
+		{Name: "a.go", Module: "github.com/getoutreach/removed"},
+		{Name: "shared.go", Module: "github.com/getoutreach/removed"},
+		{Name: "missing.go", Module: "github.com/getoutreach/removed"},
+		{Name: "b.go", Module: "github.com/getoutreach/kept"},
+	}}
+	newLock := &stencil.Lockfile{Files: []*stencil.LockfileFileEntry{
+		{Name: "shared.go", Module: "github.com/getoutreach/kept"},
+		{Name: "b.go", Module: "github.com/getoutreach/kept"},
+	}}
+
+	assert.NilError(t, removeModuleFiles(discardLogger(), "github.com/getoutreach/removed", oldLock, newLock))
+
+	_, err := os.Stat("a.go")
+	assert.Assert(t, os.IsNotExist(err), "expected a.go to be deleted")
+	for _, name := range []string{"b.go", "shared.go"} {
+		_, err := os.Stat(name)
+		assert.NilError(t, err, "expected %s to be kept", name)
+	}
+}
+
+// newTestApp returns an app with the global flags read when rendering, and
+// cmd as its only subcommand. The stencil version is set for the duration
+// of the test, development builds don't have one.
+func newTestApp(t *testing.T, cmd *cli.Command) *cli.Command {
+	version := app.Version
+	app.Version = "v1.0.0"
+	t.Cleanup(func() { app.Version = version })
+
+	return &cli.Command{
+		Name:   "stencil",
+		Writer: io.Discard,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "dry-run"},
+			&cli.BoolFlag{Name: "no-prompt", Value: true},
+			&cli.BoolFlag{Name: "require-clean"},
+			&cli.BoolFlag{Name: "commit"},
+			&cli.StringFlag{Name: "commit-branch"},
+			&cli.StringFlag{Name: "output-dir"},
+			&cli.StringFlag{Name: "output-tar"},
+			&cli.StringFlag{Name: "post-run-report"},
+			&cli.StringFlag{Name: "project-dir"},
+		},
+		Before:   changeProjectDir,
+		Commands: []*cli.Command{cmd},
+	}
+}
+
+func TestAddDryRunDoesNotSaveServiceManifest(t *testing.T) {
+	modDir := writeLocalModule(t, "name: github.com/x/a\n")
+	t.Chdir(t.TempDir())
+
+	original := "# my service\nname: s\nreplacements:\n  github.com/x/a: file://" + modDir + "\n"
+	assert.NilError(t, os.WriteFile("service.yaml", []byte(original), 0o644))
+
+	root := newTestApp(t, NewAddCommand(discardLogger()))
+	assert.NilError(t, root.Run(context.Background(), []string{"stencil", "--dry-run", "add", "github.com/x/a"}))
+
+	b, err := os.ReadFile("service.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), original)
+	_, err = os.Stat("stencil.lock")
+	assert.Assert(t, errors.Is(err, os.ErrNotExist), "expected no stencil.lock to be written")
+}
+
+func TestRemoveDryRunDoesNotSaveServiceManifest(t *testing.T) {
+	modDir := writeLocalModule(t, "name: github.com/x/a\n")
+	t.Chdir(t.TempDir())
+
+	original := "name: s\nmodules:\n  - name: github.com/x/a\nreplacements:\n  github.com/x/a: file://" + modDir + "\n"
+	assert.NilError(t, os.WriteFile("service.yaml", []byte(original), 0o644))
+
+	root := newTestApp(t, NewRemoveCommand(discardLogger()))
+	assert.NilError(t, root.Run(context.Background(), []string{"stencil", "--dry-run", "remove", "github.com/x/a"}))
+
+	b, err := os.ReadFile("service.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), original)
+}
+
+// commitAll initializes a git repository in the current directory, if
+// there isn't one, and commits every file in it.
+func commitAll(t *testing.T) {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "Test Author")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test Author")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"add", "--all"},
+		{"commit", "--quiet", "--message", "initial commit"},
+	} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		assert.NilError(t, err, string(out))
+	}
+}
+
+// writeLocalTemplateModule writes a module named github.com/x/a with a
+// template rendering hello.txt, and returns its directory.
+func writeLocalTemplateModule(t *testing.T) string {
+	t.Helper()
+	modDir := writeLocalModule(t, "name: github.com/x/a\n")
+	assert.NilError(t, os.MkdirAll(filepath.Join(modDir, "templates"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(modDir, "templates", "hello.txt.tpl"), []byte("hello\n"), 0o644))
+	return modDir
+}
+
+func TestAddCommit(t *testing.T) {
+	modDir := writeLocalTemplateModule(t)
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("service.yaml",
+		[]byte("name: s\nreplacements:\n  github.com/x/a: file://"+modDir+"\n"), 0o644))
+	commitAll(t)
+
+	root := newTestApp(t, NewAddCommand(discardLogger()))
+	assert.NilError(t, root.Run(context.Background(),
+		[]string{"stencil", "--commit", "--commit-branch", "add-a", "add", "github.com/x/a"}))
+
+	out, err := exec.Command("git", "status", "--porcelain").Output()
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), "", "expected every change to be committed")
+
+	out, err = exec.Command("git", "show", "--name-only", "--format=%D", "HEAD").Output()
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), "HEAD -> add-a\n\nhello.txt\nservice.yaml\nstencil.lock\n")
+}
+
+func TestAddRequireCleanDirtyWorktree(t *testing.T) {
+	modDir := writeLocalTemplateModule(t)
+	t.Chdir(t.TempDir())
+	original := "name: s\nreplacements:\n  github.com/x/a: file://" + modDir + "\n"
+	assert.NilError(t, os.WriteFile("service.yaml", []byte(original), 0o644))
+	commitAll(t)
+	assert.NilError(t, os.WriteFile("untracked.txt", []byte("wip\n"), 0o644))
+
+	root := newTestApp(t, NewAddCommand(discardLogger()))
+	err := root.Run(context.Background(), []string{"stencil", "--require-clean", "add", "github.com/x/a"})
+	assert.ErrorIs(t, err, git.ErrDirtyWorktree)
+
+	b, err := os.ReadFile("service.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), original)
+}
@@ -0,0 +1,340 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains the stencil blocks command, which lists,
+// exports and imports the blocks of the files generated by stencil.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+
+	"github.com/getoutreach/stencil/internal/codegen"
+	"github.com/getoutreach/stencil/pkg/stencil"
+)
+
+// blocksFile is a file generated by stencil and the blocks in it, this is
+// both the JSON output of `stencil blocks` and the format used by
+// `stencil blocks export` and `stencil blocks import`.
+type blocksFile struct {
+	// Name is the path to the file, relative to the repository root.
+	Name string `json:"name"`
+
+	// Module is the module that generated the file.
+	Module string `json:"module,omitempty"`
+
+	// Blocks are the blocks in the file.
+	Blocks []*blockInfo `json:"blocks"`
+}
+
+// blockInfo is a block in a file generated by stencil.
+type blockInfo struct {
+	// Name is the name of the block.
+	Name string `json:"name"`
+
+	// StartLine is the line number of the opening tag of the block.
+	StartLine int `json:"startLine,omitempty"`
+
+	// EndLine is the line number of the closing tag of the block.
+	EndLine int `json:"endLine,omitempty"`
+
+	// Empty is true if the block only contains whitespace.
+	Empty bool `json:"empty"`
+
+	// Contents are the contents of the block, only set when exporting.
+	Contents string `json:"contents,omitempty"`
+}
+
+// NewBlocksCommand returns a new urfave/cli.Command for the blocks
+// command.
+func NewBlocksCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "blocks",
+		Usage: "List the blocks of the files generated by stencil",
+		Description: "Lists every block in every file generated by stencil, as recorded in stencil.lock, " +
+			"along with its line range and whether it's empty",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "output the blocks as JSON",
+			},
+		},
+		Commands: []*cli.Command{newBlocksExportCommand(), newBlocksImportCommand()},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			files, err := readGeneratedBlocks(ctx, newBlocksLogger(c), false)
+			if err != nil {
+				return err
+			}
+			return printBlocks(os.Stdout, files, c.Bool("json"))
+		},
+	}
+}
+
+// newBlocksExportCommand returns the `blocks export [path]` subcommand.
+func newBlocksExportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "export",
+		Usage:     "Export the contents of the non-empty blocks as JSON",
+		ArgsUsage: "[path]",
+		Description: "Writes the contents of every non-empty block in the files generated by stencil as JSON to path, " +
+			"or stdout if not provided. The output can be applied to another repository with 'stencil blocks import'.",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			files, err := readGeneratedBlocks(ctx, newBlocksLogger(c), true)
+			if err != nil {
+				return err
+			}
+
+			if c.NArg() == 0 || c.Args().First() == "-" {
+				return writeBlocksJSON(os.Stdout, files)
+			}
+
+			var buf bytes.Buffer
+			if err := writeBlocksJSON(&buf, files); err != nil {
+				return err
+			}
+			return errors.Wrap(os.WriteFile(c.Args().First(), buf.Bytes(), 0o644), "failed to write blocks")
+		},
+	}
+}
+
+// newBlocksImportCommand returns the `blocks import <path>` subcommand.
+func newBlocksImportCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import",
+		Usage:     "Import block contents exported by 'stencil blocks export'",
+		ArgsUsage: "<path>",
+		Description: "Replaces the contents of the blocks in the files of the current repository with the contents " +
+			"written by 'stencil blocks export'. Use '-' to read from stdin. Blocks that don't exist in the " +
+			"current repository are skipped.",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.NArg() != 1 {
+				return errors.New("expected exactly one argument, path to the exported blocks")
+			}
+
+			var r io.Reader = os.Stdin
+			if path := c.Args().First(); path != "-" {
+				f, err := os.Open(path)
+				if err != nil {
+					return errors.Wrap(err, "failed to open exported blocks")
+				}
+				defer f.Close()
+				r = f
+			}
+
+			var files []*blocksFile
+			if err := json.NewDecoder(r).Decode(&files); err != nil {
+				return errors.Wrap(err, "failed to parse exported blocks")
+			}
+
+			return importBlocks(ctx, newBlocksLogger(c), files)
+		},
+	}
+}
+
+// newBlocksLogger returns the logger used by the blocks commands.
+func newBlocksLogger(c *cli.Command) logrus.FieldLogger {
+	log := logrus.New()
+	if c.Bool("debug") {
+		log.SetLevel(logrus.DebugLevel)
+	}
+	return log
+}
+
+// projectCommentStyles returns the comment styles declared by the modules
+// of the current project, merged in the order the modules were resolved,
+// so that blocks are found in files the same way they are when rendering.
+func projectCommentStyles(ctx context.Context, log logrus.FieldLogger) (map[string]string, error) {
+	serviceManifest, err := loadServiceManifest(ctx, log)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse service.yaml")
+	}
+
+	mods, err := resolveProjectModules(ctx, log, serviceManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	styles := make(map[string]string)
+	for _, m := range mods {
+		mf, err := m.Manifest(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read module %q manifest", m.Name)
+		}
+		maps.Copy(styles, mf.CommentStyles)
+	}
+	return styles, nil
+}
+
+// readGeneratedBlocks returns the blocks of the files recorded in
+// stencil.lock that exist on disk. When exporting, only non-empty blocks
+// are returned along with their contents.
+func readGeneratedBlocks(ctx context.Context, log logrus.FieldLogger, export bool) ([]*blocksFile, error) {
+	l, err := stencil.LoadLockfile("")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load lockfile")
+	}
+
+	styles, err := projectCommentStyles(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*blocksFile, 0)
+	for _, f := range l.Files {
+		// Assets and symlinks are never rendered, so they can't have blocks
+		if f.Type != "" {
+			continue
+		}
+
+		data, err := os.ReadFile(f.Name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %q", f.Name)
+		}
+
+		blocks, err := codegen.ListBlocks(bytes.NewReader(data), f.Name, codegen.CommentStyleFor(f.Name, styles))
+		if err != nil {
+			return nil, err
+		}
+
+		bf := &blocksFile{Name: f.Name, Module: f.Module}
+		for _, b := range blocks {
+			info := &blockInfo{Name: b.Name, StartLine: b.StartLine, EndLine: b.EndLine, Empty: b.IsEmpty()}
+			if export {
+				if info.Empty {
+					continue
+				}
+				info = &blockInfo{Name: b.Name, Contents: b.Contents}
+			}
+			bf.Blocks = append(bf.Blocks, info)
+		}
+
+		if len(bf.Blocks) != 0 {
+			files = append(files, bf)
+		}
+	}
+
+	slices.SortFunc(files, func(a, b *blocksFile) int { return strings.Compare(a.Name, b.Name) })
+	return files, nil
+}
+
+// printBlocks writes the blocks of files to w, either as a table or as
+// JSON.
+func printBlocks(w io.Writer, files []*blocksFile, asJSON bool) error {
+	if asJSON {
+		return writeBlocksJSON(w, files)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tBLOCK\tLINES\tEMPTY")
+	for _, f := range files {
+		for _, b := range f.Blocks {
+			fmt.Fprintf(tw, "%s\t%s\t%d-%d\t%t\n", f.Name, b.Name, b.StartLine, b.EndLine, b.Empty)
+		}
+	}
+	return errors.Wrap(tw.Flush(), "failed to write blocks")
+}
+
+// writeBlocksJSON writes files to w as indented JSON.
+func writeBlocksJSON(w io.Writer, files []*blocksFile) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(files), "failed to encode blocks")
+}
+
+// importBlocks replaces the contents of the blocks in the files on disk
+// with the contents in files. Files and blocks that don't exist on disk are
+// skipped.
+func importBlocks(ctx context.Context, log logrus.FieldLogger, files []*blocksFile) error {
+	styles, err := projectCommentStyles(ctx, log)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		info, err := os.Stat(f.Name)
+		if errors.Is(err, os.ErrNotExist) {
+			log.Warnf("Skipping blocks of %q, the file doesn't exist", f.Name)
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to stat %q", f.Name)
+		}
+
+		data, err := os.ReadFile(f.Name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %q", f.Name)
+		}
+
+		contents := make(map[string]string, len(f.Blocks))
+		for _, b := range f.Blocks {
+			contents[b.Name] = b.Contents
+		}
+
+		out, replaced, err := replaceBlocks(data, f.Name, contents, styles)
+		if err != nil {
+			return err
+		}
+
+		for _, b := range f.Blocks {
+			if !slices.Contains(replaced, b.Name) {
+				log.Warnf("Skipping block %q of %q, the block doesn't exist", b.Name, f.Name)
+			}
+		}
+		if len(replaced) == 0 || bytes.Equal(out, data) {
+			continue
+		}
+
+		if err := os.WriteFile(f.Name, out, info.Mode().Perm()); err != nil {
+			return errors.Wrapf(err, "failed to write %q", f.Name)
+		}
+		log.Infof("Imported %d block(s) into %s", len(replaced), f.Name)
+	}
+	return nil
+}
+
+// replaceBlocks replaces the lines between the tags of the blocks in data,
+// the contents of the file at path, with the provided contents keyed by
+// block name. styles are the comment styles of the project's modules. If
+// a block appears more than once only the first occurrence is replaced.
+// The names of the replaced blocks are returned.
+func replaceBlocks(data []byte, path string, contents, styles map[string]string) ([]byte, []string, error) {
+	blocks, err := codegen.ListBlocks(bytes.NewReader(data), path, codegen.CommentStyleFor(path, styles))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	replaced := make([]string, 0)
+	lines := strings.Split(string(data), "\n")
+	out := make([]string, 0, len(lines))
+	next := 0
+	for _, b := range blocks {
+		c, ok := contents[b.Name]
+		if !ok || slices.Contains(replaced, b.Name) {
+			continue
+		}
+
+		// keep everything up to, and including, the opening tag
+		out = append(out, lines[next:b.StartLine]...)
+		if c != "" {
+			out = append(out, strings.Split(c, "\n")...)
+		}
+		next = b.EndLine - 1
+		replaced = append(replaced, b.Name)
+	}
+	out = append(out, lines[next:]...)
+
+	return []byte(strings.Join(out, "\n")), replaced, nil
+}
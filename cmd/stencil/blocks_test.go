@@ -0,0 +1,158 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: Tests for the stencil blocks command.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// writeBlocksRepo creates a repository in a temporary directory, with a
+// lockfile tracking main.go and a symlink.
+func writeBlocksRepo(t *testing.T, mainGo string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+
+	assert.NilError(t, os.WriteFile("service.yaml", []byte("name: test\n"), 0o644))
+	assert.NilError(t, os.WriteFile("stencil.lock", []byte(`files:
+  - name: main.go
+    template: main.go.tpl
+    module: github.com/getoutreach/stencil-base
+  - name: link
+    template: link.tpl
+    module: github.com/getoutreach/stencil-base
+    type: symlink
+    target: main.go
+  - name: missing.go
+    template: missing.go.tpl
+    module: github.com/getoutreach/stencil-base
+`), 0o644))
+	assert.NilError(t, os.WriteFile("main.go", []byte(mainGo), 0o644))
+	assert.NilError(t, os.Symlink("main.go", "link"))
+}
+
+const blocksMainGo = `package main
+
+// <<Stencil::Block(imports)>>
+import "fmt"
+// <</Stencil::Block>>
+
+func main() {
+	// <<Stencil::Block(main)>>
+	// <</Stencil::Block>>
+}
+`
+
+func TestPrintBlocks(t *testing.T) {
+	writeBlocksRepo(t, blocksMainGo)
+
+	files, err := readGeneratedBlocks(context.Background(), discardLogger(), false)
+	assert.NilError(t, err)
+
+	var buf bytes.Buffer
+	assert.NilError(t, printBlocks(&buf, files, false))
+	assert.Equal(t, buf.String(), `FILE     BLOCK    LINES  EMPTY
+main.go  imports  3-5    false
+main.go  main     8-9    true
+`)
+
+	buf.Reset()
+	assert.NilError(t, printBlocks(&buf, files, true))
+	assert.Equal(t, buf.String(), `[
+  {
+    "name": "main.go",
+    "module": "github.com/getoutreach/stencil-base",
+    "blocks": [
+      {
+        "name": "imports",
+        "startLine": 3,
+        "endLine": 5,
+        "empty": false
+      },
+      {
+        "name": "main",
+        "startLine": 8,
+        "endLine": 9,
+        "empty": true
+      }
+    ]
+  }
+]
+`)
+}
+
+func TestExportImportBlocks(t *testing.T) {
+	writeBlocksRepo(t, blocksMainGo)
+
+	exported, err := readGeneratedBlocks(context.Background(), discardLogger(), true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(exported), 1)
+	assert.DeepEqual(t, exported[0].Blocks, []*blockInfo{{Name: "imports", Contents: `import "fmt"`}})
+
+	// Import into a repository where the imports block is empty and the
+	// main block has been customized.
+	writeBlocksRepo(t, `package main
+
+// <<Stencil::Block(imports)>>
+// <</Stencil::Block>>
+
+func main() {
+	// <<Stencil::Block(main)>>
+	fmt.Println("hello")
+	// <</Stencil::Block>>
+}
+`)
+	exported[0].Blocks = append(exported[0].Blocks,
+		&blockInfo{Name: "main", Contents: "\tfmt.Println(\"hello, world\")\n\tfmt.Println(\"bye\")"},
+		&blockInfo{Name: "unknown", Contents: "ignored"})
+	assert.NilError(t, importBlocks(context.Background(), discardLogger(), exported))
+
+	got, err := os.ReadFile("main.go")
+	assert.NilError(t, err)
+	assert.Equal(t, string(got), `package main
+
+// <<Stencil::Block(imports)>>
+import "fmt"
+// <</Stencil::Block>>
+
+func main() {
+	// <<Stencil::Block(main)>>
+	fmt.Println("hello, world")
+	fmt.Println("bye")
+	// <</Stencil::Block>>
+}
+`)
+}
+
+func TestBlocksUseModuleCommentStyles(t *testing.T) {
+	modDir := writeLocalModule(t, "name: github.com/x/a\ncommentStyles:\n  .fnl: \";;\"\n")
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("service.yaml", []byte("name: test\nmodules:\n  - name: github.com/x/a\n"+
+		"replacements:\n  github.com/x/a: file://"+modDir+"\n"), 0o644))
+	assert.NilError(t, os.WriteFile("stencil.lock", []byte(`files:
+  - name: src/core.fnl
+    template: core.fnl.tpl
+    module: github.com/x/a
+`), 0o644))
+	assert.NilError(t, os.MkdirAll("src", 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join("src", "core.fnl"),
+		[]byte("(ns core)\n;; <<Stencil::Block(fns)>>\n(defn f [] 1)\n;; <</Stencil::Block>>\n"), 0o644))
+
+	exported, err := readGeneratedBlocks(context.Background(), discardLogger(), true)
+	assert.NilError(t, err)
+	assert.Equal(t, len(exported), 1)
+	assert.DeepEqual(t, exported[0].Blocks, []*blockInfo{{Name: "fns", Contents: "(defn f [] 1)"}})
+
+	exported[0].Blocks[0].Contents = "(defn g [] 2)"
+	assert.NilError(t, importBlocks(context.Background(), discardLogger(), exported))
+	got, err := os.ReadFile(filepath.Join("src", "core.fnl"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(got), "(ns core)\n;; <<Stencil::Block(fns)>>\n(defn g [] 2)\n;; <</Stencil::Block>>\n")
+}
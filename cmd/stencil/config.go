@@ -0,0 +1,94 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains code for the config command
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/getoutreach/stencil/internal/cmd/stencil"
+	"github.com/getoutreach/stencil/internal/codegen"
+	"github.com/getoutreach/stencil/pkg/configuration"
+)
+
+// NewConfigCommand returns a new urfave/cli.Command for the
+// config command.
+func NewConfigCommand(log logrus.FieldLogger) *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Commands to inspect the service.yaml",
+		Commands: []*cli.Command{
+			{
+				Name:  "show",
+				Usage: "Print the service.yaml",
+				Description: "Prints the service.yaml. With --resolved, the effective service.yaml is printed instead: the " +
+					"service manifests it extends are merged into it and the references in its arguments are resolved, " +
+					"with the values of secrets redacted.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "resolved",
+						Usage: "Print the effective service.yaml, after merging the service manifests it extends",
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if !c.Bool("resolved") {
+						f, err := os.Open(serviceManifestFile)
+						if err != nil {
+							return errors.Wrap(err, "failed to read service.yaml")
+						}
+						defer f.Close()
+
+						_, err = io.Copy(os.Stdout, f)
+						return err
+					}
+
+					serviceManifest, err := loadServiceManifest(ctx, log)
+					if err != nil {
+						return errors.Wrap(err, "failed to parse service.yaml")
+					}
+
+					b, err := resolvedServiceManifest(ctx, log, serviceManifest)
+					if err != nil {
+						return err
+					}
+					_, err = os.Stdout.Write(b)
+					return err
+				},
+			},
+		},
+	}
+}
+
+// resolvedServiceManifest returns the encoded service manifest with the
+// references in its arguments resolved, and the values of secrets
+// redacted.
+func resolvedServiceManifest(ctx context.Context, log logrus.FieldLogger,
+	serviceManifest *configuration.ServiceManifest) ([]byte, error) {
+	st := codegen.NewStencil(serviceManifest, nil, log)
+	if err := st.ResolveArguments(ctx, "."); err != nil {
+		return nil, err
+	}
+	if args, ok := stencil.RedactValue(serviceManifest.Arguments, st.Secrets()).(map[string]any); ok {
+		serviceManifest.Arguments = args
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(serviceManifest); err != nil {
+		return nil, errors.Wrap(err, "failed to encode service.yaml")
+	}
+	if err := enc.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to encode service.yaml")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: Tests for the stencil config command.
+
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestResolvedServiceManifest(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("STENCIL_TEST_REGION", "eu-west-1")
+	assert.NilError(t, os.WriteFile("base.yaml", []byte(`arguments:
+  team: platform
+  region: us-east-1
+modules:
+  - name: github.com/getoutreach/stencil-base
+`), 0o644))
+	assert.NilError(t, os.WriteFile(serviceManifestFile, []byte(`name: test
+extends: [base.yaml]
+arguments:
+  region: ${env:STENCIL_TEST_REGION}
+`), 0o644))
+
+	ctx := context.Background()
+	serviceManifest, err := loadServiceManifest(ctx, discardLogger())
+	assert.NilError(t, err)
+
+	b, err := resolvedServiceManifest(ctx, discardLogger(), serviceManifest)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), `name: test
+arguments:
+  region: eu-west-1
+  team: platform
+modules:
+  - name: github.com/getoutreach/stencil-base
+`)
+}
@@ -5,17 +5,14 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"os"
 	"os/exec"
-	"strings"
 
 	"github.com/getoutreach/stencil/pkg/configuration"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v3"
-	"go.yaml.in/yaml/v3"
 )
 
 // NewConfigureModuleCmd returns a new urfave/cli.Command for the
@@ -33,7 +30,7 @@ func NewConfigureModuleCmd() *cli.Command {
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			// Call readAndMergeServiceYaml to update the service yaml to add or remove the native-extension fields.
-			if err := readAndMergeServiceYaml("service.yaml", c.Bool("remove-native-extension"), "foo"); err != nil {
+			if err := readAndMergeServiceYaml("service.yaml", c.Bool("remove-native-extension")); err != nil {
 				if err.Error() == "no action" {
 					return nil
 				}
@@ -50,72 +47,46 @@ func NewConfigureModuleCmd() *cli.Command {
 }
 
 // readAndMergeServiceYaml takes a path and a bool and updates the service.yaml to create/remove fields
-// associated with native-extensions.
-func readAndMergeServiceYaml(path string, removeNativeExtension bool, input string) error {
+// associated with native-extensions. The comments and the order of keys in the service.yaml are preserved.
+func readAndMergeServiceYaml(path string, removeNativeExtension bool) error {
 	log := logrus.New()
 	if path == "" {
 		path = "service.yaml"
 	}
-	var tm = &configuration.ServiceManifest{}
 
 	if _, err := os.Stat(path); err != nil {
 		return errors.Wrap(err, "service.yaml must exist")
 	}
 
-	b, err := os.ReadFile(path)
+	doc, err := configuration.LoadServiceManifestDocument(path)
 	if err != nil {
 		return err
 	}
 
-	if err := yaml.Unmarshal(b, tm); err != nil {
-		return err
-	}
-
-	releaseOpts := map[string]any{
-		"enablePrereleases": true,
-	}
-
 	if !removeNativeExtension {
-		// Note that for any additions to the configure command this line may need to be updated/removed.
-		// This section avoids removing any comments in the service.yaml file by overwriting it if there's no action to be taken.
-		if tm.Arguments["plugin"] == true && releaseOpts["force"] == true {
+		plugin, _ := doc.Argument("plugin")
+		force, _ := doc.Argument("releaseOptions.force")
+		if plugin == true && force == true {
 			log.Info("The module is already a native extension, no action taken.")
 			return errors.New("no action")
 		}
-		tm.Arguments["plugin"] = true
-		releaseOpts["force"] = true
-	} else {
-		// Note that for any additions to the configure command this line may need to be updated/removed.
-		// This section avoids removing any comments in the service.yaml file by overwriting it if there's no action to be taken.
-		_, ok := tm.Arguments["plugin"]
-		if !ok {
-			log.Info("The module is already not a native extension, no action taken.")
-			return errors.New("no action")
-		}
-		delete(tm.Arguments, "plugin")
-	}
-	tm.Arguments["releaseOptions"] = releaseOpts
 
-	if input != "" {
-		reader := bufio.NewReader(os.Stdin)
-		log.Info("This will remove any comments in your service.yaml, are you sure you want to proceed? y/N")
-		text, err := reader.ReadString('\n')
-		if err != nil {
-			return errors.Wrap(err, "failed to get user input for overwriting service.yaml")
+		if err := doc.SetArgument("plugin", true); err != nil {
+			return err
+		}
+		if err := doc.SetArgument("releaseOptions.force", true); err != nil {
+			return err
 		}
-		if strings.TrimSpace(text) != "y" {
-			log.Info("No action taken")
+	} else {
+		if !doc.RemoveArgument("plugin") {
+			log.Info("The module is already not a native extension, no action taken.")
 			return errors.New("no action")
 		}
+		doc.RemoveArgument("releaseOptions.force")
 	}
 
-	out, err := yaml.Marshal(tm)
-	if err != nil {
-		return err
-	}
-
-	if err := os.WriteFile(path, out, 0o600); err != nil {
+	if err := doc.SetArgument("releaseOptions.enablePrereleases", true); err != nil {
 		return err
 	}
-	return nil
+	return doc.Save(path)
 }
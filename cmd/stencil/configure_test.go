@@ -151,7 +151,7 @@ func TestConfigureModule(t *testing.T) {
 			assert.NilError(t, err, "failed to unmarshal expected yaml")
 
 			// configure the service.yaml and compare to expected
-			err = readAndMergeServiceYaml(tempFile, test.RemoveNativeExtensionFlag, "")
+			err = readAndMergeServiceYaml(tempFile, test.RemoveNativeExtensionFlag)
 			if test.ShouldError == true {
 				assert.Error(t, err, "no action")
 			} else {
@@ -168,3 +168,30 @@ func TestConfigureModule(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigureModulePreservesComments(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "service.yaml")
+	assert.NilError(t, os.WriteFile(tempFile, []byte(`# The service
+name: test
+arguments:
+  # Owned by the platform team
+  reportingTeam: test_name
+  releaseOptions:
+    enablePrereleases: true
+`), 0o644))
+
+	assert.NilError(t, readAndMergeServiceYaml(tempFile, false))
+
+	b, err := os.ReadFile(tempFile)
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), `# The service
+name: test
+arguments:
+  # Owned by the platform team
+  reportingTeam: test_name
+  releaseOptions:
+    enablePrereleases: true
+    force: true
+  plugin: true
+`)
+}
@@ -5,17 +5,19 @@
 package main
 
 import (
+	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v3"
 )
 
 // NewCreateCommand returns a new urfave/cli.Command for the
 // create command.
-func NewCreateCommand() *cli.Command {
+func NewCreateCommand(log logrus.FieldLogger) *cli.Command {
 	return &cli.Command{
 		Name:        "create",
 		Description: "Commands to create template repositories, or stencil powered repositories",
 		Commands: []*cli.Command{
 			NewCreateModule(),
+			NewCreateProject(log),
 		},
 	}
 }
@@ -0,0 +1,236 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains code for the create project command
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/getoutreach/gobox/pkg/cli/prompt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/term"
+
+	"github.com/getoutreach/stencil/internal/cmd/stencil"
+	"github.com/getoutreach/stencil/internal/codegen"
+	"github.com/getoutreach/stencil/pkg/configuration"
+)
+
+// ErrArchetypeNotFound is returned when the requested archetype doesn't
+// exist in the catalog or module.
+var ErrArchetypeNotFound = errors.New("archetype not found")
+
+// ErrNoArchetypes is returned when the catalog or module doesn't declare
+// any archetypes.
+var ErrNoArchetypes = errors.New("no archetypes found")
+
+// ErrArchetypeQuestionUnanswered is returned when a question has no
+// default and can't be asked because stdin isn't a terminal.
+var ErrArchetypeQuestionUnanswered = errors.New("unable to ask question, stdin is not a terminal and it has no default")
+
+// NewCreateProject returns a new urfave/cli.Command for the
+// create project command.
+func NewCreateProject(log logrus.FieldLogger) *cli.Command {
+	return &cli.Command{
+		Name:  "project",
+		Usage: "Create a project in the current directory from an archetype",
+		Description: "Creates a project with the provided name in the current directory from an archetype, a starter " +
+			"service.yaml declared by a catalog file (--catalog) or by the manifest of a module (--from). The questions " +
+			"of the archetype are asked, the service.yaml is written and stencil is ran.",
+		ArgsUsage: "<name>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "catalog",
+				Usage: "Path to a catalog file listing archetypes",
+			},
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "A module, in the form of <module>[@version], whose manifest declares archetypes",
+			},
+			&cli.StringFlag{
+				Name:  "archetype",
+				Usage: "The name of the archetype to use, prompted for if not set",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.NArg() != 1 {
+				return errors.New("must provide a name for the project")
+			}
+			name := c.Args().First()
+			if !configuration.ValidateName(name) {
+				return fmt.Errorf("%w: %q", configuration.ErrInvalidName, name)
+			}
+
+			if _, err := os.Stat(serviceManifestFile); err == nil {
+				return fmt.Errorf("%w: %s", ErrManifestExists, serviceManifestFile)
+			}
+
+			archetypes, err := loadArchetypes(ctx, log, c.String("catalog"), c.String("from"))
+			if err != nil {
+				return err
+			}
+
+			interactive := term.IsTerminal(int(os.Stdin.Fd()))
+			a, err := selectArchetype(ctx, archetypes, c.String("archetype"), interactive)
+			if err != nil {
+				return err
+			}
+
+			b, err := newArchetypeServiceManifest(ctx, name, a, archetypeAsker(interactive))
+			if err != nil {
+				return err
+			}
+
+			if err := checkWorktree(ctx, c, ""); err != nil {
+				return err
+			}
+
+			log.Infof("Creating project %s from archetype %s", name, a.Name)
+			if err := os.WriteFile(serviceManifestFile, b, 0o644); err != nil {
+				return errors.Wrap(err, "failed to write service.yaml")
+			}
+
+			serviceManifest, err := loadServiceManifest(ctx, log)
+			if err != nil {
+				return errors.Wrap(err, "failed to parse service.yaml")
+			}
+			return errors.Wrap(newStencilCommand(log, c, serviceManifest).Run(ctx), "run codegen")
+		},
+	}
+}
+
+// loadArchetypes returns the archetypes declared by the catalog at
+// catalogPath, or by the manifest of the module from.
+func loadArchetypes(ctx context.Context, log logrus.FieldLogger, catalogPath, from string) ([]*configuration.Archetype, error) {
+	var archetypes []*configuration.Archetype
+	switch {
+	case catalogPath != "" && from != "":
+		return nil, errors.New("only one of --catalog and --from can be provided")
+	case catalogPath != "":
+		catalog, err := configuration.LoadArchetypeCatalog(catalogPath)
+		if err != nil {
+			return nil, err
+		}
+		archetypes = catalog.Archetypes
+	case from != "":
+		mod, err := resolveModule(ctx, log, parseModuleArg(from))
+		if err != nil {
+			return nil, err
+		}
+
+		mf, err := mod.Manifest(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get manifest of module %q", mod.Name)
+		}
+		archetypes = mf.Archetypes
+	default:
+		return nil, errors.New("one of --catalog or --from must be provided")
+	}
+
+	if len(archetypes) == 0 {
+		return nil, ErrNoArchetypes
+	}
+	return archetypes, nil
+}
+
+// selectArchetype returns the archetype with the provided name, or asks
+// the user to pick one if name is empty.
+func selectArchetype(ctx context.Context, archetypes []*configuration.Archetype, name string,
+	interactive bool) (*configuration.Archetype, error) {
+	if name != "" {
+		for _, a := range archetypes {
+			if a.Name == name {
+				return a, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %q", ErrArchetypeNotFound, name)
+	}
+
+	if len(archetypes) == 1 {
+		return archetypes[0], nil
+	}
+	if !interactive {
+		return nil, errors.New("unable to prompt for an archetype, stdin is not a terminal, pass --archetype")
+	}
+
+	options := make([]prompt.Option[*configuration.Archetype], 0, len(archetypes))
+	for _, a := range archetypes {
+		options = append(options, prompt.Option[*configuration.Archetype]{Label: a.Name, Description: a.Description, Value: a})
+	}
+
+	a, ok, err := prompt.PickOne(ctx, "Which archetype should the project be created from?", options)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, prompt.ErrAborted
+	}
+	return a, nil
+}
+
+// questionAsker returns the answer to a question of an archetype.
+type questionAsker func(ctx context.Context, q *configuration.ArchetypeQuestion, arg *codegen.MissingArgument) (any, error)
+
+// archetypeAsker returns a questionAsker that prompts the user, or uses
+// the default of the question if not interactive.
+func archetypeAsker(interactive bool) questionAsker {
+	return func(ctx context.Context, q *configuration.ArchetypeQuestion, arg *codegen.MissingArgument) (any, error) {
+		if interactive {
+			message := q.Message
+			if message == "" {
+				message = q.Argument
+			}
+			return stencil.AskArgument(ctx, message, arg)
+		}
+
+		if q.Default == nil {
+			return nil, fmt.Errorf("%w: %q", ErrArchetypeQuestionUnanswered, q.Argument)
+		}
+		return q.Default, nil
+	}
+}
+
+// newArchetypeServiceManifest returns the contents of the service.yaml of
+// a project named name created from a, with the answers to its questions
+// set as arguments.
+func newArchetypeServiceManifest(ctx context.Context, name string, a *configuration.Archetype, ask questionAsker) ([]byte, error) {
+	b, err := yaml.Marshal(&configuration.ServiceManifest{
+		Name:      name,
+		Modules:   a.Modules,
+		Arguments: a.Arguments,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode service.yaml")
+	}
+
+	doc, err := configuration.ParseServiceManifestDocument(b)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, q := range a.Questions {
+		arg := &codegen.MissingArgument{
+			Name: q.Argument,
+			Argument: configuration.Argument{
+				Description: q.Description,
+				Default:     q.Default,
+				Schema:      q.Schema,
+			},
+		}
+
+		v, err := ask(ctx, q, arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to ask for %q", q.Argument)
+		}
+		if err := doc.SetArgument(q.Argument, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return doc.Bytes()
+}
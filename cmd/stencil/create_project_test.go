@@ -0,0 +1,104 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: Tests for the create project command.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/getoutreach/stencil/internal/codegen"
+	"github.com/getoutreach/stencil/pkg/configuration"
+)
+
+const testCatalog = `archetypes:
+  - name: grpc-service
+    description: A gRPC service
+    modules:
+      - name: github.com/getoutreach/stencil-golang
+        channel: rc
+    arguments:
+      service: true
+    questions:
+      - argument: reportingTeam
+        message: Which team owns the service?
+      - argument: grpc.port
+        default: 5000
+        schema:
+          type: integer
+  - name: library
+    modules:
+      - name: github.com/getoutreach/stencil-golang
+`
+
+func TestLoadArchetypesFromCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	assert.NilError(t, os.WriteFile(path, []byte(testCatalog), 0o644))
+
+	archetypes, err := loadArchetypes(context.Background(), discardLogger(), path, "")
+	assert.NilError(t, err)
+	assert.Equal(t, len(archetypes), 2)
+
+	a, err := selectArchetype(context.Background(), archetypes, "library", false)
+	assert.NilError(t, err)
+	assert.Equal(t, a.Name, "library")
+
+	_, err = selectArchetype(context.Background(), archetypes, "unknown", false)
+	assert.ErrorIs(t, err, ErrArchetypeNotFound)
+
+	_, err = selectArchetype(context.Background(), archetypes, "", false)
+	assert.ErrorContains(t, err, "pass --archetype")
+}
+
+func TestLoadArchetypesEmptyCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	assert.NilError(t, os.WriteFile(path, []byte("archetypes: []\n"), 0o644))
+
+	_, err := loadArchetypes(context.Background(), discardLogger(), path, "")
+	assert.ErrorIs(t, err, ErrNoArchetypes)
+}
+
+func TestNewArchetypeServiceManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	assert.NilError(t, os.WriteFile(path, []byte(testCatalog), 0o644))
+	catalog, err := configuration.LoadArchetypeCatalog(path)
+	assert.NilError(t, err)
+
+	asked := make([]string, 0)
+	b, err := newArchetypeServiceManifest(context.Background(), "my-service", catalog.Archetypes[0],
+		func(_ context.Context, q *configuration.ArchetypeQuestion, arg *codegen.MissingArgument) (any, error) {
+			asked = append(asked, q.Message)
+			if q.Argument == "reportingTeam" {
+				return arg.Parse("devx")
+			}
+			return arg.Parse("8080")
+		})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, asked, []string{"Which team owns the service?", ""})
+	assert.Equal(t, string(b), `name: my-service
+arguments:
+  service: true
+  reportingTeam: devx
+  grpc:
+    port: 8080
+modules:
+  - name: github.com/getoutreach/stencil-golang
+    channel: rc
+`)
+}
+
+func TestArchetypeAskerNonInteractive(t *testing.T) {
+	ask := archetypeAsker(false)
+
+	v, err := ask(context.Background(), &configuration.ArchetypeQuestion{Argument: "port", Default: 5000}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, v, 5000)
+
+	_, err = ask(context.Background(), &configuration.ArchetypeQuestion{Argument: "team"}, nil)
+	assert.ErrorIs(t, err, ErrArchetypeQuestionUnanswered)
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains code for the debug command
+
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+)
+
+// NewDebugCommand returns a new urfave/cli.Command for the
+// debug command.
+func NewDebugCommand(log logrus.FieldLogger) *cli.Command {
+	return &cli.Command{
+		Name:  "debug",
+		Usage: "Render a single template and print what it was rendered with, read and created",
+		Description: "Renders the provided template, its import path (<module>/<path>) or its path if only one " +
+			"module provides it, without writing any files. Prints a JSON trace of the values it was rendered " +
+			"with, every stencil.Arg call and where the value came from (service.yaml, the module's default or " +
+			"the zero value of its type, and the module it was declared by through 'from'), the module hooks and " +
+			"globals it read, the data passed to stencil.Break and the files it created. With --interactive, " +
+			"rendering pauses at each stencil.Break to inspect what was read so far.",
+		ArgsUsage: "<template>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "interactive",
+				Aliases: []string{"i"},
+				Usage:   "Pause at each stencil.Break and read commands from stdin",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.NArg() != 1 {
+				return errors.New("expected exactly one argument, the template to debug")
+			}
+
+			serviceManifest, err := loadServiceManifest(ctx, log)
+			if err != nil {
+				return errors.Wrap(err, "failed to parse service.yaml")
+			}
+
+			var in io.Reader
+			if c.Bool("interactive") {
+				in = os.Stdin
+			}
+			return newStencilCommand(log, c, serviceManifest).Debug(ctx, c.Args().First(), in, os.Stdout)
+		},
+	}
+}
@@ -5,17 +5,18 @@
 package main
 
 import (
+	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v3"
 )
 
 // NewDocsCommand returns a new urfave/cli.Command for the
 // docs command.
-func NewDocsCommand() *cli.Command {
+func NewDocsCommand(log logrus.FieldLogger) *cli.Command {
 	return &cli.Command{
 		Name:        "docs",
 		Description: "Commands for generating documentation",
 		Commands: []*cli.Command{
-			NewDocsGenerateCommand(),
+			NewDocsGenerateCommand(log),
 		},
 	}
 }
@@ -5,15 +5,73 @@
 package main
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v3"
+
+	"github.com/getoutreach/stencil/internal/schema"
 )
 
 // NewDocsGenerateCommand returns a new urfave/cli.Command for the
 // docs generate command.
-func NewDocsGenerateCommand() *cli.Command {
+func NewDocsGenerateCommand(log logrus.FieldLogger) *cli.Command {
 	return &cli.Command{
-		Name:        "generate",
-		Usage:       "Generate documentation",
-		Description: "Generates documentation for the current stencil module",
+		Name:  "generate",
+		Usage: "Generate documentation",
+		Description: "Generates documentation for the current stencil module or project: the JSON schemas of the " +
+			"service.yaml, manifest.yaml and stencil.lock are written to --schema-dir. In a project, the schema of " +
+			"the service.yaml includes the arguments declared by its modules, like 'stencil schema', unless " +
+			"--generic is set.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "schema-dir",
+				Usage: "Directory to write the JSON schemas to",
+				Value: "schemas",
+			},
+			&cli.BoolFlag{
+				Name:  "generic",
+				Usage: "Write the schema of any service.yaml, without the arguments of the project's modules",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			return generateSchemas(ctx, log, c.String("schema-dir"), c.Bool("generic"))
+		},
+	}
+}
+
+// generateSchemas writes the schema of each of schema.Files to dir, as
+// <file>.json. The schema of the service.yaml is specific to the project
+// if there's a service.yaml in the current directory, unless generic is
+// set.
+func generateSchemas(ctx context.Context, log logrus.FieldLogger, dir string, generic bool) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.Wrapf(err, "failed to create %q", dir)
+	}
+
+	for _, f := range schema.Files {
+		s := schema.For(f)
+		if f == schema.FileServiceManifest && !generic {
+			if _, err := os.Stat(serviceManifestFile); err == nil {
+				if s, err = projectSchema(ctx, log); err != nil {
+					return err
+				}
+			}
+		}
+
+		b, err := marshalSchema(s)
+		if err != nil {
+			return err
+		}
+
+		out := filepath.Join(dir, string(f)+".json")
+		log.Infof("Writing schema of %s to %s", f, out)
+		if err := os.WriteFile(out, b, 0o644); err != nil {
+			return errors.Wrapf(err, "failed to write schema to %q", out)
+		}
 	}
+	return nil
 }
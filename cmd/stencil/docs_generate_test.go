@@ -0,0 +1,47 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: Tests for the stencil docs generate command.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/getoutreach/stencil/internal/schema"
+)
+
+// readSchema reads the schema written to path.
+func readSchema(t *testing.T, path string) *schema.Schema {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	assert.NilError(t, err)
+
+	var s schema.Schema
+	assert.NilError(t, json.Unmarshal(b, &s))
+	return &s
+}
+
+func TestGenerateSchemas(t *testing.T) {
+	modDir := writeLocalModule(t, "name: github.com/x/a\narguments:\n  team:\n    description: The team\n")
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("service.yaml", []byte("name: test\nmodules:\n  - name: github.com/x/a\n"+
+		"replacements:\n  github.com/x/a: file://"+modDir+"\n"), 0o644))
+
+	assert.NilError(t, generateSchemas(context.Background(), discardLogger(), "schemas", false))
+	for _, f := range schema.Files {
+		s := readSchema(t, filepath.Join("schemas", string(f)+".json"))
+		assert.Equal(t, s.Title, string(f))
+	}
+	s := readSchema(t, filepath.Join("schemas", "service.yaml.json"))
+	assert.Assert(t, s.Properties["arguments"].Properties["team"] != nil)
+
+	assert.NilError(t, generateSchemas(context.Background(), discardLogger(), "generic", true))
+	s = readSchema(t, filepath.Join("generic", "service.yaml.json"))
+	assert.Assert(t, s.Properties["arguments"].Properties["team"] == nil)
+}
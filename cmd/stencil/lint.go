@@ -25,6 +25,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v3"
+	"go.yaml.in/yaml/v3"
 
 	"github.com/getoutreach/stencil/internal/lint"
 	lintmanifest "github.com/getoutreach/stencil/internal/lint/manifest"
@@ -32,6 +33,7 @@ import (
 	lintprojectmanifest "github.com/getoutreach/stencil/internal/lint/projectmanifest"
 	linttemplates "github.com/getoutreach/stencil/internal/lint/templates"
 	"github.com/getoutreach/stencil/internal/modules"
+	"github.com/getoutreach/stencil/pkg/configuration"
 )
 
 // templatesDir is the conventional subdirectory holding a module's *.tpl files.
@@ -261,7 +263,7 @@ func fixTemplateFile(log logrus.FieldLogger, path string) ([]lint.Finding, error
 	}
 	logAppliedTemplates(log, applied)
 
-	return linttemplates.LintReader(path, bytes.NewReader(fixed))
+	return linttemplates.LintReaderWithCommentStyles(path, bytes.NewReader(fixed), moduleCommentStyles(path))
 }
 
 // templateOpenErrorFinding builds the "not found"/"failed to open" finding
@@ -351,7 +353,35 @@ func runTemplateFile(log logrus.FieldLogger, path string) ([]lint.Finding, error
 		return []lint.Finding{templateOpenErrorFinding(path, err)}, nil
 	}
 	defer fh.Close()
-	return linttemplates.LintReader(path, fh)
+	return linttemplates.LintReaderWithCommentStyles(path, fh, moduleCommentStyles(path))
+}
+
+// moduleCommentStyles returns the commentStyles declared by the manifest of
+// the module that the template at path belongs to, found by walking up from
+// path to the first directory containing a manifest.yaml. Nil is returned
+// when there is no manifest, or it can't be parsed (the manifest linter
+// reports that).
+func moduleCommentStyles(path string) map[string]string {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	for {
+		if raw, err := os.ReadFile(filepath.Join(dir, "manifest.yaml")); err == nil {
+			var mf configuration.TemplateRepositoryManifest
+			if err := yaml.Unmarshal(raw, &mf); err != nil {
+				return nil
+			}
+			return mf.CommentStyles
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
 }
 
 // failTemplates applies the warnings-as-errors policy and logs the templates
@@ -831,13 +861,28 @@ func runLintProjectManifest(ctx context.Context, c *cli.Command) error {
 // resolves the manifest's modules and runs the online checks (O1-O8). O1 is
 // produced here (the resolver error is opaque, so a resolution failure yields a
 // single `modules` finding; a per-module Manifest decode failure is attributable
-// and yields modules.<name>); either skips O2-O8.
+// and yields modules.<name>); either skips O2-O8. The service manifests it
+// extends, relative to name, are merged into it first so that inherited
+// modules and arguments are validated too.
 func resolveAndValidateProjectManifest(ctx context.Context, log logrus.FieldLogger,
-	res *lintprojectmanifest.LoadResult, offline bool) []lint.Finding {
+	name string, res *lintprojectmanifest.LoadResult, offline bool) []lint.Finding {
 	if offline || res.Manifest == nil {
 		return lintprojectmanifest.Validate(res)
 	}
 
+	if len(res.Manifest.Extends) > 0 {
+		mf, err := configuration.ResolveServiceManifestBases(res.Manifest, name, moduleFileReader(ctx, log))
+		if err != nil {
+			return append(lintprojectmanifest.Validate(res), lint.Finding{
+				Severity: lint.SeverityError,
+				Path:     "extends",
+				Message: fmt.Sprintf("failed to load extended service manifests: %v; ensure the "+
+					"paths and modules are correct", err),
+			})
+		}
+		res = &lintprojectmanifest.LoadResult{Manifest: mf, Root: res.Root, MultiDoc: res.MultiDoc}
+	}
+
 	token, err := github.GetToken()
 	if err != nil {
 		log.Warn("failed to get github token, using anonymous access")
@@ -930,7 +975,7 @@ func runProjectManifestReaderOnline(ctx context.Context, log logrus.FieldLogger,
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to read service manifest %q", name)
 	}
-	findings := resolveAndValidateProjectManifest(ctx, log, res, offline)
+	findings := resolveAndValidateProjectManifest(ctx, log, name, res, offline)
 	if res.MultiDoc {
 		log.WithField("path", name).Warn("additional YAML documents after the first are ignored")
 	}
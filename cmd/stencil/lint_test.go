@@ -583,6 +583,22 @@ func TestRunLintTemplatesExplicitFileArg(t *testing.T) {
 	assert.Assert(t, strings.Contains(err.Error(), "lint failed"))
 }
 
+// TestRunTemplateFileHonoursManifestCommentStyles proves the comment styles
+// declared by the module's manifest.yaml are used to find block tags.
+func TestRunTemplateFileHonoursManifestCommentStyles(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "manifest.yaml"),
+		[]byte("name: test\ncommentStyles:\n  .txt: \";;\"\n"), 0o600))
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "templates", "docs"), 0o755))
+	path := filepath.Join(dir, "templates", "docs", "notes.txt.tpl")
+	assert.NilError(t, os.WriteFile(path, []byte(";; <<Stencil::Block(y)>>\nnope\n;; <</Stencil::Block>>\n"), 0o600))
+
+	findings, err := runTemplateFile(discardLogger(), path)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(findings))
+	assert.Assert(t, strings.Contains(findings[0].Message, "no file.Block call"), findings[0].Message)
+}
+
 // TestRunTemplateFileMissingFileFinding proves a missing file arg yields a
 // "template file not found:" finding (not an error).
 func TestRunTemplateFileMissingFileFinding(t *testing.T) {
@@ -0,0 +1,31 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains code for the lsp command
+
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+
+	"github.com/getoutreach/stencil/internal/lsp"
+)
+
+// NewLSPCommand returns a new urfave/cli.Command for the
+// lsp command.
+func NewLSPCommand(log logrus.FieldLogger) *cli.Command {
+	return &cli.Command{
+		Name:  "lsp",
+		Usage: "Run a language server for templates, manifest.yaml and service.yaml files over stdio",
+		Description: "Runs a Language Server Protocol server over stdin and stdout, for editors. It reports the " +
+			"findings of stencil lint as diagnostics, completes template function names and the arguments passed " +
+			"to stencil.Arg, shows the documentation of functions and arguments on hover, and goes to the " +
+			"definition of the templates passed to stencil.ApplyTemplate and template.",
+		Action: func(ctx context.Context, _ *cli.Command) error {
+			return lsp.NewServer(log).Serve(ctx, os.Stdin, os.Stdout)
+		},
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains code for the --project-dir flag, which
+// runs stencil as if it was started in another directory.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v3"
+)
+
+// pathFlags are the global flags whose values are paths, which are
+// relative to the directory stencil was started in, not --project-dir.
+var pathFlags = []string{"output-dir", "output-tar", "post-run-report"}
+
+// startDirKey is the context key of the directory stencil was started
+// in, set when --project-dir changed the working directory.
+type startDirKey struct{}
+
+// changeProjectDir changes the working directory to --project-dir, if
+// it's set. The paths of pathFlags are made absolute first, and the
+// returned context remembers the directory stencil was started in, see
+// startPath.
+func changeProjectDir(ctx context.Context, c *cli.Command) (context.Context, error) {
+	dir := c.String("project-dir")
+	if dir == "" {
+		return ctx, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return ctx, errors.Wrap(err, "failed to get the current directory")
+	}
+	ctx = context.WithValue(ctx, startDirKey{}, wd)
+
+	for _, name := range pathFlags {
+		if p := c.String(name); p != "" {
+			if err := c.Set(name, startPath(ctx, p)); err != nil {
+				return ctx, errors.Wrapf(err, "failed to resolve --%s", name)
+			}
+		}
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		return ctx, errors.Wrapf(err, "failed to change directory to %q", dir)
+	}
+	return ctx, nil
+}
+
+// startPath returns p, a path provided on the command line, relative to
+// the directory stencil was started in, for use after --project-dir
+// changed the working directory.
+func startPath(ctx context.Context, p string) string {
+	wd, ok := ctx.Value(startDirKey{}).(string)
+	if !ok || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(wd, p)
+}
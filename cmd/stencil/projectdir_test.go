@@ -0,0 +1,36 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: Tests for the --project-dir flag.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestProjectDirKeepsPathsRelativeToStartDir(t *testing.T) {
+	modDir := writeLocalTemplateModule(t)
+	start := t.TempDir()
+	t.Chdir(start)
+	assert.NilError(t, os.MkdirAll(filepath.Join("repo", "a"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join("repo", "a", "service.yaml"), []byte("name: a\n"+
+		"modules:\n  - name: github.com/x/a\nreplacements:\n  github.com/x/a: file://"+modDir+"\n"), 0o644))
+
+	root := newTestApp(t, NewWorkspaceCommand(discardLogger()))
+	assert.NilError(t, root.Run(context.Background(),
+		[]string{"stencil", "--project-dir", "repo", "--output-dir", "out", "workspace", "repo"}))
+	assert.Equal(t, root.String("output-dir"), filepath.Join(start, "out"))
+
+	for _, name := range []string{"hello.txt", "stencil.lock"} {
+		_, err := os.Stat(filepath.Join(start, "out", "a", name))
+		assert.NilError(t, err)
+	}
+	_, err := os.Stat(filepath.Join(start, "repo", "out"))
+	assert.Assert(t, errors.Is(err, os.ErrNotExist), "expected nothing to be written relative to --project-dir")
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains the stencil remove command, which
+// removes a module from the service manifest along with the files it
+// generated.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/getoutreach/stencil/pkg/stencil"
+)
+
+// ErrModuleNotInManifest is returned when removing a module that isn't in
+// the service manifest.
+var ErrModuleNotInManifest = errors.New("module is not in the service.yaml")
+
+// NewRemoveCommand returns a new urfave/cli.Command for the remove
+// command.
+func NewRemoveCommand(log logrus.FieldLogger) *cli.Command {
+	return &cli.Command{
+		Name:      "remove",
+		Usage:     "Remove a module from the service.yaml and delete the files it generated",
+		ArgsUsage: "<module>",
+		Description: "Removes the module, and any replacement of it, from the service.yaml, keeping its comments, and " +
+			"runs stencil. Files generated by the module, according to the stencil.lock, that are no longer generated " +
+			"are deleted. " +
+			"The service.yaml is restored if rendering fails, and isn't changed with --dry-run.",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.NArg() != 1 {
+				return errors.New("expected exactly one argument, the module to remove")
+			}
+			if err := checkWorktree(ctx, c, ""); err != nil {
+				return err
+			}
+			name := c.Args().First()
+
+			original, err := os.ReadFile(serviceManifestFile)
+			if err != nil {
+				return errors.Wrap(err, "service.yaml must exist")
+			}
+
+			doc, err := configuration.ParseServiceManifestDocument(original)
+			if err != nil {
+				return err
+			}
+
+			if !doc.RemoveModule(name) {
+				return fmt.Errorf("%w: %q", ErrModuleNotInManifest, name)
+			}
+			doc.RemoveReplacement(name)
+
+			oldLock, err := stencil.LoadLockfile("")
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return errors.Wrap(err, "failed to load lockfile")
+			}
+
+			log.Infof("Removing module %s from %s", name, serviceManifestFile)
+			if err := renderEditedManifest(ctx, log, c, doc, original); err != nil {
+				return err
+			}
+
+			if c.Bool("dry-run") || oldLock == nil {
+				return nil
+			}
+
+			newLock, err := stencil.LoadLockfile("")
+			if err != nil {
+				return errors.Wrap(err, "failed to load lockfile")
+			}
+			return removeModuleFiles(log, name, oldLock, newLock)
+		},
+	}
+}
+
+// removeModuleFiles deletes the files that were generated by the module
+// with the provided name according to oldLock, but are no longer
+// generated according to newLock. A module that is still a dependency of
+// another module keeps generating its files, so they aren't deleted.
+func removeModuleFiles(log logrus.FieldLogger, name string, oldLock, newLock *stencil.Lockfile) error {
+	generated := make(map[string]bool, len(newLock.Files))
+	for _, f := range newLock.Files {
+		generated[f.Name] = true
+	}
+
+	for _, f := range oldLock.Files {
+		if f.Module != name || generated[f.Name] {
+			continue
+		}
+
+		if err := os.Remove(f.Name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.Wrapf(err, "failed to delete %q", f.Name)
+		}
+		log.Infof("  -> Deleted %s", f.Name)
+	}
+	return nil
+}
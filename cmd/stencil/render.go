@@ -0,0 +1,165 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains helpers for rendering the service
+// manifest from commands other than the root command.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/getoutreach/gobox/pkg/cli/github"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+
+	"github.com/getoutreach/stencil/internal/cmd/stencil"
+	"github.com/getoutreach/stencil/internal/modules"
+	"github.com/getoutreach/stencil/pkg/configuration"
+)
+
+// serviceManifestFile is the path to the service manifest edited by
+// commands.
+const serviceManifestFile = "service.yaml"
+
+// loadServiceManifest reads the service manifest, with the service
+// manifests it extends merged into it.
+func loadServiceManifest(ctx context.Context, log logrus.FieldLogger) (*configuration.ServiceManifest, error) {
+	return configuration.LoadServiceManifest(serviceManifestFile, moduleFileReader(ctx, log))
+}
+
+// moduleFileReader returns a configuration.ModuleFileReader that reads
+// files from modules, downloading them if needed.
+func moduleFileReader(ctx context.Context, log logrus.FieldLogger) configuration.ModuleFileReader {
+	return func(module, version, path string) ([]byte, error) {
+		m, err := resolveModule(ctx, log, &configuration.TemplateRepository{Name: module, Version: version})
+		if err != nil {
+			return nil, err
+		}
+
+		fs, err := m.GetFS(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to download module %q", module)
+		}
+		return util.ReadFile(fs, path)
+	}
+}
+
+// resolveProjectModules resolves the modules of the service manifest sm,
+// including their dependencies.
+func resolveProjectModules(ctx context.Context, log logrus.FieldLogger,
+	sm *configuration.ServiceManifest,
+) ([]*modules.Module, error) {
+	token, err := github.GetToken()
+	if err != nil {
+		log.Warn("failed to get github token, using anonymous access")
+	}
+
+	mods, err := modules.GetModulesForService(ctx, &modules.ModuleResolveOptions{
+		ServiceManifest: sm,
+		Token:           token,
+		Log:             log,
+	})
+	return mods, errors.Wrap(err, "failed to resolve modules")
+}
+
+// resolveModule resolves the version of the module tr, and returns it.
+func resolveModule(ctx context.Context, log logrus.FieldLogger, tr *configuration.TemplateRepository) (*modules.Module, error) {
+	token, err := github.GetToken()
+	if err != nil {
+		log.Warn("failed to get github token, using anonymous access")
+	}
+
+	mods, err := modules.GetModulesForService(ctx, &modules.ModuleResolveOptions{
+		ServiceManifest: &configuration.ServiceManifest{Name: "resolve", Modules: []*configuration.TemplateRepository{tr}},
+		Token:           token,
+		Log:             log,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve module %q", tr.Name)
+	}
+
+	for _, m := range mods {
+		if m.Name == tr.Name {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("module %q was not resolved", tr.Name)
+}
+
+// newStencilCommand creates the command that renders serviceManifest,
+// configured by the global flags.
+func newStencilCommand(log logrus.FieldLogger, c *cli.Command, serviceManifest *configuration.ServiceManifest) *stencil.Command {
+	cmd := stencil.NewCommand(
+		log,
+		serviceManifest,
+		c.Bool("dry-run"),
+		c.Bool("frozen-lockfile"),
+		c.Bool("use-prerelease"),
+		c.Bool("allow-major-version-upgrades"),
+		c.Int("concurrent-resolvers"),
+		c.Bool("skip-post-run"),
+		c.StringSlice("only-post-run"),
+		c.String("post-run-report"),
+		!c.Bool("no-prompt"),
+	)
+	cmd.SetOutput(c.String("output-dir"), c.String("output-tar"))
+	cmd.SetGitMode(c.Bool("commit"), c.String("commit-branch"))
+	return cmd
+}
+
+// checkWorktree returns an error if --require-clean or --commit is set
+// and the git worktree of dir, or of the current directory if empty, has
+// uncommitted changes. It must be called before anything is changed.
+func checkWorktree(ctx context.Context, c *cli.Command, dir string) error {
+	if !c.Bool("require-clean") && !c.Bool("commit") {
+		return nil
+	}
+	return stencil.CheckWorktree(ctx, dir)
+}
+
+// renderEditedManifest saves doc, the edited service manifest, and
+// renders it. If rendering fails, the service manifest is restored to
+// original so that it's never left referencing a broken module. With
+// --dry-run the service manifest isn't saved, doc is rendered in memory.
+func renderEditedManifest(ctx context.Context, log logrus.FieldLogger, c *cli.Command,
+	doc *configuration.ServiceManifestDocument, original []byte,
+) error {
+	if c.Bool("dry-run") {
+		serviceManifest, err := doc.ServiceManifest()
+		if err == nil {
+			serviceManifest, err = configuration.ResolveServiceManifestBases(serviceManifest,
+				serviceManifestFile, moduleFileReader(ctx, log))
+		}
+		if err != nil {
+			return err
+		}
+		return errors.Wrap(newStencilCommand(log, c, serviceManifest).Run(ctx), "run codegen")
+	}
+
+	if err := doc.Save(serviceManifestFile); err != nil {
+		return err
+	}
+
+	serviceManifest, err := loadServiceManifest(ctx, log)
+	if err == nil {
+		err = newStencilCommand(log, c, serviceManifest).Run(ctx)
+	}
+	if err == nil {
+		return nil
+	}
+
+	perm := os.FileMode(0o644)
+	if info, serr := os.Stat(serviceManifestFile); serr == nil {
+		perm = info.Mode().Perm()
+	}
+	if rerr := os.WriteFile(serviceManifestFile, original, perm); rerr != nil {
+		log.WithError(rerr).Error("Failed to restore service.yaml")
+	} else {
+		log.Warn("Restored service.yaml, rendering failed")
+	}
+	return errors.Wrap(err, "run codegen")
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains code for the schema command
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+
+	"github.com/getoutreach/stencil/internal/schema"
+	"github.com/getoutreach/stencil/pkg/configuration"
+)
+
+// NewSchemaCommand returns a new urfave/cli.Command for the
+// schema command.
+func NewSchemaCommand(log logrus.FieldLogger) *cli.Command {
+	return &cli.Command{
+		Name:  "schema",
+		Usage: "Print the JSON schema of a service.yaml, manifest.yaml or stencil.lock",
+		Description: "Prints the JSON schema of the provided file, service.yaml by default. The schema of the " +
+			"service.yaml is specific to the project: it includes the arguments declared by the modules it uses, " +
+			"which are resolved and downloaded, unless --generic is set. Editors use the schema for completion " +
+			"and validation, e.g. with the YAML language server add '# yaml-language-server: $schema=<path>' to the " +
+			"top of the service.yaml.",
+		ArgsUsage: "[service.yaml|manifest.yaml|stencil.lock]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Write the schema to the provided path instead of stdout",
+			},
+			&cli.BoolFlag{
+				Name:  "generic",
+				Usage: "Print the schema of any service.yaml, without the arguments of the project's modules",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			file := schema.FileServiceManifest
+			if c.NArg() > 0 {
+				file = schema.File(c.Args().First())
+			}
+			if !slices.Contains(schema.Files, file) {
+				return fmt.Errorf("unknown file %q, expected one of %v", file, schema.Files)
+			}
+
+			s := schema.For(file)
+			if file == schema.FileServiceManifest && !c.Bool("generic") {
+				var err error
+				if s, err = projectSchema(ctx, log); err != nil {
+					return err
+				}
+			}
+
+			b, err := marshalSchema(s)
+			if err != nil {
+				return err
+			}
+
+			if out := c.String("output"); out != "" {
+				return errors.Wrapf(os.WriteFile(out, b, 0o644), "failed to write schema to %q", out)
+			}
+			_, err = os.Stdout.Write(b)
+			return err
+		},
+	}
+}
+
+// marshalSchema encodes s as indented JSON.
+func marshalSchema(s *schema.Schema) ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode schema")
+	}
+	return append(b, '\n'), nil
+}
+
+// projectSchema returns the schema of the service.yaml of the current
+// project, including the arguments declared by its modules.
+func projectSchema(ctx context.Context, log logrus.FieldLogger) (*schema.Schema, error) {
+	serviceManifest, err := loadServiceManifest(ctx, log)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse service.yaml")
+	}
+
+	mods, err := resolveProjectModules(ctx, log, serviceManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make([]*configuration.TemplateRepositoryManifest, 0, len(mods))
+	for _, m := range mods {
+		mf, err := m.Manifest(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read module %q manifest", m.Name)
+		}
+		manifests = append(manifests, &mf)
+	}
+	return schema.ServiceManifestForModules(manifests), nil
+}
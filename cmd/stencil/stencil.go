@@ -20,8 +20,6 @@ import (
 
 	// Place any extra imports for your startup code here
 	// <<Stencil::Block(imports)>>
-	"github.com/getoutreach/stencil/internal/cmd/stencil"
-	"github.com/getoutreach/stencil/pkg/configuration"
 	"github.com/pkg/errors"
 	// <</Stencil::Block>>
 )
@@ -68,7 +66,11 @@ func main() {
 				log.Debug("Debug logging enabled")
 			}
 
-			serviceManifest, err := configuration.NewDefaultServiceManifest()
+			if err := checkWorktree(ctx, c, ""); err != nil {
+				return err
+			}
+
+			serviceManifest, err := loadServiceManifest(ctx, log)
 			if err != nil {
 				return errors.Wrap(err, "failed to parse service.yaml")
 			}
@@ -86,15 +88,7 @@ func main() {
 				}
 			}
 
-			cmd := stencil.NewCommand(
-				log,
-				serviceManifest,
-				c.Bool("dry-run"),
-				c.Bool("frozen-lockfile"),
-				c.Bool("use-prerelease"),
-				c.Bool("allow-major-version-upgrades"),
-				c.Int("concurrent-resolvers"),
-			)
+			cmd := newStencilCommand(log, c, serviceManifest)
 			return errors.Wrap(cmd.Run(ctx), "run codegen")
 		},
 		// <</Stencil::Block>>
@@ -124,6 +118,46 @@ func main() {
 			Name:  "allow-major-version-upgrades",
 			Usage: "Allow major version upgrades without confirmation",
 		},
+		&cli.BoolFlag{
+			Name:  "skip-post-run",
+			Usage: "Don't run the post-run commands declared by modules",
+		},
+		&cli.StringSliceFlag{
+			Name:  "only-post-run",
+			Usage: "Only run the post-run commands with the provided name, can be repeated",
+		},
+		&cli.StringFlag{
+			Name:  "post-run-report",
+			Usage: "Write a JSON report of the post-run commands' status, duration and output to the provided path",
+		},
+		&cli.BoolFlag{
+			Name:  "no-prompt",
+			Usage: "Don't prompt for missing required arguments, even when running in a terminal",
+		},
+		&cli.StringFlag{
+			Name:  "output-dir",
+			Usage: "Write the rendered files and stencil.lock to the provided directory instead of the project directory",
+		},
+		&cli.StringFlag{
+			Name:  "output-tar",
+			Usage: "Write the rendered files and stencil.lock to a tarball, gzipped if it ends with .gz or .tgz, at the provided path",
+		},
+		&cli.StringFlag{
+			Name:  "project-dir",
+			Usage: "Run as if stencil was started in the provided directory, other global flags' paths stay relative to the current one",
+		},
+		&cli.BoolFlag{
+			Name:  "require-clean",
+			Usage: "Refuse to run if the git worktree has uncommitted changes",
+		},
+		&cli.BoolFlag{
+			Name:  "commit",
+			Usage: "Commit the changes to a new git branch, summarizing the module version changes. Implies --require-clean",
+		},
+		&cli.StringFlag{
+			Name:  "commit-branch",
+			Usage: "Name of the branch created by --commit, defaults to stencil/update-<timestamp>",
+		},
 		&cli.BoolFlag{
 			Name:    "debug",
 			Usage:   "Enables debug logging for version resolution, template render, and other useful information",
@@ -134,15 +168,31 @@ func main() {
 	app.Commands = []*cli.Command{
 		// <<Stencil::Block(commands)>>
 		NewDescribeCmd(),
-		NewCreateCommand(),
-		NewDocsCommand(),
+		NewCreateCommand(log),
+		NewDocsCommand(log),
 		NewConfigureCommand(),
+		NewConfigCommand(log),
 		NewLintCommand(),
+		NewBlocksCommand(),
+		NewAddCommand(log),
+		NewRemoveCommand(log),
+		NewWorkspaceCommand(log),
+		NewSchemaCommand(log),
+		NewLSPCommand(log),
+		NewDebugCommand(log),
 		// <</Stencil::Block>>
 	}
 
 	// <<Stencil::Block(postApp)>>
-
+	app.Before = func(ctx context.Context, c *cli.Command) (context.Context, error) {
+		if c.String("output-dir") != "" && c.String("output-tar") != "" {
+			return ctx, errors.New("only one of --output-dir and --output-tar can be provided")
+		}
+		if c.Bool("commit") && (c.Bool("dry-run") || c.String("output-dir") != "" || c.String("output-tar") != "") {
+			return ctx, errors.New("--commit can't be used with --dry-run, --output-dir or --output-tar")
+		}
+		return changeProjectDir(ctx, c)
+	}
 	// <</Stencil::Block>>
 
 	// Insert global flags, tracing, updating and start the application.
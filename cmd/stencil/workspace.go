@@ -0,0 +1,230 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains code for the workspace command, which
+// renders every project in a tree.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v3"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/getoutreach/stencil/internal/modules"
+)
+
+// ErrProjectsFailed is returned when rendering at least one of the
+// projects in a workspace failed.
+var ErrProjectsFailed = errors.New("failed to render projects")
+
+// skippedWorkspaceDirs are directories that are never searched for
+// projects.
+var skippedWorkspaceDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// projectResult is the outcome of rendering a project in a workspace.
+type projectResult struct {
+	// Dir is the directory of the project, relative to the root of the
+	// workspace
+	Dir string
+
+	// ChangedFiles is the number of files that were created, updated or
+	// deleted
+	ChangedFiles int
+
+	// Err is the error rendering the project failed with, if any
+	Err error
+}
+
+// NewWorkspaceCommand returns a new urfave/cli.Command for the
+// workspace command.
+func NewWorkspaceCommand(log logrus.FieldLogger) *cli.Command {
+	return &cli.Command{
+		Name:  "workspace",
+		Usage: "Render every project in a directory tree",
+		Description: "Finds every project, a directory containing a service.yaml with a name, under the provided " +
+			"directory (defaults to the current directory) and renders each of them into its own directory with its " +
+			"own stencil.lock. Modules are resolved and downloaded once for all of the projects. The global flags " +
+			"apply to every project. With --output-dir, each project is written to <output-dir>/<project>, " +
+			"--output-tar and --post-run-report aren't supported.",
+		ArgsUsage: "[dir]",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Bool("commit") {
+				return errors.New("--commit can't be used with the workspace command, commit the projects together instead")
+			}
+
+			if c.String("output-tar") != "" || c.String("post-run-report") != "" {
+				return errors.New("--output-tar and --post-run-report can't be used with the workspace command, " +
+					"every project would write to the same path, use --output-dir instead")
+			}
+
+			// Every project is rendered in its own directory
+			outputDir := c.String("output-dir")
+			if outputDir != "" {
+				var err error
+				if outputDir, err = filepath.Abs(outputDir); err != nil {
+					return errors.Wrap(err, "failed to resolve --output-dir")
+				}
+			}
+
+			root := "."
+			if c.NArg() > 0 {
+				root = startPath(ctx, c.Args().First())
+			}
+
+			// Checked once, rendering a project makes the worktree dirty
+			if err := checkWorktree(ctx, c, root); err != nil {
+				return err
+			}
+
+			projects, err := discoverProjects(root)
+			if err != nil {
+				return err
+			}
+			if len(projects) == 0 {
+				return fmt.Errorf("no projects found in %q", root)
+			}
+			log.Infof("Found %d project(s) in %s", len(projects), root)
+
+			cache := modules.NewResolveCache()
+			results := make([]*projectResult, 0, len(projects))
+			for _, dir := range projects {
+				results = append(results, renderProject(ctx, log, c, root, dir, outputDir, cache))
+			}
+
+			if err := printProjectResults(os.Stdout, results); err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, r := range results {
+				if r.Err != nil {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%w: %d of %d failed", ErrProjectsFailed, failed, len(results))
+			}
+			return nil
+		},
+	}
+}
+
+// discoverProjects returns the directories, relative to root, of the
+// projects under root. Hidden directories, node_modules and vendor are
+// skipped, as are service.yaml files without a name, e.g. shared base
+// service manifests.
+func discoverProjects(root string) ([]string, error) {
+	var projects []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || skippedWorkspaceDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Name() != serviceManifestFile {
+			return nil
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var m struct {
+			Name string `yaml:"name"`
+		}
+		if err := yaml.Unmarshal(b, &m); err != nil {
+			return errors.Wrapf(err, "failed to parse %q", path)
+		}
+		if m.Name == "" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		projects = append(projects, rel)
+		return nil
+	})
+	return projects, errors.Wrap(err, "failed to find projects")
+}
+
+// renderProject renders the project in dir, relative to root, sharing
+// cache with the other projects. If outputDir, an absolute path, is set
+// the project is written to dir inside of it.
+func renderProject(ctx context.Context, log logrus.FieldLogger, c *cli.Command, root, dir, outputDir string,
+	cache *modules.ResolveCache) *projectResult {
+	res := &projectResult{Dir: dir}
+	plog := log.WithField("project", dir)
+	plog.Info("Rendering project")
+
+	err := inDir(filepath.Join(root, dir), func() error {
+		serviceManifest, err := loadServiceManifest(ctx, plog)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse service.yaml")
+		}
+
+		cmd := newStencilCommand(plog, c, serviceManifest)
+		if outputDir != "" {
+			cmd.SetOutput(filepath.Join(outputDir, dir), "")
+		}
+		cmd.UseResolveCache(cache)
+		err = cmd.Run(ctx)
+		res.ChangedFiles = len(cmd.ChangedFiles())
+		return err
+	})
+	if err != nil {
+		plog.WithError(err).Error("Failed to render project")
+		res.Err = err
+	}
+	return res
+}
+
+// inDir runs fn with dir as the working directory, restoring the
+// working directory afterwards.
+func inDir(dir string, fn func() error) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.Wrap(err, "failed to get current working directory")
+	}
+	if err := os.Chdir(dir); err != nil {
+		return errors.Wrapf(err, "failed to change directory to %q", dir)
+	}
+	//nolint:errcheck // Why: Best effort, the original directory existed
+	defer os.Chdir(cwd)
+
+	return fn()
+}
+
+// printProjectResults writes a table of the results to w.
+func printProjectResults(w io.Writer, results []*projectResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROJECT\tSTATUS\tCHANGED FILES")
+	for _, r := range results {
+		status := "ok"
+		if r.Err != nil {
+			status = "failed: " + strings.SplitN(r.Err.Error(), "\n", 2)[0]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", r.Dir, status, r.ChangedFiles)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: Tests for the stencil workspace command.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/getoutreach/stencil/internal/git"
+)
+
+func TestDiscoverProjects(t *testing.T) {
+	root := t.TempDir()
+	for path, contents := range map[string]string{
+		"service.yaml":                      "name: root\n",
+		"services/a/service.yaml":           "name: a\n",
+		"services/b/service.yaml":           "name: b\n",
+		"services/b/nested/c/service.yaml":  "name: c\n",
+		"shared/service.yaml":               "arguments:\n  team: platform\n",
+		".git/service.yaml":                 "name: git\n",
+		"node_modules/x/service.yaml":       "name: x\n",
+		"services/a/vendor/y/service.yaml":  "name: y\n",
+		"services/a/not-a-service-manifest": "name: z\n",
+	} {
+		path = filepath.Join(root, path)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NilError(t, os.WriteFile(path, []byte(contents), 0o644))
+	}
+
+	projects, err := discoverProjects(root)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, projects, []string{".", "services/a", "services/b/nested/c", "services/b"})
+}
+
+func TestPrintProjectResults(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NilError(t, printProjectResults(&buf, []*projectResult{
+		{Dir: "services/a", ChangedFiles: 3},
+		{Dir: "services/b", Err: errors.New("failed to resolve module\nmore details")},
+	}))
+	assert.Equal(t, buf.String(), `PROJECT     STATUS                            CHANGED FILES
+services/a  ok                                3
+services/b  failed: failed to resolve module  0
+`)
+}
+
+func TestInDir(t *testing.T) {
+	cwd := t.TempDir()
+	t.Chdir(cwd)
+	dir := t.TempDir()
+
+	var got string
+	assert.NilError(t, inDir(dir, func() error {
+		var err error
+		got, err = os.Getwd()
+		return err
+	}))
+	assert.Equal(t, got, dir)
+
+	after, err := os.Getwd()
+	assert.NilError(t, err)
+	assert.Equal(t, after, cwd)
+}
+
+func TestWorkspaceRequireClean(t *testing.T) {
+	modDir := writeLocalTemplateModule(t)
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"a", "b"} {
+		assert.NilError(t, os.MkdirAll(dir, 0o755))
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, "service.yaml"), []byte("name: "+dir+
+			"\nmodules:\n  - name: github.com/x/a\nreplacements:\n  github.com/x/a: file://"+modDir+"\n"), 0o644))
+	}
+	commitAll(t)
+
+	// Rendering a makes the worktree dirty, b must still be rendered
+	root := newTestApp(t, NewWorkspaceCommand(discardLogger()))
+	assert.NilError(t, root.Run(context.Background(), []string{"stencil", "--require-clean", "workspace"}))
+	for _, dir := range []string{"a", "b"} {
+		_, err := os.Stat(filepath.Join(dir, "hello.txt"))
+		assert.NilError(t, err)
+	}
+
+	err := root.Run(context.Background(), []string{"stencil", "--require-clean", "workspace"})
+	assert.ErrorIs(t, err, git.ErrDirtyWorktree)
+}
+
+func TestWorkspaceOutputDir(t *testing.T) {
+	modDir := writeLocalTemplateModule(t)
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"a", filepath.Join("nested", "b")} {
+		assert.NilError(t, os.MkdirAll(dir, 0o755))
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, "service.yaml"), []byte("name: "+filepath.Base(dir)+
+			"\nmodules:\n  - name: github.com/x/a\nreplacements:\n  github.com/x/a: file://"+modDir+"\n"), 0o644))
+	}
+
+	// Relative to the current directory, not to the projects
+	root := newTestApp(t, NewWorkspaceCommand(discardLogger()))
+	assert.NilError(t, root.Run(context.Background(), []string{"stencil", "--output-dir", "out", "workspace"}))
+	for _, dir := range []string{"a", filepath.Join("nested", "b")} {
+		for _, name := range []string{"hello.txt", "stencil.lock"} {
+			_, err := os.Stat(filepath.Join("out", dir, name))
+			assert.NilError(t, err)
+		}
+		_, err := os.Stat(filepath.Join(dir, "stencil.lock"))
+		assert.Assert(t, errors.Is(err, os.ErrNotExist), "expected the project to be left untouched")
+	}
+
+	for _, flag := range []string{"--output-tar", "--post-run-report"} {
+		err := root.Run(context.Background(), []string{"stencil", flag, "out.json", "workspace"})
+		assert.ErrorContains(t, err, "can't be used with the workspace command")
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file generates the published JSON schemas of the
+// service.yaml, manifest.yaml and stencil.lock using `stencil docs generate`.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+)
+
+// stencilCommand returns the base argv used to invoke stencil, a bare
+// "stencil" on PATH by default, or `go run` of the checkout at
+// STENCIL_ROOT if set, like the commands generator.
+func stencilCommand() []string {
+	root := os.Getenv("STENCIL_ROOT")
+	if root == "" {
+		return []string{"stencil"}
+	}
+	return []string{"go", "run", "-C", root, "./cmd/stencil"}
+}
+
+// generateSchemas writes the generic schemas to static/schemas.
+func generateSchemas() error {
+	out, err := filepath.Abs(filepath.Join("static", "schemas"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Generating schemas in:", out)
+	base := stencilCommand()
+	args := slices.Concat(base[1:], []string{"--skip-update", "docs", "generate", "--generic", "--schema-dir", out})
+	//nolint:gosec // Why: dev-only generator; the only variable arg is the trusted STENCIL_ROOT path.
+	if b, err := exec.CommandContext(context.Background(), base[0], args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to generate schemas: %w: %s", err, b)
+	}
+	return nil
+}
+
+func main() {
+	if err := generateSchemas(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains the logic for prompting for the required
+// arguments of modules that aren't set in the service manifest.
+
+package stencil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/getoutreach/gobox/pkg/cli/prompt"
+	"github.com/getoutreach/stencil/internal/codegen"
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+// serviceManifestPath is the path to the service manifest that answers
+// to argument prompts are written to.
+const serviceManifestPath = "service.yaml"
+
+// argumentAsker asks the user for the value of a missing argument.
+type argumentAsker func(ctx context.Context, arg *codegen.MissingArgument) (any, error)
+
+// promptForArguments collects all of the missing required arguments from
+// the user, before rendering, and writes them to the service manifest.
+// This is a no-op if prompting is disabled or stdin isn't a terminal, in
+// which case rendering fails on the first missing argument instead.
+func (c *Command) promptForArguments(ctx context.Context, st *codegen.Stencil) error {
+	if !c.interactive || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+	return c.collectArguments(ctx, st, askArgument)
+}
+
+// collectArguments asks for the value of every missing required argument
+// using ask, sets them on the service manifest and saves them to disk,
+// unless running in dry-run mode.
+func (c *Command) collectArguments(ctx context.Context, st *codegen.Stencil, ask argumentAsker) error {
+	missing, err := st.MissingRequiredArguments(ctx)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	c.log.Infof("%d required argument(s) are not set, please provide them", len(missing))
+
+	doc, err := configuration.LoadServiceManifestDocument(serviceManifestPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to load service manifest for editing")
+	}
+
+	for _, arg := range missing {
+		v, err := ask(ctx, arg)
+		if err != nil {
+			return errors.Wrapf(err, "failed to ask for argument %q", arg.Name)
+		}
+
+		if err := doc.SetArgument(arg.Name, v); err != nil {
+			return err
+		}
+		if c.manifest.Arguments == nil {
+			c.manifest.Arguments = make(map[string]any)
+		}
+		setArgument(c.manifest.Arguments, arg.Name, v)
+	}
+
+	if c.dryRun {
+		c.log.Info("Not saving arguments to service.yaml, --dry-run was set")
+		return nil
+	}
+
+	c.log.Infof("Saving %d argument(s) to %s", len(missing), serviceManifestPath)
+	return doc.Save(serviceManifestPath)
+}
+
+// askArgument prompts the user for the value of a missing argument.
+func askArgument(ctx context.Context, arg *codegen.MissingArgument) (any, error) {
+	return AskArgument(ctx, fmt.Sprintf("%s (required by %s)", arg.Name, arg.Module), arg)
+}
+
+// AskArgument prompts the user for the value of arg with the provided
+// message, showing its description. The values of its schema's enum are
+// offered as choices if it has one, otherwise the input is validated
+// against its schema. The default of the argument, if set, is pre-filled.
+func AskArgument(ctx context.Context, message string, arg *codegen.MissingArgument) (any, error) {
+	def := formatArgumentValue(arg.Argument.Default)
+
+	if enum := arg.Enum(); len(enum) != 0 {
+		// Select has no default, so offer the default first
+		if i := slices.Index(enum, def); i > 0 {
+			enum = append([]string{def}, slices.Delete(enum, i, i+1)...)
+		}
+
+		v, err := prompt.Select(ctx, prompt.SelectConfig{
+			Message: message,
+			Help:    arg.Argument.Description,
+			Options: enum,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return arg.Parse(v)
+	}
+
+	v, err := prompt.Ask(ctx, prompt.Config{
+		Message: message,
+		Help:    arg.Argument.Description,
+		Default: def,
+		Validate: func(s string) error {
+			_, err := arg.Parse(s)
+			return err
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return arg.Parse(v)
+}
+
+// formatArgumentValue formats v so that it can be parsed by
+// codegen.MissingArgument.Parse. Strings are returned as-is, and other
+// values are encoded as JSON.
+func formatArgumentValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// setArgument sets key, in dot notation, to v in args creating any
+// missing parent maps.
+func setArgument(args map[string]any, key string, v any) {
+	parts := strings.Split(key, ".")
+	m := args
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = v
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for prompting for missing
+// arguments.
+
+package stencil
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/getoutreach/stencil/internal/codegen"
+	"github.com/getoutreach/stencil/internal/modules"
+	"github.com/getoutreach/stencil/internal/modules/modulestest"
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"gotest.tools/v3/assert"
+)
+
+func TestCollectArguments(t *testing.T) {
+	ctx := context.Background()
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("service.yaml", []byte("# my service\nname: test\narguments:\n  existing: true\n"), 0o644))
+
+	m, err := modulestest.NewModuleFromTemplates(&configuration.TemplateRepositoryManifest{
+		Name: "testing",
+		Arguments: map[string]configuration.Argument{
+			"existing":    {Required: true},
+			"team":        {Required: true, Description: "The owning team"},
+			"server.port": {Required: true, Schema: map[string]any{"type": "integer"}},
+		},
+	})
+	assert.NilError(t, err)
+
+	manifest := &configuration.ServiceManifest{Name: "test", Arguments: map[string]any{"existing": true}}
+	c := &Command{manifest: manifest, log: testLogger(t)}
+	st := codegen.NewStencil(manifest, []*modules.Module{m}, c.log)
+
+	asked := make([]string, 0)
+	err = c.collectArguments(ctx, st, func(_ context.Context, arg *codegen.MissingArgument) (any, error) {
+		asked = append(asked, arg.Name)
+		if arg.Name == "team" {
+			return arg.Parse("devx")
+		}
+		return arg.Parse("8080")
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, asked, []string{"server.port", "team"})
+	assert.DeepEqual(t, manifest.Arguments, map[string]any{
+		"existing": true,
+		"server":   map[string]any{"port": 8080},
+		"team":     "devx",
+	})
+
+	b, err := os.ReadFile("service.yaml")
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "# my service\nname: test\narguments:\n  existing: true\n  server:\n    port: 8080\n  team: devx\n")
+
+	missing, err := st.MissingRequiredArguments(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(missing), 0)
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains the debug mode, which renders a single
+// template and prints what it was rendered with, read and created.
+
+package stencil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/getoutreach/stencil/internal/codegen"
+)
+
+// ErrDebugStopped is returned by Debug when rendering was stopped at a
+// breakpoint.
+var ErrDebugStopped = errors.New("stopped at breakpoint")
+
+// breakpointHelp lists the commands available at a breakpoint.
+const breakpointHelp = `Commands:
+  c, continue  continue rendering (also an empty line)
+  data         print the data passed to stencil.Break
+  values       print the values the template is rendered with
+  args         print the arguments read so far
+  hooks        print the module hooks read so far
+  globals      print the globals read so far
+  trace        print everything recorded so far
+  q, quit      stop rendering
+`
+
+// Debug renders only the provided template, its import path or its path
+// if only one module provides it, and writes a JSON trace of the values
+// it was rendered with, the arguments, module hooks and globals it read
+// and the files it created to out. Nothing is written to disk. The trace
+// is written even if rendering fails, up to the failure.
+//
+// If in is set, rendering pauses at each call to stencil.Break and
+// commands to inspect the trace are read from in.
+func (c *Command) Debug(ctx context.Context, template string, in io.Reader, out io.Writer) error {
+	st, _, err := c.newStencil(ctx)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	redactor := newRedactor(st.Secrets())
+	d := &codegen.Debugger{Template: template}
+	if in != nil {
+		d.OnBreak = newBreakpointPrompt(in, out, redactor)
+	}
+	st.SetDebugger(d)
+
+	c.log.Infof("Rendering template %s", template)
+	_, rerr := st.Render(ctx, c.log)
+	if errors.Is(rerr, codegen.ErrTemplateNotFound) || errors.Is(rerr, codegen.ErrAmbiguousTemplate) {
+		return rerr
+	}
+
+	if err := writeDebugJSON(out, &d.Trace, redactor); err != nil {
+		return err
+	}
+	return rerr
+}
+
+// newBreakpointPrompt returns a codegen.Debugger.OnBreak function that
+// reads commands from in until rendering is continued or stopped.
+func newBreakpointPrompt(in io.Reader, out io.Writer, redactor *strings.Replacer) func(*codegen.Breakpoint) error {
+	scanner := bufio.NewScanner(in)
+	return func(b *codegen.Breakpoint) error {
+		fmt.Fprintf(out, "Breakpoint in %s", b.Trace.Template)
+		if b.File != "" {
+			fmt.Fprintf(out, " (rendering %s)", b.File)
+		}
+		fmt.Fprintln(out, ", type 'help' for commands")
+
+		for {
+			fmt.Fprint(out, "(stencil) ")
+			if !scanner.Scan() {
+				// No more input, continue rendering without pausing
+				fmt.Fprintln(out)
+				return scanner.Err()
+			}
+
+			var v any
+			switch cmd := strings.TrimSpace(scanner.Text()); cmd {
+			case "", "c", "continue":
+				return nil
+			case "q", "quit":
+				return ErrDebugStopped
+			case "data":
+				v = b.Data
+			case "values":
+				v = b.Trace.Values
+			case "args":
+				v = b.Trace.Args
+			case "hooks":
+				v = b.Trace.ModuleHooks
+			case "globals":
+				v = b.Trace.Globals
+			case "trace":
+				v = b.Trace
+			case "help":
+				fmt.Fprint(out, breakpointHelp)
+				continue
+			default:
+				fmt.Fprintf(out, "Unknown command %q, type 'help' for commands\n", cmd)
+				continue
+			}
+
+			if err := writeDebugJSON(out, v, redactor); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeDebugJSON writes v as indented JSON to out, with the values of
+// secrets redacted. v is converted to generic values first so that the
+// secrets are redacted before they're escaped by the encoding, which
+// orders the keys of objects alphabetically.
+func writeDebugJSON(out io.Writer, v any, redactor *strings.Replacer) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode trace")
+	}
+
+	var generic any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&generic); err != nil {
+		return errors.Wrap(err, "failed to decode trace")
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(redactValue(generic, redactor)), "failed to encode trace")
+}
@@ -0,0 +1,65 @@
+package stencil
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/getoutreach/stencil/internal/codegen"
+)
+
+func TestBreakpointPrompt(t *testing.T) {
+	b := &codegen.Breakpoint{
+		Break: codegen.Break{File: "README.md", Data: []any{"here"}},
+		Trace: &codegen.Trace{
+			Template: "app/README.md.tpl",
+			Args: []codegen.ArgRead{
+				{Name: "token", Value: "s3cret", Source: codegen.ArgSourceServiceManifest},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	onBreak := newBreakpointPrompt(strings.NewReader("args\nbogus\ncontinue\nquit\n"), &out, newRedactor([]string{"s3cret"}))
+
+	assert.NilError(t, onBreak(b))
+	assert.Equal(t, out.String(), `Breakpoint in app/README.md.tpl (rendering README.md), type 'help' for commands
+(stencil) [
+  {
+    "name": "token",
+    "source": "service.yaml",
+    "value": "[REDACTED]"
+  }
+]
+(stencil) Unknown command "bogus", type 'help' for commands
+(stencil) `)
+
+	// The input is shared between breakpoints
+	assert.ErrorIs(t, onBreak(b), ErrDebugStopped)
+
+	// Rendering continues once there's no more input
+	assert.NilError(t, onBreak(b))
+}
+
+func TestWriteDebugJSONRedactsEscapedSecrets(t *testing.T) {
+	pem := "-----BEGIN KEY-----\nabc&def<ghi>\n-----END KEY-----"
+	quoted := `pass"word\&more`
+	tr := &codegen.Trace{
+		Template: "app/README.md.tpl",
+		Args: []codegen.ArgRead{
+			{Name: "key", Value: pem, Source: codegen.ArgSourceServiceManifest},
+			{Name: "password", Value: map[string]any{"value": "prefix " + quoted}, Source: codegen.ArgSourceServiceManifest},
+		},
+		Files: []codegen.TracedFile{{Name: "README.md", Mode: "-rw-r--r--", Contents: "a && b: " + pem}},
+	}
+
+	var out bytes.Buffer
+	assert.NilError(t, writeDebugJSON(&out, tr, newRedactor([]string{pem, quoted})))
+	for _, leak := range []string{"abc", "BEGIN KEY", "more", `\u0026`} {
+		assert.Assert(t, !strings.Contains(out.String(), leak), "expected %q to not be in the output:\n%s", leak, out.String())
+	}
+	assert.Assert(t, strings.Contains(out.String(), `"contents": "a && b: [REDACTED]"`), out.String())
+	assert.Assert(t, strings.Contains(out.String(), `"value": "prefix [REDACTED]"`), out.String())
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains the git-aware run mode, which checks
+// that the worktree is clean and commits the changes to a new branch.
+
+package stencil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/getoutreach/stencil/internal/git"
+	"github.com/getoutreach/stencil/internal/modules"
+	"github.com/getoutreach/stencil/pkg/stencil"
+)
+
+// SetGitMode configures the git-aware run mode. With commit the changes
+// are committed to a new branch named branch, or
+// stencil/update-<timestamp> if empty, with a message summarizing the
+// module version changes. The worktree must be checked to be clean with
+// CheckWorktree before anything is changed.
+func (c *Command) SetGitMode(commit bool, branch string) {
+	c.gitCommit = commit
+	c.gitBranch = branch
+}
+
+// CheckWorktree returns git.ErrDirtyWorktree if the git worktree of dir,
+// or of the current directory if empty, has uncommitted changes. Commands
+// call it once before they edit or render anything, the changes they make
+// would otherwise make the worktree dirty.
+func CheckWorktree(ctx context.Context, dir string) error {
+	clean, err := git.IsClean(ctx, dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to check git worktree")
+	}
+	if !clean {
+		return errors.Wrap(git.ErrDirtyWorktree, "commit or stash them first")
+	}
+	return nil
+}
+
+// commitChanges commits the changes made by the command to a new branch,
+// if enabled. The original branch is checked out again if committing
+// fails.
+func (c *Command) commitChanges(ctx context.Context, mods []*modules.Module) error {
+	if !c.gitCommit || c.dryRun {
+		return nil
+	}
+
+	clean, err := git.IsClean(ctx, "")
+	if err != nil {
+		return errors.Wrap(err, "failed to check git worktree")
+	}
+	if clean {
+		c.log.Info("Nothing changed, not committing")
+		return nil
+	}
+
+	original, err := git.CurrentBranch(ctx, "")
+	if err != nil {
+		return errors.Wrap(err, "failed to get current branch")
+	}
+
+	branch := c.gitBranch
+	if branch == "" {
+		branch = "stencil/update-" + time.Now().UTC().Format("20060102150405")
+	}
+
+	c.log.Infof("Committing changes to branch %s", branch)
+	if err := git.CreateBranch(ctx, "", branch); err != nil {
+		return errors.Wrapf(err, "failed to create branch %q", branch)
+	}
+
+	if err := git.CommitAll(ctx, "", commitMessage(c.lock, mods)); err != nil {
+		if cerr := git.Checkout(ctx, "", original); cerr != nil {
+			c.log.WithError(cerr).Errorf("Failed to check out original branch %s", original)
+		} else if derr := git.DeleteBranch(ctx, "", branch); derr != nil {
+			c.log.WithError(derr).Warnf("Failed to delete branch %s", branch)
+		}
+		return errors.Wrap(err, "failed to commit changes")
+	}
+	return nil
+}
+
+// commitMessage returns the message of the commit containing the changes
+// made by rendering mods, summarizing how their versions changed since
+// the last run recorded in lock.
+func commitMessage(lock *stencil.Lockfile, mods []*modules.Module) string {
+	previous := make(map[string]string)
+	if lock != nil {
+		for _, m := range lock.Modules {
+			previous[m.Name] = m.Version
+		}
+	}
+
+	var changes []string
+	for _, m := range mods {
+		old, ok := previous[m.Name]
+		delete(previous, m.Name)
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("- %s: added at %s", m.Name, m.Version))
+		case old != m.Version:
+			changes = append(changes, fmt.Sprintf("- %s: %s -> %s", m.Name, old, m.Version))
+		}
+	}
+	for name := range previous {
+		changes = append(changes, fmt.Sprintf("- %s: removed", name))
+	}
+	sort.Strings(changes)
+
+	if len(changes) == 0 {
+		return "chore: re-render stencil templates"
+	}
+	return "chore: update stencil modules\n\n" + strings.Join(changes, "\n") + "\n"
+}
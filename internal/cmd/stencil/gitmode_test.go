@@ -0,0 +1,44 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for the git-aware run mode.
+
+package stencil
+
+import (
+	"testing"
+
+	"github.com/getoutreach/stencil/internal/modules"
+	"github.com/getoutreach/stencil/pkg/stencil"
+	"gotest.tools/v3/assert"
+)
+
+func TestCommitMessage(t *testing.T) {
+	lock := &stencil.Lockfile{
+		Modules: []*stencil.LockfileModuleEntry{
+			{Name: "github.com/getoutreach/stencil-base", Version: "v1.0.0"},
+			{Name: "github.com/getoutreach/stencil-circleci", Version: "v1.2.0"},
+			{Name: "github.com/getoutreach/stencil-golang", Version: "v2.0.0"},
+		},
+	}
+	mods := []*modules.Module{
+		{Name: "github.com/getoutreach/stencil-base", Version: "v1.1.0"},
+		{Name: "github.com/getoutreach/stencil-golang", Version: "v2.0.0"},
+		{Name: "github.com/getoutreach/stencil-actions", Version: "v0.3.0"},
+	}
+
+	assert.Equal(t, commitMessage(lock, mods), `chore: update stencil modules
+
+- github.com/getoutreach/stencil-actions: added at v0.3.0
+- github.com/getoutreach/stencil-base: v1.0.0 -> v1.1.0
+- github.com/getoutreach/stencil-circleci: removed
+`)
+
+	assert.Equal(t, commitMessage(lock, mods[1:2]), `chore: update stencil modules
+
+- github.com/getoutreach/stencil-base: removed
+- github.com/getoutreach/stencil-circleci: removed
+`)
+
+	lock.Modules = lock.Modules[2:]
+	assert.Equal(t, commitMessage(lock, mods[1:2]), "chore: re-render stencil templates")
+}
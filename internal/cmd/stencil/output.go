@@ -0,0 +1,114 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains helpers for writing the rendered files
+// to an alternate output directory or tarball.
+
+package stencil
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrOutsideOutput is returned when a file outside of the project, which
+// modules may write with the writeOutsideProject capability, is rendered
+// to an alternate output directory or tarball.
+var ErrOutsideOutput = errors.New("can't write a file outside of the project to an alternate output")
+
+// outputPath returns the path name, relative to the project directory, is
+// written to. Names outside of the project can't be written to an output
+// directory, since they'd be written outside of it.
+func (c *Command) outputPath(name string) (string, error) {
+	if c.outputDir == "" {
+		return name, nil
+	}
+	if !filepath.IsLocal(name) {
+		return "", errors.Wrapf(ErrOutsideOutput, "%q", name)
+	}
+	return filepath.Join(c.outputDir, name), nil
+}
+
+// writeTar writes the contents of dir to a tarball at path, compressed
+// with gzip if path ends with .gz or .tgz.
+func writeTar(path, dir string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create tarball")
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	var w io.Writer = f
+	if strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".tgz") {
+		gw := gzip.NewWriter(f)
+		defer func() {
+			if cerr := gw.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = gw
+	}
+
+	tw := tar.NewWriter(w)
+	defer func() {
+		if cerr := tw.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return errors.Wrap(filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == dir {
+			return err
+		}
+
+		inf, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if inf.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+
+		hdr, err := tar.FileInfoHeader(inf, link)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !inf.Mode().IsRegular() {
+			return nil
+		}
+
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	}), "failed to write tarball")
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for writing to an alternate
+// output.
+
+package stencil
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/getoutreach/stencil/internal/codegen"
+	"gotest.tools/v3/assert"
+)
+
+func TestWriteFileToOutputDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	out := t.TempDir()
+	assert.NilError(t, os.WriteFile("unchanged", []byte("same"), 0o644))
+	assert.NilError(t, os.WriteFile("deleted", []byte("old"), 0o644))
+
+	newFile := func(name, contents string) *codegen.File {
+		f, err := codegen.NewFile(name, 0o644, time.Now())
+		assert.NilError(t, err)
+		f.SetContents(contents)
+		return f
+	}
+	deleted := newFile("deleted", "")
+	deleted.Deleted = true
+
+	assert.NilError(t, os.Symlink("unchanged", "link"))
+	link := newFile("link", "")
+	link.SetSymlink("unchanged")
+
+	c := &Command{log: testLogger(t), outputDir: out}
+	for _, f := range []*codegen.File{newFile("unchanged", "same"), newFile("nested/created", "new"), deleted, link} {
+		assert.NilError(t, c.writeFile(f))
+	}
+
+	// Changes are still relative to the project directory
+	assert.DeepEqual(t, c.changedFiles, []string{"nested/created", "deleted"})
+
+	b, err := os.ReadFile(filepath.Join(out, "nested", "created"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "new")
+	_, err = os.Stat(filepath.Join(out, "unchanged"))
+	assert.NilError(t, err)
+	target, err := os.Readlink(filepath.Join(out, "link"))
+	assert.NilError(t, err)
+	assert.Equal(t, target, "unchanged")
+
+	// The project directory is left untouched
+	_, err = os.Stat("deleted")
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join("nested", "created"))
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestWriteFileOutsideProjectToOutputDir(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	assert.NilError(t, os.Mkdir(project, 0o755))
+	t.Chdir(project)
+
+	f, err := codegen.NewFile(filepath.Join("..", "outside"), 0o644, time.Now())
+	assert.NilError(t, err)
+	f.SetContents("escaped")
+
+	c := &Command{log: testLogger(t), outputDir: filepath.Join(root, "out")}
+	assert.Assert(t, errors.Is(c.writeFile(f), ErrOutsideOutput))
+
+	_, err = os.Stat(filepath.Join(root, "outside"))
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestWriteTar(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "scripts"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "scripts", "build.sh"), []byte("#!/bin/sh\n"), 0o755))
+	assert.NilError(t, os.Symlink("scripts/build.sh", filepath.Join(dir, "build")))
+
+	path := filepath.Join(t.TempDir(), "out.tar.gz")
+	assert.NilError(t, writeTar(path, dir))
+
+	f, err := os.Open(path)
+	assert.NilError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	assert.NilError(t, err)
+
+	got := map[string]string{}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NilError(t, err)
+
+		b, err := io.ReadAll(tr)
+		assert.NilError(t, err)
+		got[hdr.Name] = string(b) + hdr.Linkname
+		if hdr.Name == "scripts/build.sh" {
+			assert.Equal(t, os.FileMode(hdr.Mode).Perm(), os.FileMode(0o755))
+		}
+	}
+	assert.DeepEqual(t, got, map[string]string{
+		"build":            "scripts/build.sh",
+		"scripts/":         "",
+		"scripts/build.sh": "#!/bin/sh\n",
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains a logrus hook that redacts the values of
+// secrets from log entries, and helpers for redacting them from values.
+
+package stencil
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// redacted replaces the value of a secret in log entries.
+const redacted = "[REDACTED]"
+
+// redactHook is a logrus hook that replaces the values of secrets in the
+// message and fields of log entries.
+type redactHook struct {
+	replacer *strings.Replacer
+}
+
+// addRedactHook adds a hook to the logger backing log that redacts the
+// provided secrets. This is a no-op if there are no secrets, or log isn't
+// backed by a *logrus.Logger.
+func addRedactHook(log logrus.FieldLogger, secrets []string) {
+	if len(secrets) == 0 {
+		return
+	}
+
+	var l *logrus.Logger
+	switch t := log.(type) {
+	case *logrus.Logger:
+		l = t
+	case *logrus.Entry:
+		l = t.Logger
+	default:
+		return
+	}
+
+	l.AddHook(&redactHook{replacer: newRedactor(secrets)})
+}
+
+// newRedactor returns a strings.Replacer that replaces the provided
+// secrets.
+func newRedactor(secrets []string) *strings.Replacer {
+	oldnew := make([]string, 0, len(secrets)*2)
+	for _, s := range secrets {
+		oldnew = append(oldnew, s, redacted)
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+// RedactValue returns a copy of v, an argument value, with the provided
+// secrets replaced in all of its strings.
+func RedactValue(v any, secrets []string) any {
+	if len(secrets) == 0 {
+		return v
+	}
+	return redactValue(v, newRedactor(secrets))
+}
+
+// redactValue implements RedactValue.
+func redactValue(v any, r *strings.Replacer) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			out[k] = redactValue(e, r)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = redactValue(e, r)
+		}
+		return out
+	case string:
+		return r.Replace(t)
+	default:
+		return v
+	}
+}
+
+// Levels returns the levels the hook fires for, all of them.
+func (h *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire redacts the secrets from the entry.
+func (h *redactHook) Fire(e *logrus.Entry) error {
+	e.Message = h.replacer.Replace(e.Message)
+	for k, v := range e.Data {
+		switch t := v.(type) {
+		case string:
+			e.Data[k] = h.replacer.Replace(t)
+		case error:
+			if msg := h.replacer.Replace(t.Error()); msg != t.Error() {
+				e.Data[k] = errors.New(msg)
+			}
+		}
+	}
+	return nil
+}
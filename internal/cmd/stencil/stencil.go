@@ -8,11 +8,14 @@
 package stencil
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	gerrors "errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	msemver "github.com/Masterminds/semver/v3"
@@ -65,6 +68,46 @@ type Command struct {
 	// upgrades without a prompt or not
 	allowMajorVersionUpgrades bool
 
+	// skipPostRun denotes if we should skip running post-run commands
+	skipPostRun bool
+
+	// onlyPostRun, if set, is the names of the only post-run commands
+	// that should be ran
+	onlyPostRun []string
+
+	// postRunReport, if set, is the path a JSON report of the post-run
+	// commands is written to
+	postRunReport string
+
+	// interactive denotes if we should prompt for missing required
+	// arguments when stdin is a terminal
+	interactive bool
+
+	// changedFiles are the files that were created, updated or deleted
+	// when writing the rendered templates
+	changedFiles []string
+
+	// outputDir, if set, is the directory files are written to instead
+	// of the project directory
+	outputDir string
+
+	// outputTar, if set, is the path of a tarball files are written to
+	// instead of the project directory
+	outputTar string
+
+	// gitCommit denotes if the changes should be committed to a new git
+	// branch, named gitBranch or generated if empty
+	gitCommit bool
+	gitBranch string
+
+	// tx records the changes made to files so that they can be rolled
+	// back if the command fails
+	tx transaction
+
+	// resolveCache, if set, is shared with the other commands rendering
+	// projects in the same invocation
+	resolveCache *modules.ResolveCache
+
 	// token is the github token used for fetching modules
 	token            cfg.SecretData
 	resolverRoutines int
@@ -72,7 +115,8 @@ type Command struct {
 
 // NewCommand creates a new stencil command.
 func NewCommand(log logrus.FieldLogger, s *configuration.ServiceManifest, dryRun, frozen, usePrerelease,
-	allowMajorVersionUpgrades bool, resolverRoutines int,
+	allowMajorVersionUpgrades bool, resolverRoutines int, skipPostRun bool, onlyPostRun []string,
+	postRunReport string, interactive bool,
 ) *Command {
 	l, err := stencil.LoadLockfile("")
 	if err != nil && !errors.Is(err, os.ErrNotExist) {
@@ -102,17 +146,90 @@ func NewCommand(log logrus.FieldLogger, s *configuration.ServiceManifest, dryRun
 		allowMajorVersionUpgrades: allowMajorVersionUpgrades,
 		token:                     token,
 		resolverRoutines:          resolverRoutines,
+		skipPostRun:               skipPostRun,
+		onlyPostRun:               onlyPostRun,
+		postRunReport:             postRunReport,
+		interactive:               interactive,
 	}
 }
 
+// UseResolveCache makes the command share the resolved versions and the
+// filesystems of modules with other commands using cache.
+func (c *Command) UseResolveCache(cache *modules.ResolveCache) {
+	c.resolveCache = cache
+}
+
+// SetOutput makes the command write the rendered files, and the
+// lockfile, to dir or to a tarball at tar instead of the project
+// directory. Existing files are still read from the project directory,
+// e.g. to preserve blocks, and post-run commands aren't ran since the
+// output only contains the rendered files. Rendering files outside of the
+// project to an alternate output fails with ErrOutsideOutput.
+func (c *Command) SetOutput(dir, tar string) {
+	c.outputDir = dir
+	c.outputTar = tar
+}
+
+// ChangedFiles returns the files that were created, updated or deleted
+// by Run.
+func (c *Command) ChangedFiles() []string {
+	return c.changedFiles
+}
+
 // Run fetches dependencies of the root modules and builds the layered filesystem,
 // after that GenerateFiles is called to actually walk the filesystem and render
 // the templates. This step also does minimal post-processing of the dependencies
 // manifests.
 func (c *Command) Run(ctx context.Context) error {
+	st, mods, err := c.newStencil(ctx)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	c.log.Info("Rendering templates")
+	tpls, err := st.Render(ctx, c.log)
+	if err != nil {
+		return err
+	}
+
+	if c.outputTar != "" && !c.dryRun {
+		dir, err := os.MkdirTemp("", "stencil-output-*")
+		if err != nil {
+			return errors.Wrap(err, "failed to create temporary output directory")
+		}
+		defer os.RemoveAll(dir)
+		c.outputDir = dir
+	}
+
+	if err := c.writeFiles(ctx, st, tpls); err != nil {
+		return c.rollbackFiles(err)
+	}
+
+	if c.outputTar != "" && !c.dryRun {
+		c.log.Infof("Writing rendered files to %s", c.outputTar)
+		if err := writeTar(c.outputTar, c.outputDir); err != nil {
+			return c.rollbackFiles(err)
+		}
+	}
+
+	if err := c.runPostRun(ctx, st); err != nil {
+		return c.rollbackFiles(err)
+	}
+	if err := c.commitFiles(); err != nil {
+		return err
+	}
+
+	return c.commitChanges(ctx, mods)
+}
+
+// newStencil resolves the modules of the service manifest and returns
+// the renderer for them, with the arguments resolved and the native
+// extensions loaded. The renderer must be closed by the caller.
+func (c *Command) newStencil(ctx context.Context) (*codegen.Stencil, []*modules.Module, error) {
 	if c.frozenLockfile {
 		if err := c.useModulesFromLock(); err != nil {
-			return errors.Wrap(err, "failed to use lockfile for modules")
+			return nil, nil, errors.Wrap(err, "failed to use lockfile for modules")
 		}
 	}
 
@@ -122,44 +239,98 @@ func (c *Command) Run(ctx context.Context) error {
 		Token:               c.token,
 		Log:                 c.log,
 		ConcurrentResolvers: c.resolverRoutines,
+		Cache:               c.resolveCache,
 	})
 	if err != nil {
-		return errors.Wrap(err, "failed to process modules list")
+		return nil, nil, errors.Wrap(err, "failed to process modules list")
 	}
 
 	if err := c.checkForMajorVersions(ctx, mods); err != nil {
-		return errors.Wrap(err, "failed to handle major version upgrade")
+		return nil, nil, errors.Wrap(err, "failed to handle major version upgrade")
 	}
 
 	if err := c.validateStencilVersion(ctx, mods, app.Version); err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	granted, err := c.checkCapabilities(ctx, mods)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to grant module capabilities")
 	}
 
 	st := codegen.NewStencil(c.manifest, mods, c.log)
-	defer st.Close()
+	st.GrantCapabilities(granted)
+	st.SetLockfile(c.lock)
+	if err := c.setupStencil(ctx, st); err != nil {
+		st.Close()
+		return nil, nil, err
+	}
+	return st, mods, nil
+}
 
-	c.log.Info("Loading native extensions")
-	if err := st.RegisterExtensions(ctx); err != nil {
-		return err
+// setupStencil resolves the arguments of st and loads its native
+// extensions.
+func (c *Command) setupStencil(ctx context.Context, st *codegen.Stencil) error {
+	if err := st.ResolveArguments(ctx, "."); err != nil {
+		return errors.Wrap(err, "failed to resolve arguments")
 	}
+	addRedactHook(c.log, st.Secrets())
 
-	c.log.Info("Rendering templates")
-	tpls, err := st.Render(ctx, c.log)
-	if err != nil {
-		return err
+	if err := c.promptForArguments(ctx, st); err != nil {
+		return errors.Wrap(err, "failed to collect missing arguments")
 	}
 
-	if err := c.writeFiles(st, tpls); err != nil {
-		return err
+	c.log.Info("Loading native extensions")
+	return st.RegisterExtensions(ctx)
+}
+
+// runPostRun runs the post-run commands of the modules, unless they're
+// skipped.
+func (c *Command) runPostRun(ctx context.Context, st *codegen.Stencil) error {
+	if c.outputDir != "" || c.outputTar != "" {
+		c.log.Info("Skipping post-run commands, rendering to an alternate output")
+		return nil
 	}
 
-	// Can't dry run post run yet
-	if c.dryRun {
-		c.log.Info("Skipping post-run commands, dry-run")
+	if c.skipPostRun {
+		c.log.Info("Skipping post-run commands, --skip-post-run was set")
 		return nil
 	}
 
-	return st.PostRun(ctx, c.log)
+	results, err := st.PostRun(ctx, c.log, &codegen.PostRunOptions{
+		DryRun:       c.dryRun,
+		Only:         c.onlyPostRun,
+		ChangedFiles: c.changedFiles,
+	})
+	if c.postRunReport != "" {
+		if rerr := writePostRunReport(c.postRunReport, results); rerr != nil {
+			c.log.WithError(rerr).Warn("Failed to write post-run report")
+		}
+	}
+	return err
+}
+
+// postRunReport is the JSON report of the post-run commands written to
+// the path provided by --post-run-report.
+type postRunReport struct {
+	// Commands are the results of each post-run command, in the order
+	// they were ran
+	Commands []*codegen.PostRunResult `json:"commands"`
+}
+
+// writePostRunReport writes the results of the post-run commands to path
+// as JSON.
+func writePostRunReport(path string, results []*codegen.PostRunResult) error {
+	if results == nil {
+		results = []*codegen.PostRunResult{}
+	}
+
+	b, err := json.MarshalIndent(&postRunReport{Commands: results}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode post-run report")
+	}
+
+	return errors.Wrap(os.WriteFile(path, append(b, '\n'), 0o644), "failed to write post-run report")
 }
 
 // validateStencilVersion ensures that the running Stencil version is
@@ -371,67 +542,321 @@ func (c *Command) promptMajorVersion(ctx context.Context, m *modules.Module, las
 	return nil
 }
 
+// checkCapabilities ensures that every capability requested by a module has
+// been granted, either by the permissions in the service manifest or by
+// consent given on a previous run (recorded in the lockfile). Capabilities
+// that haven't been granted yet are printed and, if running in a terminal,
+// the user is asked for consent. The granted capabilities are returned keyed
+// by module name.
+func (c *Command) checkCapabilities(ctx context.Context,
+	mods []*modules.Module) (map[string][]configuration.Capability, error) {
+	consented := make(map[string][]configuration.Capability)
+	if c.lock != nil {
+		for _, l := range c.lock.Modules {
+			consented[l.Name] = l.Capabilities
+		}
+	}
+
+	granted := make(map[string][]configuration.Capability)
+	for _, m := range mods {
+		mf, err := m.Manifest(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get module manifest")
+		}
+
+		var ungranted []configuration.Capability
+		for _, capability := range mf.RequestedCapabilities() {
+			if c.manifest.Permissions.Granted(m.Name, capability) || slices.Contains(consented[m.Name], capability) {
+				granted[m.Name] = append(granted[m.Name], capability)
+				continue
+			}
+			ungranted = append(ungranted, capability)
+		}
+		if len(ungranted) == 0 {
+			continue
+		}
+
+		if err := c.promptCapabilities(ctx, m, ungranted); err != nil {
+			return nil, err
+		}
+		granted[m.Name] = append(granted[m.Name], ungranted...)
+	}
+
+	return granted, nil
+}
+
+// promptCapabilities prints the capabilities requested by a module and asks
+// the user for consent to grant them.
+func (c *Command) promptCapabilities(ctx context.Context, m *modules.Module, caps []configuration.Capability) error {
+	c.log.Infof("Module %q requests the following capabilities:", m.Name)
+	for _, capability := range caps {
+		c.log.Infof(" - %s: %s", capability, capability.Description())
+	}
+
+	// If we're not a terminal, we can't ask for consent
+	// so we error out informing the user how to fix this.
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("%w: module %q, stdin is not a terminal, grant them under 'permissions' in service.yaml to continue",
+			codegen.ErrCapabilityNotGranted, m.Name)
+	}
+
+	proceed, err := prompt.Confirm(ctx, prompt.ConfirmConfig{
+		Message: fmt.Sprintf("Grant these capabilities to module %q?", m.Name),
+		Default: false,
+	})
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		return fmt.Errorf("%w: module %q, grant them under 'permissions' in service.yaml to continue",
+			codegen.ErrCapabilityNotGranted, m.Name)
+	}
+
+	return nil
+}
+
 // writeFile writes a codegen.File to disk based on its current state.
 func (c *Command) writeFile(f *codegen.File) error {
+	if f.SymlinkTarget() != "" && !f.Deleted && !f.Skipped {
+		return c.writeSymlink(f)
+	}
+
 	action := "Created"
+	changed := true
 	if f.Deleted {
 		action = "Deleted"
+		_, err := os.Stat(f.Name())
+		changed = err == nil
 
-		if !c.dryRun {
-			os.Remove(f.Name())
+		if !c.dryRun && c.outputDir == "" {
+			if err := c.tx.remove(f.Name()); err != nil {
+				return errors.Wrapf(err, "failed to delete %q", f.Name())
+			}
 		}
 	} else if f.Skipped {
 		action = "Skipped"
-	} else if _, err := os.Stat(f.Name()); err == nil {
+		changed = false
+	} else if existing, err := os.ReadFile(f.Name()); err == nil {
 		action = "Updated"
+		changed = !bytes.Equal(existing, f.Bytes()) || c.modeChanged(f) || isSymlink(f.Name())
+	}
+
+	if changed {
+		c.changedFiles = append(c.changedFiles, f.Name())
 	}
 
 	if action == "Created" || action == "Updated" {
 		if !c.dryRun {
-			if err := os.MkdirAll(filepath.Dir(f.Name()), 0o755); err != nil {
-				return errors.Wrapf(err, "failed to ensure directory for %q existed", f.Name())
+			// Symlinks are replaced, not written through
+			path, err := c.outputPath(f.Name())
+			if err != nil {
+				return err
+			}
+			if err := c.tx.writeFile(path, f.Bytes(), c.fileMode(f, path)); err != nil {
+				return errors.Wrapf(err, "failed to write %q", f.Name())
 			}
+		}
+	}
+
+	c.logFileAction(action, f.Name())
+	if f.Skipped {
+		c.log.Debug("Skipped: ", f.SkippedReason)
+	}
+	return nil
+}
+
+// writeSymlink creates the symlink f, replacing anything that already
+// exists at its path.
+func (c *Command) writeSymlink(f *codegen.File) error {
+	action := "Created"
+	changed := true
+	if _, err := os.Lstat(f.Name()); err == nil {
+		action = "Updated"
+		target, err := os.Readlink(f.Name())
+		changed = err != nil || target != f.SymlinkTarget()
+	}
+
+	if changed {
+		c.changedFiles = append(c.changedFiles, f.Name())
+	}
+
+	// An alternate output contains every rendered file, like regular
+	// files symlinks are written there even if they're unchanged
+	if (changed || c.outputDir != "") && !c.dryRun {
+		path, err := c.outputPath(f.Name())
+		if err != nil {
+			return err
+		}
+		if err := c.tx.symlink(f.SymlinkTarget(), path); err != nil {
+			return errors.Wrapf(err, "failed to create symlink %q", f.Name())
+		}
+	}
 
-			if err := os.WriteFile(f.Name(), f.Bytes(), f.Mode()); err != nil {
-				return errors.Wrapf(err, "failed to create %q", f.Name())
+	c.logFileAction(action, f.Name()+" -> "+f.SymlinkTarget())
+	return nil
+}
+
+// writeOrphanedBlocks warns about the orphaned blocks of f, if it has any,
+// and writes them to a sidecar file so that their contents aren't lost.
+func (c *Command) writeOrphanedBlocks(f *codegen.File) error {
+	if len(f.OrphanedBlocks) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(f.OrphanedBlocks))
+	for name := range f.OrphanedBlocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sidecar, err := f.OrphanedBlocksFile()
+	if err != nil {
+		return errors.Wrapf(err, "failed to create orphaned blocks file for %q", f.Name())
+	}
+
+	c.log.Warnf("Block(s) %s in %q had contents but no longer exist, their contents were saved to %q",
+		strings.Join(names, ", "), f.Name(), sidecar.Name())
+	return c.writeFile(sidecar)
+}
+
+// removeStaleFiles removes the assets and symlinks recorded in the lockfile
+// by the last run that are no longer generated by any module.
+func (c *Command) removeStaleFiles(tpls []*codegen.Template) error {
+	if c.lock == nil {
+		return nil
+	}
+
+	generated := make(map[string]bool)
+	for _, tpl := range tpls {
+		for _, f := range tpl.Files {
+			generated[f.Name()] = true
+		}
+	}
+
+	for _, lf := range c.lock.Files {
+		if lf.Type == "" || generated[lf.Name] {
+			continue
+		}
+
+		// Leave the path alone if it's already gone, or if a symlink has
+		// since been replaced by something else.
+		inf, err := os.Lstat(lf.Name)
+		if err != nil || (lf.Type == stencil.LockfileFileTypeSymlink && inf.Mode()&os.ModeSymlink == 0) {
+			continue
+		}
+
+		c.changedFiles = append(c.changedFiles, lf.Name)
+		if !c.dryRun && c.outputDir == "" {
+			if err := c.tx.remove(lf.Name); err != nil {
+				return errors.Wrapf(err, "failed to remove %q", lf.Name)
 			}
 		}
+		c.logFileAction("Deleted", lf.Name)
 	}
 
-	msg := fmt.Sprintf("  -> %s %s", action, f.Name())
+	return nil
+}
+
+// logFileAction logs the action taken on the provided file.
+func (c *Command) logFileAction(action, name string) {
+	msg := fmt.Sprintf("  -> %s %s", action, name)
 	if c.dryRun {
 		msg += " (dry-run)"
 	}
 	c.log.Info(msg)
-	if f.Skipped {
-		c.log.Debug("Skipped: ", f.SkippedReason)
+}
+
+// isSymlink returns true if path exists and is a symlink.
+func isSymlink(path string) bool {
+	inf, err := os.Lstat(path)
+	return err == nil && inf.Mode()&os.ModeSymlink != 0
+}
+
+// modeChanged returns true if f exists on disk with permissions that are
+// different from the ones it was rendered with.
+func (c *Command) modeChanged(f *codegen.File) bool {
+	if f.Mode().Perm() == 0 {
+		return false
 	}
-	return nil
+
+	inf, err := os.Stat(f.Name())
+	return err == nil && inf.Mode().Perm() != f.Mode().Perm()
+}
+
+// fileMode returns the mode f should be written to path with: the mode it
+// was rendered with, or the mode of the existing file if it has none.
+func (c *Command) fileMode(f *codegen.File, path string) os.FileMode {
+	if perm := f.Mode().Perm(); perm != 0 {
+		return perm
+	}
+	if inf, err := os.Stat(path); err == nil {
+		return inf.Mode().Perm()
+	}
+	return 0o644
 }
 
-// writeFiles writes the files to disk.
-func (c *Command) writeFiles(st *codegen.Stencil, tpls []*codegen.Template) error {
+// writeFiles writes the files to disk. Every file is replaced atomically,
+// but the changes are only kept once the command commits them, see
+// commitFiles and rollbackFiles. Writing stops if ctx is canceled.
+func (c *Command) writeFiles(ctx context.Context, st *codegen.Stencil, tpls []*codegen.Template) error {
 	c.log.Infof("Writing template(s) to disk")
 	for _, tpl := range tpls {
 		for i := range tpl.Files {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "interrupted while writing files")
+			}
+
 			if err := c.writeFile(tpl.Files[i]); err != nil {
 				return err
 			}
+
+			if err := c.writeOrphanedBlocks(tpl.Files[i]); err != nil {
+				return err
+			}
 		}
 	}
 
+	if err := c.removeStaleFiles(tpls); err != nil {
+		return err
+	}
+
 	// Don't generate a lockfile in dry-run mode
 	if c.dryRun {
 		return nil
 	}
 
-	l := st.GenerateLockfile(tpls)
-	f, err := os.Create(stencil.LockfileName)
+	var buf bytes.Buffer
+	if err := yaml.NewEncoder(&buf).Encode(st.GenerateLockfile(tpls)); err != nil {
+		return errors.Wrap(err, "failed to encode lockfile into yaml")
+	}
+	path, err := c.outputPath(stencil.LockfileName)
 	if err != nil {
-		return errors.Wrap(err, "failed to create lockfile")
+		return err
+	}
+	return errors.Wrap(c.tx.writeFile(path, buf.Bytes(), 0o644),
+		"failed to write lockfile")
+}
+
+// commitFiles keeps the changes made by writeFiles. Failing to remove the
+// backups of the original files isn't fatal, so this never fails.
+func (c *Command) commitFiles() error {
+	if err := c.tx.commit(); err != nil {
+		c.log.WithError(err).Warn("Failed to remove backups of changed files")
+	}
+	return nil
+}
+
+// rollbackFiles restores the files changed by writeFiles, including the
+// lockfile, to their original state and returns err. Files that only
+// post-run commands changed aren't restored, see transaction.
+func (c *Command) rollbackFiles(err error) error {
+	if len(c.tx.changes) == 0 {
+		return err
 	}
-	defer f.Close()
 
-	return errors.Wrap(yaml.NewEncoder(f).Encode(l),
-		"failed to encode lockfile into yaml")
+	c.log.Warn("Rolling back changed files")
+	if rerr := c.tx.rollback(); rerr != nil {
+		c.log.WithError(rerr).Error("Failed to roll back changed files")
+	}
+	return err
 }
@@ -6,11 +6,18 @@ package stencil
 
 import (
 	"context"
+	"encoding/json"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/getoutreach/stencil/internal/codegen"
 	"github.com/getoutreach/stencil/internal/modules"
+	"github.com/getoutreach/stencil/internal/modules/modulestest"
 	"github.com/getoutreach/stencil/pkg/configuration"
 	"github.com/getoutreach/stencil/pkg/stencil"
 	"github.com/go-git/go-billy/v5/osfs"
@@ -216,3 +223,221 @@ func TestValidateStencilVersionConstraintValidationSuccess(t *testing.T) {
 	}
 	assert.NilError(t, c.validateStencilVersion(ctx, mods, "v1.10.0"))
 }
+
+func TestCheckCapabilities(t *testing.T) {
+	ctx := context.Background()
+	m, err := modulestest.NewModuleFromTemplates(&configuration.TemplateRepositoryManifest{
+		Name:           "example.com/stencil-test",
+		Capabilities:   []configuration.Capability{configuration.CapabilityDeleteFiles},
+		PostRunCommand: []*configuration.PostRunCommandSpec{{Name: "hello", Command: "echo hello"}},
+	})
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name        string
+		permissions *configuration.Permissions
+		lock        *stencil.Lockfile
+		want        map[string][]configuration.Capability
+		wantErr     error
+	}{
+		{
+			name:    "should refuse ungranted capabilities when not in a terminal",
+			wantErr: codegen.ErrCapabilityNotGranted,
+		},
+		{
+			name: "should refuse partially granted capabilities",
+			permissions: &configuration.Permissions{
+				Modules: map[string][]configuration.Capability{
+					"example.com/stencil-test": {configuration.CapabilityPostRunCommands},
+				},
+			},
+			wantErr: codegen.ErrCapabilityNotGranted,
+		},
+		{
+			name:        "should grant all capabilities to trusted modules",
+			permissions: &configuration.Permissions{TrustedModules: []string{"example.com/stencil-test"}},
+			want: map[string][]configuration.Capability{
+				"example.com/stencil-test": {configuration.CapabilityDeleteFiles, configuration.CapabilityPostRunCommands},
+			},
+		},
+		{
+			name: "should use consent recorded in the lockfile",
+			permissions: &configuration.Permissions{
+				Modules: map[string][]configuration.Capability{
+					"example.com/stencil-test": {configuration.CapabilityPostRunCommands},
+				},
+			},
+			lock: &stencil.Lockfile{
+				Modules: []*stencil.LockfileModuleEntry{{
+					Name:         "example.com/stencil-test",
+					Capabilities: []configuration.Capability{configuration.CapabilityDeleteFiles},
+				}},
+			},
+			want: map[string][]configuration.Capability{
+				"example.com/stencil-test": {configuration.CapabilityDeleteFiles, configuration.CapabilityPostRunCommands},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Command{
+				lock:     tt.lock,
+				manifest: &configuration.ServiceManifest{Name: "testing", Permissions: tt.permissions},
+				log:      testLogger(t),
+			}
+			got, err := c.checkCapabilities(ctx, []*modules.Module{m})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestWriteFileTracksChangedFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("unchanged", []byte("same"), 0o644))
+	assert.NilError(t, os.WriteFile("updated", []byte("old"), 0o644))
+	assert.NilError(t, os.WriteFile("deleted", []byte("old"), 0o644))
+
+	newFile := func(name, contents string) *codegen.File {
+		f, err := codegen.NewFile(name, 0o644, time.Now())
+		assert.NilError(t, err)
+		f.SetContents(contents)
+		return f
+	}
+
+	deleted := newFile("deleted", "")
+	deleted.Deleted = true
+	alreadyDeleted := newFile("already-deleted", "")
+	alreadyDeleted.Deleted = true
+	skipped := newFile("skipped", "new")
+	skipped.Skipped = true
+
+	c := &Command{log: testLogger(t)}
+	for _, f := range []*codegen.File{
+		newFile("unchanged", "same"), newFile("updated", "new"), newFile("created", "new"),
+		deleted, alreadyDeleted, skipped,
+	} {
+		assert.NilError(t, c.writeFile(f))
+	}
+
+	assert.DeepEqual(t, c.changedFiles, []string{"updated", "created", "deleted"})
+}
+
+func TestWriteFileAppliesModeToExistingFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("script.sh", []byte("#!/usr/bin/env bash\n"), 0o644))
+
+	f, err := codegen.NewFile("script.sh", 0o755, time.Now())
+	assert.NilError(t, err)
+	f.SetContents("#!/usr/bin/env bash\n")
+
+	c := &Command{log: testLogger(t)}
+	assert.NilError(t, c.writeFile(f))
+
+	inf, err := os.Stat("script.sh")
+	assert.NilError(t, err)
+	assert.Equal(t, inf.Mode().Perm(), os.FileMode(0o755))
+	assert.DeepEqual(t, c.changedFiles, []string{"script.sh"})
+}
+
+func TestWriteSymlink(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("Makefile", []byte("all:\n"), 0o644))
+
+	f, err := codegen.NewFile("scripts/Makefile", 0o644, time.Now())
+	assert.NilError(t, err)
+	f.SetSymlink("../Makefile")
+
+	c := &Command{log: testLogger(t)}
+	assert.NilError(t, c.writeFile(f))
+	assert.NilError(t, c.writeFile(f))
+
+	target, err := os.Readlink(filepath.Join("scripts", "Makefile"))
+	assert.NilError(t, err)
+	assert.Equal(t, target, "../Makefile")
+	assert.DeepEqual(t, c.changedFiles, []string{"scripts/Makefile"})
+
+	// Rendering a regular file over the symlink should replace it, not
+	// write through it
+	f, err = codegen.NewFile("scripts/Makefile", 0o644, time.Now())
+	assert.NilError(t, err)
+	f.SetContents("include ../Makefile\n")
+	assert.NilError(t, c.writeFile(f))
+
+	b, err := os.ReadFile("Makefile")
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "all:\n")
+	assert.Assert(t, !isSymlink(filepath.Join("scripts", "Makefile")))
+}
+
+func TestRemoveStaleFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("logo.png", []byte("png"), 0o644))
+	assert.NilError(t, os.WriteFile("kept.png", []byte("png"), 0o644))
+	assert.NilError(t, os.WriteFile("template", []byte("txt"), 0o644))
+	assert.NilError(t, os.Symlink("template", "link"))
+	assert.NilError(t, os.WriteFile("replaced-link", []byte("txt"), 0o644))
+
+	c := &Command{log: testLogger(t), lock: &stencil.Lockfile{
+		Files: []*stencil.LockfileFileEntry{
+			{Name: "logo.png", Type: stencil.LockfileFileTypeAsset},
+			{Name: "kept.png", Type: stencil.LockfileFileTypeAsset},
+			{Name: "template"},
+			{Name: "link", Type: stencil.LockfileFileTypeSymlink, Target: "template"},
+			{Name: "replaced-link", Type: stencil.LockfileFileTypeSymlink, Target: "template"},
+		},
+	}}
+
+	kept, err := codegen.NewFile("kept.png", 0o644, time.Now())
+	assert.NilError(t, err)
+	assert.NilError(t, c.removeStaleFiles([]*codegen.Template{{Files: []*codegen.File{kept}}}))
+
+	for _, name := range []string{"logo.png", "link"} {
+		_, err := os.Lstat(name)
+		assert.Assert(t, os.IsNotExist(err), "expected %q to be removed", name)
+	}
+	for _, name := range []string{"kept.png", "template", "replaced-link"} {
+		_, err := os.Lstat(name)
+		assert.NilError(t, err, "expected %q to be kept", name)
+	}
+	assert.DeepEqual(t, c.changedFiles, []string{"logo.png", "link"})
+}
+
+func TestWriteOrphanedBlocks(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	f, err := codegen.NewFile("main.go", 0o644, time.Now())
+	assert.NilError(t, err)
+	f.OrphanedBlocks = map[string]string{"removed": "func helper() {}"}
+
+	c := &Command{log: testLogger(t)}
+	assert.NilError(t, c.writeOrphanedBlocks(f))
+
+	b, err := os.ReadFile("main.go" + codegen.OrphanedBlocksSuffix)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(b), "## <<Stencil::Block(removed)>>\nfunc helper() {}\n"))
+}
+
+func TestWritePostRunReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	results := []*codegen.PostRunResult{{
+		Module:   "testing",
+		Name:     "build",
+		Command:  "make",
+		Status:   codegen.PostRunStatusFailed,
+		Duration: time.Second,
+		Error:    "exit status 2",
+	}}
+	assert.NilError(t, writePostRunReport(path, results))
+
+	b, err := os.ReadFile(path)
+	assert.NilError(t, err)
+
+	var got postRunReport
+	assert.NilError(t, json.Unmarshal(b, &got))
+	assert.DeepEqual(t, got.Commands, results)
+}
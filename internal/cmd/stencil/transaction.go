@@ -0,0 +1,263 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements writing files as a transaction that
+// can be rolled back, restoring the files it changed.
+
+package stencil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// transaction applies changes to files atomically, each file is replaced
+// with a rename, and records the original of every changed file so that
+// all of the changes can be rolled back. The zero value is ready to use.
+//
+// Files are replaced in place, not staged, so a rollback only happens if
+// the process is still running: if it's killed (e.g. with SIGKILL) the
+// files changed so far are kept and the backups are left behind. Only
+// the files changed through the transaction are restored, other changes,
+// e.g. by post-run commands, are never rolled back.
+type transaction struct {
+	// dir is the directory containing the backups of the original files,
+	// created on the first change
+	dir string
+
+	// changes are the files that were changed, in order
+	changes []*fileChange
+
+	// changed are the files that were changed, by path
+	changed map[string]*fileChange
+}
+
+// fileChange is the original state of a file changed by a transaction.
+type fileChange struct {
+	// path is the path to the file
+	path string
+
+	// existed is true if the file existed before it was changed
+	existed bool
+
+	// backup is the path to a copy of the original file, if it was a
+	// regular file
+	backup string
+
+	// mode is the mode of the original file
+	mode fs.FileMode
+
+	// target is the target of the original file, if it was a symlink
+	target string
+
+	// createdDirs are the parent directories that were created for the
+	// file, deepest first
+	createdDirs []string
+}
+
+// writeFile atomically replaces the file at path with data.
+func (t *transaction) writeFile(path string, data []byte, mode fs.FileMode) error {
+	c, err := t.record(path)
+	if err != nil {
+		return err
+	}
+	if err := c.createParents(); err != nil {
+		return err
+	}
+
+	return replaceFile(path, func(tmp string) error {
+		if err := os.WriteFile(tmp, data, mode); err != nil {
+			return err
+		}
+
+		// Apply the mode exactly, ignoring the umask, like an update of
+		// an existing file would
+		return os.Chmod(tmp, mode)
+	})
+}
+
+// symlink atomically replaces the file at path with a symlink to target.
+func (t *transaction) symlink(target, path string) error {
+	c, err := t.record(path)
+	if err != nil {
+		return err
+	}
+	if err := c.createParents(); err != nil {
+		return err
+	}
+
+	return replaceFile(path, func(tmp string) error {
+		return os.Symlink(target, tmp)
+	})
+}
+
+// remove removes the file at path, if it exists.
+func (t *transaction) remove(path string) error {
+	if _, err := os.Lstat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if _, err := t.record(path); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// record backs up the file at path the first time it's changed and
+// returns its original state.
+func (t *transaction) record(path string) (*fileChange, error) {
+	if c, ok := t.changed[path]; ok {
+		return c, nil
+	}
+
+	c := &fileChange{path: path}
+	if inf, err := os.Lstat(path); err == nil {
+		c.existed = true
+		c.mode = inf.Mode()
+
+		switch {
+		case inf.Mode()&fs.ModeSymlink != 0:
+			if c.target, err = os.Readlink(path); err != nil {
+				return nil, err
+			}
+		case inf.Mode().IsRegular():
+			if c.backup, err = t.backup(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if t.changed == nil {
+		t.changed = make(map[string]*fileChange)
+	}
+	t.changed[path] = c
+	t.changes = append(t.changes, c)
+	return c, nil
+}
+
+// createParents creates the parent directories of the file, remembering
+// the ones that didn't exist so that they can be removed again.
+func (c *fileChange) createParents() error {
+	for dir := filepath.Dir(c.path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil || dir == filepath.Dir(dir) {
+			break
+		}
+		c.createdDirs = append(c.createdDirs, dir)
+	}
+	return os.MkdirAll(filepath.Dir(c.path), 0o755)
+}
+
+// backup copies the file at path into the backup directory and returns
+// the path to the copy.
+func (t *transaction) backup(path string) (string, error) {
+	if t.dir == "" {
+		dir, err := os.MkdirTemp("", "stencil-backup-*")
+		if err != nil {
+			return "", err
+		}
+		t.dir = dir
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	backup := filepath.Join(t.dir, strconv.Itoa(len(t.changes)))
+	return backup, os.WriteFile(backup, b, 0o600)
+}
+
+// commit keeps all of the changes, removing the backups.
+func (t *transaction) commit() error {
+	t.changes = nil
+	t.changed = nil
+	if t.dir == "" {
+		return nil
+	}
+
+	err := os.RemoveAll(t.dir)
+	t.dir = ""
+	return err
+}
+
+// rollback restores every changed file to its original state, in reverse
+// order, and removes the backups.
+func (t *transaction) rollback() error {
+	var errs []error
+	for i := len(t.changes) - 1; i >= 0; i-- {
+		c := t.changes[i]
+		if err := c.restore(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(append(errs, t.commit())...)
+}
+
+// restore restores the file to its original state.
+func (c *fileChange) restore() error {
+	switch {
+	case c.target != "":
+		if err := replaceFile(c.path, func(tmp string) error { return os.Symlink(c.target, tmp) }); err != nil {
+			return err
+		}
+	case c.backup != "":
+		b, err := os.ReadFile(c.backup)
+		if err != nil {
+			return err
+		}
+		err = replaceFile(c.path, func(tmp string) error {
+			if err := os.WriteFile(tmp, b, c.mode.Perm()); err != nil {
+				return err
+			}
+			return os.Chmod(tmp, c.mode.Perm())
+		})
+		if err != nil {
+			return err
+		}
+	case !c.existed:
+		if err := os.Remove(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	// Only remove the directories that are empty again
+	for _, dir := range c.createdDirs {
+		if err := os.Remove(dir); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// replaceFile atomically replaces the file at path with the file created
+// by create at the temporary path it's given.
+func replaceFile(path string, create func(tmp string) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".stencil-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	// create may create a symlink, which requires the path to be free
+	if err := os.Remove(tmp); err != nil {
+		return err
+	}
+	if err := create(tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
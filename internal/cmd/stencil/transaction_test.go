@@ -0,0 +1,152 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for writing files as a
+// transaction.
+
+package stencil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/getoutreach/stencil/internal/codegen"
+	"gotest.tools/v3/assert"
+)
+
+// snapshot returns the contents, or symlink targets, of every file in the
+// current directory keyed by path.
+func snapshot(t *testing.T) map[string]string {
+	t.Helper()
+
+	files := map[string]string{}
+	assert.NilError(t, filepath.WalkDir(".", func(path string, d os.DirEntry, err error) error {
+		if err != nil || path == "." {
+			return err
+		}
+
+		inf, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			files[path+"/"] = ""
+		case inf.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			files[path] = "-> " + target
+		default:
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files[path] = inf.Mode().Perm().String() + " " + string(b)
+		}
+		return nil
+	}))
+	return files
+}
+
+func TestTransactionRollback(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("updated", []byte("old"), 0o644))
+	assert.NilError(t, os.WriteFile("removed", []byte("old"), 0o755))
+	assert.NilError(t, os.WriteFile("target", []byte("target"), 0o644))
+	assert.NilError(t, os.Symlink("target", "link"))
+	before := snapshot(t)
+
+	var tx transaction
+	assert.NilError(t, tx.writeFile("updated", []byte("new"), 0o600))
+	assert.NilError(t, tx.writeFile("updated", []byte("newer"), 0o600))
+	assert.NilError(t, tx.writeFile(filepath.Join("a", "b", "created"), []byte("new"), 0o644))
+	assert.NilError(t, tx.remove("removed"))
+	assert.NilError(t, tx.remove("missing"))
+	assert.NilError(t, tx.writeFile("link", []byte("replaced"), 0o644))
+	assert.NilError(t, tx.symlink("updated", "new-link"))
+
+	assert.DeepEqual(t, snapshot(t), map[string]string{
+		"a/":          "",
+		"a/b/":        "",
+		"a/b/created": "-rw-r--r-- new",
+		"link":        "-rw-r--r-- replaced",
+		"new-link":    "-> updated",
+		"target":      "-rw-r--r-- target",
+		"updated":     "-rw------- newer",
+	})
+
+	backups := tx.dir
+	assert.NilError(t, tx.rollback())
+	assert.DeepEqual(t, snapshot(t), before)
+	_, err := os.Stat(backups)
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestTransactionCommit(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("updated", []byte("old"), 0o644))
+
+	var tx transaction
+	assert.NilError(t, tx.writeFile("updated", []byte("new"), 0o644))
+	backups := tx.dir
+	assert.NilError(t, tx.commit())
+
+	_, err := os.Stat(backups)
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+	assert.NilError(t, tx.rollback())
+	assert.DeepEqual(t, snapshot(t), map[string]string{"updated": "-rw-r--r-- new"})
+}
+
+func TestTransactionRemoveMissingFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	var tx transaction
+	assert.NilError(t, tx.remove(filepath.Join("does", "not", "exist.txt")))
+	assert.NilError(t, tx.commit())
+
+	_, err := os.Stat("does")
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestWriteFilesRollsBackWhenInterrupted(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	f, err := codegen.NewFile("created", 0o644, time.Now())
+	assert.NilError(t, err)
+	f.SetContents("new")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := &Command{log: testLogger(t)}
+	err = c.rollbackFiles(c.writeFiles(ctx, nil, []*codegen.Template{{Files: []*codegen.File{f}}}))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.DeepEqual(t, snapshot(t), map[string]string{})
+}
+
+func TestWriteFilesRollsBackOnFailure(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("updated", []byte("old"), 0o644))
+
+	var files []*codegen.File
+	for _, name := range []string{"updated", "created", "blocked"} {
+		f, err := codegen.NewFile(name, 0o644, time.Now())
+		assert.NilError(t, err)
+		f.SetContents("new")
+		files = append(files, f)
+	}
+
+	assert.NilError(t, os.MkdirAll(filepath.Join("blocked", "dir"), 0o755))
+	before := snapshot(t)
+
+	// Writing "blocked" fails since it's a directory
+	c := &Command{log: testLogger(t)}
+	err := c.rollbackFiles(c.writeFiles(context.Background(), nil, []*codegen.Template{{Files: files}}))
+	assert.ErrorContains(t, err, `failed to write "blocked"`)
+	assert.DeepEqual(t, snapshot(t), before)
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements resolving references to environment
+// variables, files and secrets in the arguments of a service manifest.
+
+package codegen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/getoutreach/gobox/pkg/secrets"
+	"github.com/getoutreach/stencil/internal/dotnotation"
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/pkg/errors"
+	"go.yaml.in/yaml/v3"
+)
+
+// ArgumentRefKey is the key of a mapping, in the arguments of a service
+// manifest, that is replaced by the contents of the file it references.
+const ArgumentRefKey = configuration.ArgumentRefKey
+
+// ErrArgumentReference is returned when a reference in the arguments of
+// a service manifest can't be resolved.
+var ErrArgumentReference = errors.New("failed to resolve argument reference")
+
+// argumentRefPattern matches references to environment variables and
+// secrets in strings, see configuration.ArgumentReferencePattern.
+var argumentRefPattern = regexp.MustCompile(configuration.ArgumentReferencePattern)
+
+// argumentResolver resolves the references in arguments.
+type argumentResolver struct {
+	ctx context.Context
+
+	// lookupEnv returns the value of an environment variable
+	lookupEnv func(string) (string, bool)
+
+	// lookupSecret returns the value of a secret
+	lookupSecret func(context.Context, string) (string, error)
+
+	// files are the absolute paths of the files currently being
+	// resolved, used to detect cycles
+	files []string
+
+	// secrets are the values of the secrets that were resolved
+	secrets []string
+}
+
+// ResolveArguments replaces the references in the arguments of the
+// service manifest with the values they reference. Strings may reference
+// environment variables with ${env:NAME}, or ${env:NAME:-default}, and
+// secrets with ${secret:path}. Mappings with a $ref key, e.g.
+// {$ref: ./args.yaml#path.to.key}, are replaced by the contents of the
+// referenced YAML or JSON file, with the other keys of the mapping merged
+// on top. Relative paths are relative to dir, or to the file containing
+// the reference.
+//
+// The values of resolved secrets are returned by Secrets so they can be
+// redacted from logs.
+func (s *Stencil) ResolveArguments(ctx context.Context, dir string) error {
+	r := &argumentResolver{
+		ctx:       ctx,
+		lookupEnv: os.LookupEnv,
+		lookupSecret: func(ctx context.Context, path string) (string, error) {
+			v, err := secrets.Config(ctx, path)
+			return strings.TrimSpace(v), err
+		},
+	}
+
+	v, err := r.resolve(s.m.Arguments, dir, "")
+	if err != nil {
+		return err
+	}
+
+	args, ok := v.(map[string]any)
+	if !ok && v != nil {
+		return fmt.Errorf("%w: arguments must be a mapping, got %T", ErrArgumentReference, v)
+	}
+	s.m.Arguments = args
+	s.secrets = append(s.secrets, r.secrets...)
+	return nil
+}
+
+// Secrets returns the values of the secrets referenced by the arguments
+// of the service manifest, as resolved by ResolveArguments.
+func (s *Stencil) Secrets() []string {
+	return s.secrets
+}
+
+// ContainsArgumentReference reports whether v, an argument value, contains
+// a reference that is resolved by ResolveArguments.
+func ContainsArgumentReference(v any) bool {
+	switch t := v.(type) {
+	case map[string]any:
+		if _, ok := t[ArgumentRefKey]; ok {
+			return true
+		}
+		for _, e := range t {
+			if ContainsArgumentReference(e) {
+				return true
+			}
+		}
+	case []any:
+		return slices.ContainsFunc(t, ContainsArgumentReference)
+	case string:
+		return argumentRefPattern.MatchString(t)
+	}
+	return false
+}
+
+// resolve returns v with all of its references resolved. key is the
+// dot-separated path to v and is used for errors.
+func (r *argumentResolver) resolve(v any, dir, key string) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		return r.resolveMap(t, dir, key)
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			var err error
+			if out[i], err = r.resolve(e, dir, fmt.Sprintf("%s.%d", key, i)); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case string:
+		return r.resolveString(t, key)
+	default:
+		return v, nil
+	}
+}
+
+// resolveMap resolves the values of m, and replaces it with the contents
+// of the file referenced by its $ref key if it has one.
+func (r *argumentResolver) resolveMap(m map[string]any, dir, key string) (any, error) {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if k == ArgumentRefKey {
+			continue
+		}
+
+		var err error
+		if out[k], err = r.resolve(v, dir, strings.TrimPrefix(key+"."+k, ".")); err != nil {
+			return nil, err
+		}
+	}
+
+	ref, ok := m[ArgumentRefKey]
+	if !ok {
+		return out, nil
+	}
+
+	refStr, ok := ref.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s at %q must be a string", ErrArgumentReference, ArgumentRefKey, key)
+	}
+
+	base, err := r.resolveRef(refStr, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "argument %q", key)
+	}
+	if len(out) == 0 {
+		return base, nil
+	}
+
+	// The keys next to $ref override the values of the referenced file
+	return mergeValues(base, out, MergeStrategyOverride, key)
+}
+
+// resolveRef returns the resolved contents of the file referenced by ref,
+// a path optionally followed by #key to select a key, in dot notation, of
+// the file.
+func (r *argumentResolver) resolveRef(ref, dir string) (any, error) {
+	path, selector, _ := strings.Cut(ref, "#")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(r.files, path) {
+		return nil, fmt.Errorf("%w: %q references itself", ErrArgumentReference, path)
+	}
+	r.files = append(r.files, path)
+	defer func() { r.files = r.files[:len(r.files)-1] }()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrArgumentReference, err)
+	}
+
+	var v any
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse %q: %w", ErrArgumentReference, path, err)
+	}
+
+	if selector != "" {
+		if v, err = dotnotation.Get(v, selector); err != nil {
+			return nil, fmt.Errorf("%w: %q in %q: %w", ErrArgumentReference, selector, path, err)
+		}
+	}
+
+	return r.resolve(v, filepath.Dir(path), "")
+}
+
+// resolveString replaces the references to environment variables and
+// secrets in s with their values.
+func (r *argumentResolver) resolveString(s, key string) (string, error) {
+	var rerr error
+	out := argumentRefPattern.ReplaceAllStringFunc(s, func(match string) string {
+		m := argumentRefPattern.FindStringSubmatch(match)
+		source, name, hasDefault, def := m[1], m[2], m[3] != "", m[4]
+
+		switch source {
+		case "env":
+			if v, ok := r.lookupEnv(name); ok {
+				return v
+			}
+			if hasDefault {
+				return def
+			}
+			rerr = fmt.Errorf("%w: argument %q references environment variable %q, which is not set",
+				ErrArgumentReference, key, name)
+		case "secret":
+			v, err := r.lookupSecret(r.ctx, name)
+			if err != nil {
+				if hasDefault {
+					return def
+				}
+				rerr = fmt.Errorf("%w: argument %q references secret %q: %w", ErrArgumentReference, key, name, err)
+				return ""
+			}
+			if v != "" {
+				r.secrets = append(r.secrets, v)
+			}
+			return v
+		}
+		return match
+	})
+	return out, rerr
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for resolving references in
+// arguments
+
+package codegen
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+)
+
+// newTestArgumentResolver returns an argumentResolver with the provided
+// environment variables and secrets.
+func newTestArgumentResolver(env, secrets map[string]string) *argumentResolver {
+	return &argumentResolver{
+		ctx: context.Background(),
+		lookupEnv: func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		},
+		lookupSecret: func(_ context.Context, name string) (string, error) {
+			v, ok := secrets[name]
+			if !ok {
+				return "", errors.New("not found")
+			}
+			return v, nil
+		},
+	}
+}
+
+func TestArgumentResolverStrings(t *testing.T) {
+	r := newTestArgumentResolver(map[string]string{"HOST": "example.com"}, map[string]string{"db/password": "hunter2"})
+
+	got, err := r.resolve(map[string]any{
+		"url":      "https://${env:HOST}/${env:PATH_PREFIX:-api}",
+		"password": "${secret:db/password}",
+		"list":     []any{"${env:HOST}", 1},
+		"shell":    "${HOME}",
+	}, ".", "")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, map[string]any{
+		"url":      "https://example.com/api",
+		"password": "hunter2",
+		"list":     []any{"example.com", 1},
+		"shell":    "${HOME}",
+	})
+	assert.DeepEqual(t, r.secrets, []string{"hunter2"})
+
+	_, err = r.resolve(map[string]any{"a": map[string]any{"b": "${env:MISSING}"}}, ".", "")
+	assert.ErrorIs(t, err, ErrArgumentReference)
+	assert.ErrorContains(t, err, `argument "a.b" references environment variable "MISSING"`)
+
+	_, err = r.resolve("${secret:missing}", ".", "a")
+	assert.ErrorIs(t, err, ErrArgumentReference)
+}
+
+func TestArgumentResolverRefs(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "shared", "envs"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "shared", "args.yaml"), []byte(`team: platform
+env:
+  $ref: ./envs/prod.json#settings
+`), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "shared", "envs", "prod.json"),
+		[]byte(`{"settings": {"replicas": 3, "region": "${env:REGION}"}}`), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "cycle.yaml"), []byte("$ref: ./cycle.yaml\n"), 0o644))
+
+	r := newTestArgumentResolver(map[string]string{"REGION": "us-east-1"}, nil)
+	got, err := r.resolve(map[string]any{
+		"$ref": "./shared/args.yaml",
+		"team": "devx",
+		"env":  map[string]any{"replicas": 5},
+	}, dir, "")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, map[string]any{
+		"team": "devx",
+		"env":  map[string]any{"replicas": 5, "region": "us-east-1"},
+	})
+
+	_, err = r.resolve(map[string]any{"$ref": "./cycle.yaml"}, dir, "")
+	assert.ErrorIs(t, err, ErrArgumentReference)
+	assert.ErrorContains(t, err, "references itself")
+
+	_, err = r.resolve(map[string]any{"$ref": "./missing.yaml"}, dir, "")
+	assert.ErrorIs(t, err, ErrArgumentReference)
+}
+
+func TestResolveArguments(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	t.Setenv("STENCIL_TEST_TEAM", "devx")
+
+	m := &configuration.ServiceManifest{Name: "test", Arguments: map[string]any{"team": "${env:STENCIL_TEST_TEAM}"}}
+	st := NewStencil(m, nil, log)
+	assert.NilError(t, st.ResolveArguments(context.Background(), "."))
+	assert.DeepEqual(t, m.Arguments, map[string]any{"team": "devx"})
+	assert.Equal(t, len(st.Secrets()), 0)
+}
+
+func TestContainsArgumentReference(t *testing.T) {
+	assert.Assert(t, ContainsArgumentReference(map[string]any{"a": []any{"${env:FOO}"}}))
+	assert.Assert(t, ContainsArgumentReference(map[string]any{"a": map[string]any{"$ref": "./a.yaml"}}))
+	assert.Assert(t, !ContainsArgumentReference(map[string]any{"a": "${FOO}", "b": 1}))
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains helpers for finding and collecting the
+// required arguments of modules that aren't set in the service manifest.
+
+package codegen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getoutreach/stencil/internal/dotnotation"
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/pkg/errors"
+	"go.yaml.in/yaml/v3"
+)
+
+// MissingArgument is a required argument of a module that isn't set in
+// the service manifest and has no default.
+type MissingArgument struct {
+	// Name is the name of the argument, in dot notation.
+	Name string
+
+	// Module is the name of the module that declared the argument.
+	Module string
+
+	// Argument is the declaration of the argument from the module's
+	// manifest.
+	Argument configuration.Argument
+}
+
+// MissingRequiredArguments returns the required arguments, across all of
+// the modules, that aren't set in the service manifest, sorted by name.
+// Arguments that are declared by more than one module are only returned
+// once, and arguments using "from" are skipped since the module they
+// reference declares them.
+func (s *Stencil) MissingRequiredArguments(ctx context.Context) ([]*MissingArgument, error) {
+	args := make(map[any]any, len(s.m.Arguments))
+	for k, v := range s.m.Arguments {
+		args[k] = v
+	}
+
+	seen := make(map[string]bool)
+	missing := make([]*MissingArgument, 0)
+	for _, m := range s.modules {
+		mf, err := m.Manifest(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get manifest of module %q", m.Name)
+		}
+
+		for name, arg := range mf.Arguments {
+			if !arg.Required || arg.Default != nil || arg.From != "" || seen[name] {
+				continue
+			}
+
+			if _, err := dotnotation.Get(args, name); err == nil {
+				continue
+			}
+
+			seen[name] = true
+			missing = append(missing, &MissingArgument{Name: name, Module: m.Name, Argument: arg})
+		}
+	}
+
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Name < missing[j].Name })
+	return missing, nil
+}
+
+// Enum returns the values allowed by the schema of the argument as
+// strings, or nil if the schema doesn't declare an enum.
+func (a *MissingArgument) Enum() []string {
+	enum, ok := a.Argument.Schema["enum"].([]any)
+	if !ok {
+		return nil
+	}
+
+	values := make([]string, 0, len(enum))
+	for _, v := range enum {
+		values = append(values, fmt.Sprint(v))
+	}
+	return values
+}
+
+// Parse converts input, provided by a user, into a value of the type
+// declared by the schema of the argument and validates it against the
+// schema. Arrays and objects are parsed as YAML, e.g. "[a, b]".
+func (a *MissingArgument) Parse(input string) (any, error) {
+	var v any
+	var err error
+	switch a.typ() {
+	case "integer", "int":
+		v, err = strconv.Atoi(strings.TrimSpace(input))
+	case "number":
+		v, err = strconv.ParseFloat(strings.TrimSpace(input), 64)
+	case "boolean", "bool":
+		v, err = strconv.ParseBool(strings.TrimSpace(input))
+	case "array", "list", "object", "map":
+		err = yaml.Unmarshal([]byte(input), &v)
+	default:
+		v = input
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: expected a value of type %s", ErrArgInvalidType, a.typ())
+	}
+
+	if a.Argument.Schema == nil {
+		return v, nil
+	}
+
+	schema, err := compileArgSchema(a.Name, &a.Argument)
+	if err != nil {
+		return nil, err
+	}
+	if err := schema.Validate(v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrArgValidationFailed, err)
+	}
+	return v, nil
+}
+
+// typ returns the type of the argument from its schema, falling back to
+// the deprecated type field.
+func (a *MissingArgument) typ() string {
+	if typ, ok := a.Argument.Schema["type"].(string); ok {
+		return typ
+	}
+	return a.Argument.Type //nolint:staticcheck // Why: Compat
+}
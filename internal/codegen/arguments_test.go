@@ -0,0 +1,100 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for finding missing required
+// arguments
+
+package codegen
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/getoutreach/stencil/internal/modules"
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+)
+
+func TestMissingRequiredArguments(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	m1fs := createFakeModuleFSWithManifest(t, `name: m1
+arguments:
+  team:
+    required: true
+  nested.port:
+    required: true
+    schema:
+      type: integer
+  set:
+    required: true
+  withDefault:
+    required: true
+    default: a
+  optional:
+    schema:
+      type: string
+`)
+	m2fs := createFakeModuleFSWithManifest(t, `name: m2
+modules:
+  - name: m1
+arguments:
+  team:
+    from: m1
+  environment:
+    required: true
+    schema:
+      enum: [dev, prod]
+`)
+
+	st := NewStencil(&configuration.ServiceManifest{Name: "test", Arguments: map[string]any{"set": "yes"}},
+		[]*modules.Module{modules.NewWithFS(ctx, "m1", m1fs), modules.NewWithFS(ctx, "m2", m2fs)}, log)
+
+	missing, err := st.MissingRequiredArguments(ctx)
+	assert.NilError(t, err)
+
+	names := make([]string, 0, len(missing))
+	for _, m := range missing {
+		names = append(names, m.Module+":"+m.Name)
+	}
+	assert.DeepEqual(t, names, []string{"m2:environment", "m1:nested.port", "m1:team"})
+	assert.DeepEqual(t, missing[0].Enum(), []string{"dev", "prod"})
+	assert.Assert(t, missing[1].Enum() == nil)
+}
+
+func TestMissingArgumentParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  map[string]any
+		input   string
+		want    any
+		wantErr error
+	}{
+		{name: "string", schema: map[string]any{"type": "string"}, input: "hello", want: "hello"},
+		{name: "integer", schema: map[string]any{"type": "integer"}, input: " 42 ", want: 42},
+		{name: "boolean", schema: map[string]any{"type": "boolean"}, input: "true", want: true},
+		{name: "array", schema: map[string]any{"type": "array"}, input: "[a, b]", want: []any{"a", "b"}},
+		{name: "invalid integer", schema: map[string]any{"type": "integer"}, input: "abc", wantErr: ErrArgInvalidType},
+		{
+			name:    "fails schema validation",
+			schema:  map[string]any{"type": "string", "enum": []any{"dev", "prod"}},
+			input:   "staging",
+			wantErr: ErrArgValidationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arg := &MissingArgument{Name: "arg", Argument: configuration.Argument{Schema: tt.schema}}
+			got, err := arg.Parse(tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
@@ -7,7 +7,9 @@ package codegen
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -45,9 +47,64 @@ var BlockPattern = regexp.MustCompile(`^\s*(///|###|<!---)\s*([a-zA-Z ]+)\(([a-z
 // internal/lint/templates.classify depends on these indices.
 var V2BlockPattern = regexp.MustCompile(`^\s*(//|##|--|<!--)\s{0,1}<<(/?)Stencil::([a-zA-Z ]+)(\([a-zA-Z0-9 _]+\))?>>`)
 
-// parseBlocks reads the blocks from an existing file.
-func parseBlocks(filePath string) (map[string]string, error) {
-	blocks := make(map[string]string)
+// DefaultCommentStyles maps file extensions, or file names, to an additional
+// comment prefix that block tags can be written after in those files. The
+// prefixes recognized by V2BlockPattern are always recognized. Modules can
+// extend this via the commentStyles key of their manifest, and templates can
+// override it with file.SetCommentStyle.
+//
+//nolint:gochecknoglobals // Why: static lookup table of comment styles.
+var DefaultCommentStyles = map[string]string{
+	".bat":  "REM",
+	".clj":  ";;",
+	".cljc": ";;",
+	".cljs": ";;",
+	".cmd":  "REM",
+	".css":  "/*",
+	".edn":  ";;",
+	".el":   ";;",
+	".erl":  "%%",
+	".hrl":  "%%",
+	".lisp": ";;",
+	".lua":  "--[[",
+	".scm":  ";;",
+}
+
+// CommentStyleFor returns the additional comment prefix block tags can be
+// written after in the file at path. The file name, and then its extension,
+// are looked up in styles before DefaultCommentStyles. Extensions in styles
+// may be written with or without the leading dot. An empty string is
+// returned if there is none.
+func CommentStyleFor(path string, styles map[string]string) string {
+	base := filepath.Base(path)
+	ext := strings.ToLower(filepath.Ext(base))
+	for _, key := range []string{base, ext, strings.TrimPrefix(ext, ".")} {
+		if prefix, ok := styles[key]; ok && key != "" {
+			return prefix
+		}
+	}
+	return DefaultCommentStyles[ext]
+}
+
+// NormalizeCommentPrefix rewrites line so that a block tag written after
+// prefix is written after "//" instead, allowing it to be matched by
+// V2BlockPattern. Lines that don't start with prefix are returned as-is.
+func NormalizeCommentPrefix(line, prefix string) string {
+	if prefix == "" {
+		return line
+	}
+
+	trimmed := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(trimmed, prefix) {
+		return line
+	}
+	return line[:len(line)-len(trimmed)] + "//" + trimmed[len(prefix):]
+}
+
+// parseBlocks reads the blocks from an existing file. Block tags are
+// recognized after the comment prefixes of V2BlockPattern, and after
+// commentPrefix if it is set.
+func parseBlocks(filePath, commentPrefix string) (map[string]string, error) {
 	f, err := os.Open(filePath)
 	if errors.Is(err, os.ErrNotExist) {
 		return make(map[string]string), nil
@@ -56,8 +113,72 @@ func parseBlocks(filePath string) (map[string]string, error) {
 	}
 	defer f.Close()
 
-	var curBlockName string
-	scanner := bufio.NewScanner(f)
+	return parseBlocksFrom(f, filePath, commentPrefix)
+}
+
+// parseBlocksFrom reads the blocks from r, the contents of the file at
+// filePath. See parseBlocks.
+func parseBlocksFrom(r io.Reader, filePath, commentPrefix string) (map[string]string, error) {
+	found, err := ListBlocks(r, filePath, commentPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make(map[string]string)
+	for _, b := range found {
+		// blocks without any lines aren't tracked, and blocks that appear
+		// more than once have their contents joined together.
+		if b.Lines() == 0 {
+			continue
+		}
+
+		if curVal, ok := blocks[b.Name]; ok {
+			blocks[b.Name] = curVal + "\n" + b.Contents
+		} else {
+			blocks[b.Name] = b.Contents
+		}
+	}
+	return blocks, nil
+}
+
+// Block is a block found in a file.
+type Block struct {
+	// Name is the name of the block.
+	Name string `json:"name"`
+
+	// StartLine is the line number of the opening tag of the block.
+	StartLine int `json:"startLine"`
+
+	// EndLine is the line number of the closing tag of the block.
+	EndLine int `json:"endLine"`
+
+	// Contents are the lines between the opening and closing tags of the
+	// block, joined by newlines.
+	Contents string `json:"contents"`
+}
+
+// Lines returns the number of lines between the opening and closing tags
+// of the block.
+func (b *Block) Lines() int {
+	return b.EndLine - b.StartLine - 1
+}
+
+// IsEmpty reports whether the block only contains whitespace.
+func (b *Block) IsEmpty() bool {
+	return strings.TrimSpace(b.Contents) == ""
+}
+
+// ListBlocks returns the blocks in r, the contents of the file at filePath,
+// in the order they appear in. Block tags are recognized after the comment
+// prefixes of V2BlockPattern, and after commentPrefix if it is set.
+//
+//nolint:funlen // Why: mirrors the block grammar
+func ListBlocks(r io.Reader, filePath, commentPrefix string) ([]*Block, error) {
+	blocks := make([]*Block, 0)
+
+	var cur *Block
+	var lines []string
+	scanner := bufio.NewScanner(r)
 	for i := 0; scanner.Scan(); i++ {
 		line := scanner.Text()
 		matches := BlockPattern.FindStringSubmatch(line)
@@ -67,7 +188,7 @@ func parseBlocks(filePath string) (map[string]string, error) {
 			// 2: / if end of block
 			// 3: block name
 			// 4: block args, if present
-			v2Matches := V2BlockPattern.FindStringSubmatch(line)
+			v2Matches := V2BlockPattern.FindStringSubmatch(NormalizeCommentPrefix(line, commentPrefix))
 			if len(v2Matches) == 5 {
 				cmd := v2Matches[3]
 				if v2Matches[2] == "/" {
@@ -82,6 +203,10 @@ func parseBlocks(filePath string) (map[string]string, error) {
 						return nil, fmt.Errorf("%w: line %d: %s", ErrBlockParse, i+1, MsgClosingTagArgs)
 					}
 
+					curBlockName := ""
+					if cur != nil {
+						curBlockName = cur.Name
+					}
 					v2Matches[4] = fmt.Sprintf("(%s)", curBlockName)
 				} else if cmd == EndStatement {
 					// If it's not a closing tag, but the command is EndBlock,
@@ -112,25 +237,29 @@ func parseBlocks(filePath string) (map[string]string, error) {
 			switch cmd {
 			case StartStatement:
 				blockName := matches[3]
-				if curBlockName != "" {
+				if cur != nil {
 					return nil, fmt.Errorf("%w: invalid Block when already inside of a block, at %s:%d", ErrBlockParse, filePath, i+1)
 				}
-				curBlockName = blockName
+				cur = &Block{Name: blockName, StartLine: i + 1}
+				lines = nil
 			case EndStatement:
 				blockName := matches[3]
 
-				if curBlockName == "" {
+				if cur == nil {
 					return nil, fmt.Errorf("%w: invalid EndBlock when not inside of a block, at %s:%d", ErrBlockParse, filePath, i+1)
 				}
 
-				if blockName != curBlockName {
+				if blockName != cur.Name {
 					return nil, fmt.Errorf(
 						"%w: invalid EndBlock, found EndBlock with name %q while inside of block with name %q, at %s:%d",
-						ErrBlockParse, blockName, curBlockName, filePath, i+1,
+						ErrBlockParse, blockName, cur.Name, filePath, i+1,
 					)
 				}
 
-				curBlockName = ""
+				cur.EndLine = i + 1
+				cur.Contents = strings.Join(lines, "\n")
+				blocks = append(blocks, cur)
+				cur = nil
 			default:
 				isCommand = false
 			}
@@ -138,24 +267,19 @@ func parseBlocks(filePath string) (map[string]string, error) {
 
 		// we skip lines that had a recognized command in them, or that
 		// aren't in a block
-		if isCommand || curBlockName == "" {
+		if isCommand || cur == nil {
 			continue
 		}
 
 		// add the line we processed to the current block we're in
-		// and account for having an existing curVal or not. If we
-		// don't then we assign curVal to start with the line we
-		// just found.
-		curVal, ok := blocks[curBlockName]
-		if ok {
-			blocks[curBlockName] = curVal + "\n" + line
-		} else {
-			blocks[curBlockName] = line
-		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", filePath)
 	}
 
-	if curBlockName != "" {
-		return nil, fmt.Errorf("%w: found dangling Block (%s) in %s", ErrBlockParse, curBlockName, filePath)
+	if cur != nil {
+		return nil, fmt.Errorf("%w: found dangling Block (%s) in %s", ErrBlockParse, cur.Name, filePath)
 	}
 
 	return blocks, nil
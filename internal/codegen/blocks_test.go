@@ -5,41 +5,42 @@
 package codegen
 
 import (
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
 )
 
 func TestParseBlocks(t *testing.T) {
-	blocks, err := parseBlocks("testdata/blocks-test.txt")
+	blocks, err := parseBlocks("testdata/blocks-test.txt", "")
 	assert.NilError(t, err, "expected parseBlocks() not to fail")
 	assert.Equal(t, blocks["helloWorld"], "Hello, world!", "expected parseBlocks() to parse basic block")
 	assert.Equal(t, blocks["e2e"], "content", "expected parseBlocks() to parse e2e block")
 }
 
 func TestDanglingBlock(t *testing.T) {
-	_, err := parseBlocks("testdata/danglingblock-test.txt")
+	_, err := parseBlocks("testdata/danglingblock-test.txt", "")
 	assert.Error(t, err,
 		"failed to parse blocks: found dangling Block (dangles) in testdata/danglingblock-test.txt",
 		"expected parseBlocks() to fail")
 }
 
 func TestDanglingEndBlock(t *testing.T) {
-	_, err := parseBlocks("testdata/danglingendblock-test.txt")
+	_, err := parseBlocks("testdata/danglingendblock-test.txt", "")
 	assert.Error(t, err,
 		"failed to parse blocks: invalid EndBlock when not inside of a block, at testdata/danglingendblock-test.txt:8",
 		"expected parseBlocks() to fail")
 }
 
 func TestBlockInsideBlock(t *testing.T) {
-	_, err := parseBlocks("testdata/blockinsideblock-test.txt")
+	_, err := parseBlocks("testdata/blockinsideblock-test.txt", "")
 	assert.Error(t, err,
 		"failed to parse blocks: invalid Block when already inside of a block, at testdata/blockinsideblock-test.txt:3",
 		"expected parseBlocks() to fail")
 }
 
 func TestWrongEndBlock(t *testing.T) {
-	_, err := parseBlocks("testdata/wrongendblock-test.txt")
+	_, err := parseBlocks("testdata/wrongendblock-test.txt", "")
 	assert.Error(t, err,
 		"failed to parse blocks: invalid EndBlock, found EndBlock with name \"wrongend\" while inside of block with name \"helloWorld\", at testdata/wrongendblock-test.txt:3", //nolint:lll
 		"expected parseBlocks() to fail")
@@ -50,21 +51,62 @@ func TestWrongEndBlock(t *testing.T) {
 // shape internal/lint/templates.FixBytes produces for a mismatched pair,
 // which it deliberately leaves the EndBlock unmigrated to keep triggering.
 func TestWrongEndBlockAfterV2Start(t *testing.T) {
-	_, err := parseBlocks("testdata/wrongendblock-v2start-test.txt")
+	_, err := parseBlocks("testdata/wrongendblock-v2start-test.txt", "")
 	assert.Error(t, err,
 		"failed to parse blocks: invalid EndBlock, found EndBlock with name \"wrongend\" while inside of block with name \"helloWorld\", at testdata/wrongendblock-v2start-test.txt:3", //nolint:lll
 		"expected parseBlocks() to fail")
 }
 
 func TestParseV2Blocks(t *testing.T) {
-	blocks, err := parseBlocks("testdata/v2blocks-test.txt")
+	blocks, err := parseBlocks("testdata/v2blocks-test.txt", "")
 	assert.NilError(t, err, "expected parseBlocks() not to fail")
 	assert.Equal(t, blocks["helloWorld"], "Hello, world!", "expected parseBlocks() to parse basic block")
 }
 
 func TestV2BlocksErrors(t *testing.T) {
-	_, err := parseBlocks("testdata/v2blocks-invalid.txt")
+	_, err := parseBlocks("testdata/v2blocks-invalid.txt", "")
 	if err == nil {
 		t.Fatal("expected parseBlocks() to fail")
 	}
 }
+
+func TestParseBlocksCommentStyle(t *testing.T) {
+	blocks, err := parseBlocks("testdata/commentstyle-test.clj", CommentStyleFor("testdata/commentstyle-test.clj", nil))
+	assert.NilError(t, err, "expected parseBlocks() not to fail")
+	assert.DeepEqual(t, blocks, map[string]string{"deps": "(require '[clojure.string :as str])"})
+
+	blocks, err = parseBlocks("testdata/commentstyle-test.clj", "")
+	assert.NilError(t, err, "expected parseBlocks() not to fail")
+	assert.Equal(t, len(blocks), 0, "expected ;; blocks to be ignored without a comment style")
+}
+
+func TestCommentStyleFor(t *testing.T) {
+	styles := map[string]string{"rkt": ";;", ".css": "//", "Jenkinsfile": "##"}
+	assert.Equal(t, CommentStyleFor("src/app.rkt", styles), ";;")
+	assert.Equal(t, CommentStyleFor("web/app.css", styles), "//", "expected module styles to override defaults")
+	assert.Equal(t, CommentStyleFor("web/app.css", nil), "/*")
+	assert.Equal(t, CommentStyleFor("ci/Jenkinsfile", styles), "##")
+	assert.Equal(t, CommentStyleFor("main.go", styles), "")
+}
+
+func TestNormalizeCommentPrefix(t *testing.T) {
+	assert.Equal(t, NormalizeCommentPrefix("  REM <<Stencil::Block(a)>>", "REM"), "  // <<Stencil::Block(a)>>")
+	assert.Equal(t, NormalizeCommentPrefix("echo REM", "REM"), "echo REM")
+	assert.Equal(t, NormalizeCommentPrefix(";; text", ""), ";; text")
+}
+
+func TestListBlocks(t *testing.T) {
+	r := strings.NewReader("package main\n\n// <<Stencil::Block(imports)>>\nimport \"fmt\"\n// <</Stencil::Block>>\n" +
+		"\n// <<Stencil::Block(main)>>\n// <</Stencil::Block>>\n")
+
+	blocks, err := ListBlocks(r, "main.go", "")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, blocks, []*Block{
+		{Name: "imports", StartLine: 3, EndLine: 5, Contents: "import \"fmt\""},
+		{Name: "main", StartLine: 7, EndLine: 8},
+	})
+	assert.Equal(t, blocks[0].Lines(), 1)
+	assert.Equal(t, blocks[0].IsEmpty(), false)
+	assert.Equal(t, blocks[1].Lines(), 0)
+	assert.Equal(t, blocks[1].IsEmpty(), true)
+}
@@ -0,0 +1,280 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains the debugger, which traces the render of
+// a single template for the debug command.
+
+package codegen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrTemplateNotFound is returned when the template to debug isn't
+// provided by any of the modules.
+var ErrTemplateNotFound = errors.New("template not found")
+
+// ErrAmbiguousTemplate is returned when the template to debug is provided
+// by more than one module.
+var ErrAmbiguousTemplate = errors.New("template is provided by more than one module, use its import path")
+
+// ArgSource is where the value of an argument came from.
+type ArgSource string
+
+// This block contains all of the ArgSource values.
+const (
+	// ArgSourceServiceManifest is a value set in the service manifest.
+	ArgSourceServiceManifest ArgSource = "service.yaml"
+
+	// ArgSourceDefault is the default declared by the module's manifest.
+	ArgSourceDefault ArgSource = "default"
+
+	// ArgSourceZero is the zero value of the argument's type, used when
+	// neither the service manifest nor the module's manifest set one.
+	ArgSourceZero ArgSource = "zero"
+)
+
+// Debugger traces the render of a single template, recording the values
+// it's rendered with, what it reads through the stencil functions and
+// the files it creates. Templates are still rendered in the first pass,
+// to populate module hooks and globals, but only the traced template is
+// rendered in the second pass.
+type Debugger struct {
+	// Template is the template to trace, either its import path (e.g.
+	// github.com/getoutreach/stencil-base/README.md.tpl) or its path if
+	// only one module provides it
+	Template string
+
+	// OnBreak, if set, is called when the traced template calls
+	// stencil.Break. Rendering is stopped with the error it returns.
+	OnBreak func(*Breakpoint) error
+
+	// Trace is what has been recorded so far
+	Trace Trace
+
+	// tpl is the template being traced, once resolved
+	tpl *Template
+}
+
+// Trace is what was recorded while rendering a template.
+type Trace struct {
+	// Template is the import path of the template
+	Template string `json:"template"`
+
+	// Values are the values the template was rendered with
+	Values *Values `json:"values"`
+
+	// Args are the calls to stencil.Arg, in order
+	Args []ArgRead `json:"args"`
+
+	// ModuleHooks are the calls to stencil.GetModuleHook, in order
+	ModuleHooks []ModuleHookRead `json:"moduleHooks"`
+
+	// Globals are the calls to stencil.GetGlobal, in order
+	Globals []GlobalRead `json:"globals"`
+
+	// Breaks are the calls to stencil.Break, in order
+	Breaks []Break `json:"breaks"`
+
+	// Files are the files the template created
+	Files []TracedFile `json:"files"`
+}
+
+// ArgRead is a call to stencil.Arg.
+type ArgRead struct {
+	// Name is the argument that was read
+	Name string `json:"name"`
+
+	// Value is the value that was returned
+	Value any `json:"value"`
+
+	// Source is where the value came from, empty if reading it failed
+	Source ArgSource `json:"source,omitempty"`
+
+	// From is the module the argument was declared by through "from", if
+	// it was declared by another module
+	From string `json:"from,omitempty"`
+
+	// Error is the error that was returned, if any
+	Error string `json:"error,omitempty"`
+}
+
+// ModuleHookRead is a call to stencil.GetModuleHook.
+type ModuleHookRead struct {
+	// Name is the module hook, prefixed with the module that owns it
+	Name string `json:"name"`
+
+	// Values are the values that were returned
+	Values []any `json:"values"`
+}
+
+// GlobalRead is a call to stencil.GetGlobal.
+type GlobalRead struct {
+	// Name is the global, prefixed with the module that owns it
+	Name string `json:"name"`
+
+	// Value is the value that was returned
+	Value any `json:"value"`
+
+	// SetBy is the template that set the global, empty if it wasn't set
+	SetBy string `json:"setBy,omitempty"`
+}
+
+// Break is a call to stencil.Break.
+type Break struct {
+	// File is the file that was being rendered
+	File string `json:"file"`
+
+	// Data is the data passed to stencil.Break
+	Data []any `json:"data,omitempty"`
+}
+
+// TracedFile is a file created by a template.
+type TracedFile struct {
+	Name          string `json:"name"`
+	Mode          string `json:"mode"`
+	Deleted       bool   `json:"deleted,omitempty"`
+	Skipped       bool   `json:"skipped,omitempty"`
+	SkippedReason string `json:"skippedReason,omitempty"`
+	SymlinkTarget string `json:"symlinkTarget,omitempty"`
+	Contents      string `json:"contents,omitempty"`
+}
+
+// Breakpoint is passed to Debugger.OnBreak when the traced template
+// calls stencil.Break.
+type Breakpoint struct {
+	Break
+
+	// Trace is what has been recorded up to the breakpoint
+	Trace *Trace
+}
+
+// SetDebugger makes Render trace the render of the template d.Template
+// with d, instead of rendering every template.
+func (s *Stencil) SetDebugger(d *Debugger) {
+	s.debugger = d
+}
+
+// resolve sets the template to trace from tpls.
+func (d *Debugger) resolve(tpls []*Template) error {
+	var matches []*Template
+	for _, t := range tpls {
+		if t.ImportPath() == d.Template {
+			matches = []*Template{t}
+			break
+		}
+		if t.Path == d.Template {
+			matches = append(matches, t)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("%w: %q", ErrTemplateNotFound, d.Template)
+	case 1:
+		d.tpl = matches[0]
+		d.Trace.Template = d.tpl.ImportPath()
+		return nil
+	default:
+		paths := make([]string, 0, len(matches))
+		for _, t := range matches {
+			paths = append(paths, t.ImportPath())
+		}
+		slices.Sort(paths)
+		return fmt.Errorf("%w: %s", ErrAmbiguousTemplate, strings.Join(paths, ", "))
+	}
+}
+
+// record records the values t was rendered with and the files it
+// created, after it was rendered.
+func (d *Debugger) record(t *Template) {
+	d.Trace.Values = t.args
+	for _, f := range t.Files {
+		tf := TracedFile{
+			Name:          f.Name(),
+			Mode:          f.Mode().String(),
+			Deleted:       f.Deleted,
+			Skipped:       f.Skipped,
+			SkippedReason: f.SkippedReason,
+			SymlinkTarget: f.SymlinkTarget(),
+		}
+		if !t.IsAsset() {
+			tf.Contents = f.String()
+		}
+		d.Trace.Files = append(d.Trace.Files, tf)
+	}
+}
+
+// trace returns the trace to record the calls of the current template
+// to, or nil if it isn't being traced. Only the second pass is traced,
+// the first one doesn't have the data of module hooks and globals.
+func (s *TplStencil) trace() *Trace {
+	d := s.s.debugger
+	if d == nil || s.s.isFirstPass || d.tpl == nil || s.t != d.tpl {
+		return nil
+	}
+	return &d.Trace
+}
+
+// Break is a breakpoint for `stencil debug`. When the template is being
+// debugged, the data passed to it is recorded and, in interactive mode,
+// rendering is paused to inspect the values and what the template has
+// read so far. Otherwise, it does nothing.
+//
+//	{{- stencil.Break }}
+//	{{- stencil.Break $myVariable }}
+func (s *TplStencil) Break(data ...any) (string, error) {
+	tr := s.trace()
+	if tr == nil {
+		return "", nil
+	}
+
+	b := Break{Data: traceValues(data)}
+	if n := len(s.t.Files); n > 0 {
+		b.File = s.t.Files[n-1].Name()
+	}
+	tr.Breaks = append(tr.Breaks, b)
+
+	if onBreak := s.s.debugger.OnBreak; onBreak != nil {
+		return "", onBreak(&Breakpoint{Break: b, Trace: tr})
+	}
+	return "", nil
+}
+
+// traceValue returns v with every map[any]any in it converted to a
+// map[string]any, so that it can be encoded as JSON.
+func traceValue(v any) any {
+	switch t := v.(type) {
+	case map[any]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			out[fmt.Sprint(k)] = traceValue(e)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			out[k] = traceValue(e)
+		}
+		return out
+	case []any:
+		return traceValues(t)
+	default:
+		return v
+	}
+}
+
+// traceValues returns vs with traceValue applied to each value.
+func traceValues(vs []any) []any {
+	if vs == nil {
+		return nil
+	}
+	out := make([]any, len(vs))
+	for i, v := range vs {
+		out[i] = traceValue(v)
+	}
+	return out
+}
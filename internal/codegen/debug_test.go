@@ -0,0 +1,149 @@
+package codegen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getoutreach/stencil/internal/modules"
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+)
+
+// newDebugTestModules returns the modules used by the debugger tests: base,
+// which declares the org argument and writes to a module hook of app, and
+// app, which reads them.
+func newDebugTestModules(t *testing.T) []*modules.Module {
+	ctx := context.Background()
+
+	base := createFakeModuleFSWithManifest(t, `name: base
+arguments:
+  org:
+    schema:
+      type: string
+    default: acme
+`)
+	assert.NilError(t, util.WriteFile(base, "templates/base.tpl",
+		[]byte(`{{ file.Delete }}{{ stencil.AddToModuleHook "app" "extra" (list "x") }}`), 0o644))
+
+	app := createFakeModuleFSWithManifest(t, `name: app
+modules:
+  - name: base
+arguments:
+  name:
+    schema:
+      type: string
+  org:
+    from: base
+  count:
+    schema:
+      type: integer
+  greeting:
+    schema:
+      type: string
+    default: hi
+`)
+	assert.NilError(t, util.WriteFile(app, "templates/helpers.tpl",
+		[]byte(`{{ file.Delete }}{{ stencil.SetGlobal "g" "v" }}`), 0o644))
+	assert.NilError(t, util.WriteFile(app, "templates/README.md.tpl", []byte(
+		`{{ stencil.Arg "name" }} {{ stencil.Arg "org" }} {{ stencil.Arg "count" }} {{ stencil.Arg "greeting" }}`+
+			`{{ stencil.Break "here" }} {{ stencil.GetModuleHook "extra" }} {{ stencil.GetGlobal "g" }}`), 0o644))
+
+	return []*modules.Module{modules.NewWithFS(ctx, "base", base), modules.NewWithFS(ctx, "app", app)}
+}
+
+func TestDebuggerTrace(t *testing.T) {
+	ctx := context.Background()
+
+	st := NewStencil(&configuration.ServiceManifest{
+		Name:      "test",
+		Arguments: map[string]any{"name": "svc"},
+	}, newDebugTestModules(t), logrus.New())
+
+	var breakpoints []Breakpoint
+	d := &Debugger{
+		Template: "README.md.tpl",
+		OnBreak: func(b *Breakpoint) error {
+			// Copy the trace, it's still being recorded to
+			tr := *b.Trace
+			breakpoints = append(breakpoints, Breakpoint{Break: b.Break, Trace: &tr})
+			return nil
+		},
+	}
+	st.SetDebugger(d)
+
+	tpls, err := st.Render(ctx, logrus.New())
+	assert.NilError(t, err)
+	assert.Equal(t, len(tpls), 1, "expected only the debugged template to be rendered")
+
+	assert.Equal(t, d.Trace.Template, "app/README.md.tpl")
+	assert.Equal(t, d.Trace.Values.Template.Name, "README.md.tpl")
+	assert.DeepEqual(t, d.Trace.Args, []ArgRead{
+		{Name: "name", Value: "svc", Source: ArgSourceServiceManifest},
+		{Name: "org", Value: "acme", Source: ArgSourceDefault, From: "base"},
+		{Name: "count", Value: 0, Source: ArgSourceZero},
+		{Name: "greeting", Value: "hi", Source: ArgSourceDefault},
+	})
+	assert.DeepEqual(t, d.Trace.ModuleHooks, []ModuleHookRead{{Name: "app/extra", Values: []any{"x"}}})
+	assert.DeepEqual(t, d.Trace.Globals, []GlobalRead{{Name: "app/g", Value: "v", SetBy: "helpers.tpl"}})
+	assert.DeepEqual(t, d.Trace.Breaks, []Break{{File: "README.md", Data: []any{"here"}}})
+	assert.DeepEqual(t, d.Trace.Files, []TracedFile{{
+		Name:     "README.md",
+		Mode:     "-rw-r--r--",
+		Contents: "svc acme 0 hi [x] v",
+	}})
+
+	assert.Equal(t, len(breakpoints), 1)
+	assert.Equal(t, len(breakpoints[0].Trace.Args), 4, "expected the arguments read before the breakpoint")
+	assert.Equal(t, len(breakpoints[0].Trace.ModuleHooks), 0, "expected no module hooks read before the breakpoint")
+}
+
+func TestDebuggerTraceRenderError(t *testing.T) {
+	ctx := context.Background()
+
+	fs := createFakeModuleFSWithManifest(t, "name: app\narguments:\n  name:\n    schema:\n      type: string\n")
+	assert.NilError(t, util.WriteFile(fs, "templates/README.md.tpl",
+		[]byte(`{{ stencil.Arg "name" }}{{ .Missing }}`), 0o644))
+
+	st := NewStencil(&configuration.ServiceManifest{Name: "test"}, []*modules.Module{
+		modules.NewWithFS(ctx, "app", fs),
+	}, logrus.New())
+	d := &Debugger{Template: "app/README.md.tpl"}
+	st.SetDebugger(d)
+
+	_, err := st.Render(ctx, logrus.New())
+	assert.ErrorContains(t, err, "can't evaluate field Missing")
+	assert.DeepEqual(t, d.Trace.Args, []ArgRead{{Name: "name", Value: "", Source: ArgSourceZero}})
+}
+
+func TestDebuggerTemplateNotFound(t *testing.T) {
+	st := NewStencil(&configuration.ServiceManifest{Name: "test"}, newDebugTestModules(t), logrus.New())
+	st.SetDebugger(&Debugger{Template: "app/missing.tpl"})
+
+	_, err := st.Render(context.Background(), logrus.New())
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestDebuggerAmbiguousTemplate(t *testing.T) {
+	ctx := context.Background()
+
+	var mods []*modules.Module
+	for _, name := range []string{"a", "b"} {
+		fs := createFakeModuleFSWithManifest(t, "name: "+name)
+		assert.NilError(t, util.WriteFile(fs, "templates/README.md.tpl", []byte(name), 0o644))
+		mods = append(mods, modules.NewWithFS(ctx, name, fs))
+	}
+
+	st := NewStencil(&configuration.ServiceManifest{Name: "test"}, mods, logrus.New())
+	st.SetDebugger(&Debugger{Template: "README.md.tpl"})
+	_, err := st.Render(ctx, logrus.New())
+	assert.ErrorIs(t, err, ErrAmbiguousTemplate)
+
+	st = NewStencil(&configuration.ServiceManifest{Name: "test"}, mods, logrus.New())
+	d := &Debugger{Template: "b/README.md.tpl"}
+	st.SetDebugger(d)
+	_, err = st.Render(ctx, logrus.New())
+	assert.NilError(t, err)
+	assert.Equal(t, d.Trace.Files[0].Contents, "b")
+}
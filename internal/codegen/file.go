@@ -6,7 +6,11 @@
 package codegen
 
 import (
+	"bytes"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -30,6 +34,33 @@ type File struct {
 	// mode is the mode of the file
 	mode os.FileMode
 
+	// commentStyles extends DefaultCommentStyles for this file, these
+	// come from the manifest of the module rendering it
+	commentStyles map[string]string
+
+	// commentStyle, if set, is the additional comment prefix block tags
+	// can be written after, regardless of the file's extension
+	commentStyle string
+
+	// renamedBlocks maps the old names of blocks renamed via RenameBlock
+	// to their new names
+	renamedBlocks map[string]string
+
+	// symlinkTarget is the target of this file, if it's a symlink
+	symlinkTarget string
+
+	// mergeStrategy is the strategy used to merge this file with the
+	// other fragments of the same structured file, if set
+	mergeStrategy MergeStrategy
+
+	// mergedKeys are the keys rendered into this file by the fragments
+	// merged into it, as JSON pointers, see mergeStructuredFile
+	mergedKeys []string
+
+	// preservedKeys are the dot-separated paths of keys, in a YAML or
+	// JSON file, whose values are kept from the existing file
+	preservedKeys []string
+
 	// modTime is the modification time of this file (when it was last modified)
 	// Note: This is not always reliable currently.
 	modTime time.Time
@@ -51,18 +82,33 @@ type File struct {
 	// Warnings is an array of warnings that were created
 	// while rendering this template
 	Warnings []string
+
+	// OrphanedBlocks are the blocks that had contents in the existing
+	// file, but that don't exist, or are empty, in the rendered file.
+	// See OrphanedBlocksFile.
+	OrphanedBlocks map[string]string
 }
 
+// OrphanedBlocksSuffix is appended to the path of a file to get the path of
+// the sidecar file that its orphaned blocks are written to.
+const OrphanedBlocksSuffix = ".stencil-orphaned"
+
 // NewFile creates a new file, an existing file at the given path is
 // parsed to read blocks from, if it exists. An error is returned if
 // the file is unable to be read for a reason other than not existing.
 func NewFile(path string, mode os.FileMode, modTime time.Time) (*File, error) {
-	blocks, err := parseBlocks(path)
+	return newFile(path, mode, modTime, nil)
+}
+
+// newFile creates a new file like NewFile, with commentStyles extending
+// DefaultCommentStyles when parsing blocks.
+func newFile(path string, mode os.FileMode, modTime time.Time, commentStyles map[string]string) (*File, error) {
+	blocks, err := parseBlocks(path, CommentStyleFor(path, commentStyles))
 	if err != nil {
 		return nil, err
 	}
 
-	return &File{path: path, mode: mode, modTime: modTime, blocks: blocks}, nil
+	return &File{path: path, mode: mode, modTime: modTime, blocks: blocks, commentStyles: commentStyles}, nil
 }
 
 // Block returns the contents of a given block.
@@ -83,7 +129,7 @@ func (f *File) AddDeprecationNotice(msg string) {
 // SetPath updates the path of this file. This causes
 // the blocks to be parsed again.
 func (f *File) SetPath(path string) error {
-	blocks, err := parseBlocks(path)
+	blocks, err := parseBlocks(path, f.commentPrefix(path))
 	if err != nil {
 		return err
 	}
@@ -93,11 +139,130 @@ func (f *File) SetPath(path string) error {
 	return nil
 }
 
+// commentPrefix returns the additional comment prefix that block tags can
+// be written after if this file was at path.
+func (f *File) commentPrefix(path string) string {
+	if f.commentStyle != "" {
+		return f.commentStyle
+	}
+	return CommentStyleFor(path, f.commentStyles)
+}
+
+// RenameBlock carries the contents of the block oldName in the existing
+// file over to the block newName. If the existing file already has a
+// newName block, its contents are kept instead.
+func (f *File) RenameBlock(oldName, newName string) {
+	if v, ok := f.blocks[oldName]; ok {
+		if _, ok := f.blocks[newName]; !ok {
+			f.blocks[newName] = v
+		}
+	}
+
+	if f.renamedBlocks == nil {
+		f.renamedBlocks = make(map[string]string)
+	}
+	f.renamedBlocks[oldName] = newName
+}
+
+// findOrphanedBlocks sets OrphanedBlocks to the blocks with contents in the
+// existing file that don't exist, or are empty, in the rendered contents,
+// accounting for blocks renamed via RenameBlock.
+func (f *File) findOrphanedBlocks() error {
+	f.OrphanedBlocks = nil
+	if len(f.blocks) == 0 || f.Deleted || f.Skipped || f.symlinkTarget != "" {
+		return nil
+	}
+
+	rendered, err := parseBlocksFrom(bytes.NewReader(f.contents), f.path, f.commentPrefix(f.path))
+	if err != nil {
+		return err
+	}
+
+	for name, contents := range f.blocks {
+		if strings.TrimSpace(contents) == "" {
+			continue
+		}
+
+		if newName, ok := f.renamedBlocks[name]; ok {
+			name = newName
+		}
+		if strings.TrimSpace(rendered[name]) != "" {
+			continue
+		}
+
+		if f.OrphanedBlocks == nil {
+			f.OrphanedBlocks = make(map[string]string)
+		}
+		f.OrphanedBlocks[name] = contents
+	}
+	return nil
+}
+
+// OrphanedBlocksFile returns a file, at the path of this file with
+// OrphanedBlocksSuffix appended, containing the orphaned blocks of this
+// file. Blocks already in an existing sidecar file are kept, so that the
+// contents of blocks orphaned by previous runs aren't lost.
+func (f *File) OrphanedBlocksFile() (*File, error) {
+	sidecar, err := newFile(f.path+OrphanedBlocksSuffix, 0o644, f.modTime, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make(map[string]string, len(sidecar.blocks)+len(f.OrphanedBlocks))
+	for name, contents := range sidecar.blocks {
+		blocks[name] = contents
+	}
+	for name, contents := range f.OrphanedBlocks {
+		blocks[name] = contents
+	}
+
+	names := make([]string, 0, len(blocks))
+	for name := range blocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "## These blocks had contents in %s, but no longer exist in it.\n", f.path)
+	buf.WriteString("## Move their contents back into the file where needed, then delete this file.\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "## <<Stencil::Block(%s)>>\n%s\n## <</Stencil::Block>>\n", name, blocks[name])
+	}
+	sidecar.SetContents(buf.String())
+	return sidecar, nil
+}
+
+// SetCommentStyle sets the additional comment prefix that block tags can
+// be written after in this file, overriding the one for its extension.
+// This causes the blocks to be parsed again.
+func (f *File) SetCommentStyle(prefix string) error {
+	blocks, err := parseBlocks(f.path, prefix)
+	if err != nil {
+		return err
+	}
+	f.blocks = blocks
+	f.commentStyle = prefix
+
+	return nil
+}
+
 // SetMode updates the mode of the file.
 func (f *File) SetMode(mode os.FileMode) {
 	f.mode = mode
 }
 
+// SetSymlink turns this file into a symlink pointing to target. The
+// contents of the file are ignored when it's a symlink.
+func (f *File) SetSymlink(target string) {
+	f.symlinkTarget = target
+}
+
+// SymlinkTarget returns the target of this file if it's a symlink,
+// otherwise an empty string is returned.
+func (f *File) SymlinkTarget() string {
+	return f.symlinkTarget
+}
+
 // SetContents updates the contents of the current file.
 func (f *File) SetContents(contents string) {
 	f.contents = []byte(contents)
@@ -134,6 +299,9 @@ func (f *File) ModTime() time.Time {
 
 // Mode returns the file mode.
 func (f *File) Mode() os.FileMode {
+	if f.symlinkTarget != "" {
+		return f.mode | os.ModeSymlink
+	}
 	return f.mode
 }
 
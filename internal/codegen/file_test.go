@@ -6,7 +6,9 @@ package codegen
 
 import (
 	"io"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/go-git/go-billy/v5/memfs"
 	"gotest.tools/v3/assert"
@@ -42,3 +44,45 @@ func TestFileBasic(t *testing.T) {
 	assert.Equal(t, cnts, string(f.contents), "expected SetContents() to set contents")
 	assert.Equal(t, cnts, f.String(), "expected String() to return proper contents")
 }
+
+func TestFileOrphanedBlocks(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NilError(t, os.WriteFile("main.go", []byte(`package main
+
+// <<Stencil::Block(imports)>>
+import "fmt"
+// <</Stencil::Block>>
+
+// <<Stencil::Block(removed)>>
+func helper() {}
+// <</Stencil::Block>>
+
+// <<Stencil::Block(empty)>>
+// <</Stencil::Block>>
+`), 0o644))
+
+	f, err := NewFile("main.go", 0o644, time.Now())
+	assert.NilError(t, err)
+	f.RenameBlock("imports", "extraImports")
+	assert.Equal(t, f.Block("extraImports"), `import "fmt"`, "expected RenameBlock to carry contents over")
+
+	f.SetContents("package main\n\n// <<Stencil::Block(extraImports)>>\n" + f.Block("extraImports") + "\n// <</Stencil::Block>>\n")
+	assert.NilError(t, f.findOrphanedBlocks())
+	assert.DeepEqual(t, f.OrphanedBlocks, map[string]string{"removed": "func helper() {}"})
+
+	// Blocks orphaned by a previous run are kept in the sidecar
+	assert.NilError(t, os.WriteFile("main.go"+OrphanedBlocksSuffix,
+		[]byte("## <<Stencil::Block(older)>>\nvar x = 1\n## <</Stencil::Block>>\n"), 0o644))
+	sidecar, err := f.OrphanedBlocksFile()
+	assert.NilError(t, err)
+	assert.Equal(t, sidecar.Name(), "main.go.stencil-orphaned")
+	assert.Equal(t, sidecar.String(), `## These blocks had contents in main.go, but no longer exist in it.
+## Move their contents back into the file where needed, then delete this file.
+## <<Stencil::Block(older)>>
+var x = 1
+## <</Stencil::Block>>
+## <<Stencil::Block(removed)>>
+func helper() {}
+## <</Stencil::Block>>
+`)
+}
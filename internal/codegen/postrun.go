@@ -0,0 +1,495 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements running the post-run commands
+// declared by modules.
+
+package codegen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrPostRunCommandCycle is returned when the post-run commands declared by
+// modules depend on each other, via after, in a cycle.
+var ErrPostRunCommandCycle = errors.New("post-run commands have a dependency cycle")
+
+// ErrPostRunCommandDir is returned when a post-run command's working
+// directory is outside of the project.
+var ErrPostRunCommandDir = errors.New("post-run command dir must be a relative path inside of the project")
+
+// postRunWaitDelay is how long to wait for the output of a post-run
+// command to be closed after it was killed, e.g. by a process that
+// escaped its process group.
+const postRunWaitDelay = 5 * time.Second
+
+// PostRunOptions controls how post-run commands are ran by PostRun.
+type PostRunOptions struct {
+	// DryRun logs the commands that would be ran instead of running them.
+	DryRun bool
+
+	// Only, if set, restricts the commands that are ran to the ones with
+	// one of these names.
+	Only []string
+
+	// ChangedFiles are the paths of the files that were created, updated
+	// or deleted by the render. This is used to evaluate when conditions.
+	ChangedFiles []string
+
+	// Stdout and Stderr are where the prefixed output of commands is
+	// written to. Defaults to os.Stdout and os.Stderr.
+	Stdout, Stderr io.Writer
+}
+
+// PostRunStatus is the outcome of a post-run command.
+type PostRunStatus string
+
+// This block contains all of the PostRunStatus values.
+const (
+	// PostRunStatusSucceeded denotes a command that exited successfully.
+	PostRunStatusSucceeded PostRunStatus = "succeeded"
+
+	// PostRunStatusFailed denotes a command that failed, or wasn't allowed to run.
+	PostRunStatusFailed PostRunStatus = "failed"
+
+	// PostRunStatusSkipped denotes a command that was not ran, see Reason.
+	PostRunStatusSkipped PostRunStatus = "skipped"
+)
+
+// PostRunResult is the result of a single post-run command.
+type PostRunResult struct {
+	// Module is the name of the module that declared the command
+	Module string `json:"module"`
+
+	// Name is the name of the command
+	Name string `json:"name"`
+
+	// Command is the command that was ran
+	Command string `json:"command"`
+
+	// Status is the outcome of the command
+	Status PostRunStatus `json:"status"`
+
+	// Reason is why the command was skipped, if it was
+	Reason string `json:"reason,omitempty"`
+
+	// Duration is how long the command took to run, in nanoseconds
+	// when encoded as JSON
+	Duration time.Duration `json:"duration"`
+
+	// Output is the combined stdout and stderr of the command
+	Output string `json:"output,omitempty"`
+
+	// Error is the error the command failed with, if it did
+	Error string `json:"error,omitempty"`
+}
+
+// postRunCommand is a post-run command and the module that declared it.
+type postRunCommand struct {
+	// module is the name of the module that declared this command
+	module string
+
+	// spec is the command as declared in the module's manifest
+	spec *configuration.PostRunCommandSpec
+}
+
+// String returns a human-readable identifier for the command.
+func (c *postRunCommand) String() string {
+	return fmt.Sprintf("%s (%s)", c.name(), c.module)
+}
+
+// name returns the name of the command, falling back to the command itself
+// when the module didn't name it.
+func (c *postRunCommand) name() string {
+	if c.spec.Name == "" {
+		return c.spec.Command
+	}
+	return c.spec.Name
+}
+
+// matches returns true if the command is referenced by ref.
+func (c *postRunCommand) matches(ref *configuration.PostRunCommandRef) bool {
+	return c.module == ref.Module && (ref.Name == "" || ref.Name == c.spec.Name)
+}
+
+// shouldRun returns true if the command's when condition, if any, is met by
+// the provided changed files.
+func (c *postRunCommand) shouldRun(changedFiles []string) bool {
+	if c.spec.When == nil || len(c.spec.When.FilesChanged) == 0 {
+		return true
+	}
+
+	for _, glob := range c.spec.When.FilesChanged {
+		for _, f := range changedFiles {
+			if matchGlob(glob, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// run runs the command through bash, in its working directory and with
+// its environment and timeout applied. Output is written to stdout and
+// stderr with every line prefixed by the module and command name, and the
+// combined output is returned.
+func (c *postRunCommand) run(ctx context.Context, stdout, stderr io.Writer) (string, error) {
+	if c.spec.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.spec.Timeout)
+		defer cancel()
+	}
+
+	//nolint:gosec // Why: This is by design
+	cmd := exec.CommandContext(ctx, "/usr/bin/env", "bash", "-c", c.spec.Command)
+
+	// Run the command in its own process group and kill the whole group
+	// when it times out, otherwise only bash is killed and its children
+	// keep running, holding the output pipes open
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = postRunWaitDelay
+	if c.spec.Dir != "" {
+		if !filepath.IsLocal(c.spec.Dir) {
+			return "", fmt.Errorf("%w: %q", ErrPostRunCommandDir, c.spec.Dir)
+		}
+		cmd.Dir = c.spec.Dir
+	}
+
+	if len(c.spec.Env) > 0 {
+		keys := make([]string, 0, len(c.spec.Env))
+		for k := range c.spec.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		cmd.Env = os.Environ()
+		for _, k := range keys {
+			cmd.Env = append(cmd.Env, k+"="+c.spec.Env[k])
+		}
+	}
+
+	output := &lockedBuffer{}
+	prefix := fmt.Sprintf("[%s: %s] ", c.module, c.name())
+	outw := &prefixWriter{w: stdout, prefix: prefix, capture: output}
+	errw := &prefixWriter{w: stderr, prefix: prefix, capture: output}
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = outw
+	cmd.Stderr = errw
+	err := cmd.Run()
+	outw.Flush()
+	errw.Flush()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return output.String(), errors.Wrapf(err, "timed out after %s", c.spec.Timeout)
+		}
+		return output.String(), err
+	}
+
+	return output.String(), nil
+}
+
+// PostRun runs all post run commands specified in the modules that
+// this service depends on. Commands are ran in module order unless
+// reordered by their after field, and commands whose when condition
+// isn't met by opts.ChangedFiles are skipped. opts may be nil.
+//
+// The result of every command is returned, along with the first error
+// from a command that didn't set continueOnError. A summary of the
+// results is logged once all commands have finished.
+func (s *Stencil) PostRun(ctx context.Context, log logrus.FieldLogger, opts *PostRunOptions) ([]*PostRunResult, error) {
+	if opts == nil {
+		opts = &PostRunOptions{}
+	}
+	stdout, stderr := opts.Stdout, opts.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	cmds, err := s.postRunCommands(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Running post-run command(s)")
+	results := make([]*PostRunResult, 0, len(cmds))
+	var runErr error
+	for _, cmd := range cmds {
+		if len(opts.Only) > 0 && !slices.Contains(opts.Only, cmd.spec.Name) {
+			log.Debugf("Skipping post-run command %s, not selected", cmd)
+			continue
+		}
+
+		res := &PostRunResult{
+			Module:  cmd.module,
+			Name:    cmd.name(),
+			Command: cmd.spec.Command,
+			Status:  PostRunStatusSkipped,
+		}
+		results = append(results, res)
+
+		switch {
+		case runErr != nil:
+			res.Reason = "a previous command failed"
+			continue
+		case !cmd.shouldRun(opts.ChangedFiles):
+			res.Reason = "no matching files changed"
+			log.Infof(" - %s (skipped, %s)", res.Name, res.Reason)
+			continue
+		case opts.DryRun:
+			res.Reason = "dry-run"
+			log.Infof(" - %s (dry-run)", res.Name)
+			continue
+		}
+
+		if err := s.checkCapability(cmd.module, configuration.CapabilityPostRunCommands); err != nil {
+			res.Status = PostRunStatusFailed
+			res.Error = err.Error()
+			runErr = err
+			continue
+		}
+
+		log.Infof(" - %s", res.Name)
+		started := time.Now()
+		res.Output, err = cmd.run(ctx, stdout, stderr)
+		res.Duration = time.Since(started)
+		if err == nil {
+			res.Status = PostRunStatusSucceeded
+			continue
+		}
+
+		res.Status = PostRunStatusFailed
+		res.Error = err.Error()
+		if cmd.spec.ContinueOnError {
+			log.WithError(err).Warnf("Post-run command %s failed, continuing", cmd)
+			continue
+		}
+		runErr = errors.Wrapf(err, "failed to run post run command for module %s and command %s", cmd.module, cmd.spec.Command)
+	}
+
+	logPostRunSummary(log, results)
+	return results, runErr
+}
+
+// logPostRunSummary logs the status and duration of each post-run command.
+func logPostRunSummary(log logrus.FieldLogger, results []*PostRunResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	log.Info("Post-run command summary:")
+	for _, res := range results {
+		msg := fmt.Sprintf(" - %-9s %s (%s)", res.Status, res.Name, res.Module)
+		switch res.Status {
+		case PostRunStatusSucceeded:
+			log.Infof("%s in %s", msg, res.Duration.Round(time.Millisecond))
+		case PostRunStatusFailed:
+			log.Errorf("%s in %s: %s", msg, res.Duration.Round(time.Millisecond), res.Error)
+		case PostRunStatusSkipped:
+			log.Infof("%s: %s", msg, res.Reason)
+		}
+	}
+}
+
+// postRunCommands returns the post-run commands of all modules, in the
+// order they should be ran.
+func (s *Stencil) postRunCommands(ctx context.Context) ([]*postRunCommand, error) {
+	var cmds []*postRunCommand
+	for _, m := range s.modules {
+		mf, err := m.Manifest(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, spec := range mf.PostRunCommand {
+			cmds = append(cmds, &postRunCommand{module: m.Name, spec: spec})
+		}
+	}
+
+	return orderPostRunCommands(cmds)
+}
+
+// orderPostRunCommands orders the provided commands so that every command
+// is ran after the commands referenced by its after field. Otherwise, the
+// original order is preserved. References to commands that don't exist
+// are ignored, since the module declaring them may not be used by the
+// current service.
+func orderPostRunCommands(cmds []*postRunCommand) ([]*postRunCommand, error) {
+	// deps[i] are the indexes of the commands that must be ran before cmds[i]
+	deps := make([][]int, len(cmds))
+	for i, cmd := range cmds {
+		for _, ref := range cmd.spec.After {
+			for j, other := range cmds {
+				if i != j && other.matches(ref) {
+					deps[i] = append(deps[i], j)
+				}
+			}
+		}
+	}
+
+	ordered := make([]*postRunCommand, 0, len(cmds))
+	done := make([]bool, len(cmds))
+	for len(ordered) < len(cmds) {
+		next := -1
+		for i := range cmds {
+			if done[i] {
+				continue
+			}
+
+			ready := true
+			for _, j := range deps[i] {
+				if !done[j] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+
+		// Nothing is ready to be ran, so the remaining commands
+		// depend on each other.
+		if next == -1 {
+			remaining := make([]string, 0)
+			for i, cmd := range cmds {
+				if !done[i] {
+					remaining = append(remaining, cmd.String())
+				}
+			}
+			return nil, fmt.Errorf("%w: %s", ErrPostRunCommandCycle, strings.Join(remaining, ", "))
+		}
+
+		done[next] = true
+		ordered = append(ordered, cmds[next])
+	}
+
+	return ordered, nil
+}
+
+// matchGlob returns true if the slash separated file path name matches
+// pattern. A "**" segment matches any number of directories, and a
+// pattern without a "/" is matched against the base name of the path.
+func matchGlob(pattern, name string) bool {
+	pattern = path.Clean(filepath.ToSlash(pattern))
+	name = path.Clean(filepath.ToSlash(name))
+
+	if !strings.Contains(pattern, "/") {
+		ok, err := path.Match(pattern, path.Base(name))
+		return err == nil && ok
+	}
+
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchGlobSegments matches path segments against glob segments, see
+// matchGlob.
+func matchGlobSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlobSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// lockedBuffer is a bytes.Buffer that is safe to write to from the
+// goroutines copying a command's stdout and stderr.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write implements io.Writer.
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the contents of the buffer.
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// prefixWriter is an io.Writer that writes every line written to it to w
+// with prefix prepended, and the unmodified output to capture. Partial
+// lines are buffered until they're terminated, or Flush is called.
+type prefixWriter struct {
+	w       io.Writer
+	prefix  string
+	capture io.Writer
+	partial []byte
+}
+
+// Write implements io.Writer.
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	if _, err := p.capture.Write(b); err != nil {
+		return 0, err
+	}
+
+	p.partial = append(p.partial, b...)
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			break
+		}
+
+		if _, err := io.WriteString(p.w, p.prefix+string(p.partial[:i+1])); err != nil {
+			return 0, err
+		}
+		p.partial = p.partial[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// Flush writes any buffered partial line, terminated with a newline.
+func (p *prefixWriter) Flush() {
+	if len(p.partial) == 0 {
+		return
+	}
+
+	//nolint:errcheck // Why: Best effort, the output is also captured
+	io.WriteString(p.w, p.prefix+string(p.partial)+"\n")
+	p.partial = nil
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for running post-run commands
+
+package codegen
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getoutreach/stencil/internal/modules"
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"go.mod", "go.mod", true},
+		{"go.mod", "tools/go.mod", true},
+		{"*.go", "cmd/stencil/stencil.go", true},
+		{"cmd/*.go", "cmd/stencil/stencil.go", false},
+		{"cmd/**/*.go", "cmd/stencil/stencil.go", true},
+		{"cmd/**/*.go", "cmd/main.go", true},
+		{"**/package.json", "package.json", true},
+		{"**/package.json", "web/app/package.json", true},
+		{"web/package.json", "./web/package.json", true},
+		{"web/package.json", "api/package.json", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, matchGlob(tt.pattern, tt.name), tt.want)
+		})
+	}
+}
+
+// postRunNames returns the "module/name" of each command, for comparison.
+func postRunNames(cmds []*postRunCommand) []string {
+	names := make([]string, 0, len(cmds))
+	for _, c := range cmds {
+		names = append(names, c.module+"/"+c.spec.Name)
+	}
+	return names
+}
+
+func TestOrderPostRunCommands(t *testing.T) {
+	cmds := []*postRunCommand{
+		{module: "a", spec: &configuration.PostRunCommandSpec{Name: "1", After: []*configuration.PostRunCommandRef{
+			{Module: "b"},
+		}}},
+		{module: "a", spec: &configuration.PostRunCommandSpec{Name: "2"}},
+		{module: "b", spec: &configuration.PostRunCommandSpec{Name: "1"}},
+		{module: "b", spec: &configuration.PostRunCommandSpec{Name: "2", After: []*configuration.PostRunCommandRef{
+			{Module: "a", Name: "2"},
+			{Module: "not-used"},
+		}}},
+	}
+
+	ordered, err := orderPostRunCommands(cmds)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, postRunNames(ordered), []string{"a/2", "b/1", "b/2", "a/1"})
+}
+
+func TestOrderPostRunCommandsCycle(t *testing.T) {
+	cmds := []*postRunCommand{
+		{module: "a", spec: &configuration.PostRunCommandSpec{Name: "1", After: []*configuration.PostRunCommandRef{
+			{Module: "b"},
+		}}},
+		{module: "b", spec: &configuration.PostRunCommandSpec{Name: "1", After: []*configuration.PostRunCommandRef{
+			{Module: "a"},
+		}}},
+	}
+
+	_, err := orderPostRunCommands(cmds)
+	assert.ErrorIs(t, err, ErrPostRunCommandCycle)
+}
+
+func TestPostRunConditionsDirAndEnv(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	assert.NilError(t, os.Mkdir("sub", 0o755))
+
+	fs := createFakeModuleFSWithManifest(t, `name: testing
+postRunCommand:
+- name: skipped
+  command: touch skipped
+  when:
+    filesChanged: ["**/*.go"]
+- name: env
+  command: echo -n "$GREETING" > greeting
+  dir: sub
+  env:
+    GREETING: hello
+  when:
+    filesChanged: ["*.txt"]
+- name: not selected
+  command: touch not-selected
+`)
+	st := NewStencil(&configuration.ServiceManifest{Name: "testing"}, []*modules.Module{
+		modules.NewWithFS(ctx, "testing", fs),
+	}, log)
+
+	results, err := st.PostRun(ctx, log, &PostRunOptions{
+		Only:         []string{"skipped", "env"},
+		ChangedFiles: []string{"docs/readme.txt"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 2, "expected commands not in Only to be excluded from the results")
+	assert.Equal(t, results[0].Status, PostRunStatusSkipped)
+	assert.Equal(t, results[1].Status, PostRunStatusSucceeded)
+
+	b, err := os.ReadFile(filepath.Join("sub", "greeting"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "hello")
+
+	_, err = os.Stat("skipped")
+	assert.Assert(t, os.IsNotExist(err), "expected command with unmet condition to be skipped")
+	_, err = os.Stat("not-selected")
+	assert.Assert(t, os.IsNotExist(err), "expected command not in Only to be skipped")
+}
+
+func TestPostRunDryRun(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	fs := createFakeModuleFSWithManifest(t, "name: testing\npostRunCommand:\n- name: fail\n  command: exit 1\n")
+	st := NewStencil(&configuration.ServiceManifest{Name: "testing"}, []*modules.Module{
+		modules.NewWithFS(ctx, "testing", fs),
+	}, log)
+
+	results, err := st.PostRun(ctx, log, &PostRunOptions{DryRun: true})
+	assert.NilError(t, err)
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Status, PostRunStatusSkipped)
+	assert.Equal(t, results[0].Reason, "dry-run")
+}
+
+func TestPostRunDirOutsideProject(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	fs := createFakeModuleFSWithManifest(t, "name: testing\npostRunCommand:\n- name: escape\n  command: \"true\"\n  dir: ../\n")
+	st := NewStencil(&configuration.ServiceManifest{Name: "testing"}, []*modules.Module{
+		modules.NewWithFS(ctx, "testing", fs),
+	}, log)
+
+	_, err := st.PostRun(ctx, log, &PostRunOptions{Stdout: io.Discard, Stderr: io.Discard})
+	assert.ErrorIs(t, err, ErrPostRunCommandDir)
+}
+
+func TestPostRunOutputIsPrefixed(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	fs := createFakeModuleFSWithManifest(t, `name: testing
+postRunCommand:
+- name: greet
+  command: echo hello; echo -n oops >&2
+`)
+	st := NewStencil(&configuration.ServiceManifest{Name: "testing"}, []*modules.Module{
+		modules.NewWithFS(ctx, "testing", fs),
+	}, log)
+
+	var stdout, stderr bytes.Buffer
+	results, err := st.PostRun(ctx, log, &PostRunOptions{Stdout: &stdout, Stderr: &stderr})
+	assert.NilError(t, err)
+	assert.Equal(t, stdout.String(), "[testing: greet] hello\n")
+	assert.Equal(t, stderr.String(), "[testing: greet] oops\n")
+	assert.Equal(t, len(results), 1)
+
+	// stdout and stderr are read concurrently, so the order that they're
+	// captured in isn't deterministic.
+	assert.Assert(t, strings.Contains(results[0].Output, "hello\n"))
+	assert.Assert(t, strings.Contains(results[0].Output, "oops"))
+}
+
+func TestPostRunContinueOnError(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	t.Chdir(t.TempDir())
+
+	fs := createFakeModuleFSWithManifest(t, `name: testing
+postRunCommand:
+- name: lint
+  command: exit 1
+  continueOnError: true
+- name: build
+  command: exit 2
+- name: test
+  command: touch ran
+`)
+	st := NewStencil(&configuration.ServiceManifest{Name: "testing"}, []*modules.Module{
+		modules.NewWithFS(ctx, "testing", fs),
+	}, log)
+
+	results, err := st.PostRun(ctx, log, &PostRunOptions{Stdout: io.Discard, Stderr: io.Discard})
+	assert.ErrorContains(t, err, "failed to run post run command for module testing and command exit 2")
+
+	statuses := make([]PostRunStatus, 0, len(results))
+	for _, res := range results {
+		statuses = append(statuses, res.Status)
+	}
+	assert.DeepEqual(t, statuses, []PostRunStatus{PostRunStatusFailed, PostRunStatusFailed, PostRunStatusSkipped})
+	assert.Equal(t, results[2].Reason, "a previous command failed")
+
+	_, err = os.Stat("ran")
+	assert.Assert(t, os.IsNotExist(err), "expected commands after a failure to be skipped")
+}
+
+func TestPostRunTimeoutKillsChildProcesses(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	t.Chdir(t.TempDir())
+
+	// The sleep is a child of bash, which holds the output open
+	fs := createFakeModuleFSWithManifest(t, `name: testing
+postRunCommand:
+- name: slow
+  command: sleep 3; touch ran
+  timeout: 200ms
+`)
+	st := NewStencil(&configuration.ServiceManifest{Name: "testing"}, []*modules.Module{
+		modules.NewWithFS(ctx, "testing", fs),
+	}, log)
+
+	start := time.Now()
+	_, err := st.PostRun(ctx, log, &PostRunOptions{Stdout: io.Discard, Stderr: io.Discard})
+	assert.ErrorContains(t, err, "timed out after 200ms")
+	assert.Assert(t, time.Since(start) < 2*time.Second, "expected the command to be killed, took %s", time.Since(start))
+
+	// Make sure the child was killed, rather than left running
+	time.Sleep(3 * time.Second)
+	_, err = os.Stat("ran")
+	assert.Assert(t, os.IsNotExist(err), "expected the child process to be killed")
+}
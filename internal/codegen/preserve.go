@@ -0,0 +1,305 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements preserving user-owned keys of YAML
+// and JSON files across renders.
+
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrUnsupportedPreservedKeyFile is returned when file.PreserveKey is used
+// on a file that isn't YAML or JSON.
+var ErrUnsupportedPreservedKeyFile = errors.New("preserving keys is only supported for .yaml, .yml and .json files")
+
+// ErrInvalidPreservedKey is returned when a preserved key can't be set in
+// the rendered file, e.g. because a parent of it is a scalar.
+var ErrInvalidPreservedKey = errors.New("unable to set preserved key")
+
+// applyPreservedKeys copies the values of the keys marked by
+// file.PreserveKey from the existing files on disk into the rendered files.
+func (s *Stencil) applyPreservedKeys(tpls []*Template) error {
+	for _, t := range tpls {
+		for _, f := range t.Files {
+			if len(f.preservedKeys) == 0 || f.Deleted || f.Skipped || f.symlinkTarget != "" {
+				continue
+			}
+
+			if err := f.applyPreservedKeys(); err != nil {
+				return errors.Wrapf(err, "failed to preserve keys in %q", f.path)
+			}
+		}
+	}
+	return nil
+}
+
+// applyPreservedKeys sets the preserved keys of the file to the values they
+// have in the existing file, if it exists. The file is only re-encoded if
+// at least one of the keys is set in the existing file.
+func (f *File) applyPreservedKeys() error {
+	var isJSON bool
+	switch strings.ToLower(filepath.Ext(f.path)) {
+	case ".yaml", ".yml":
+	case ".json":
+		isJSON = true
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedPreservedKeyFile, f.path)
+	}
+
+	existing, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to read existing file")
+	}
+
+	existingDocs, err := decodeDocuments(existing)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse existing file")
+	}
+
+	docs, err := decodeDocuments(f.contents)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse rendered file")
+	}
+	if len(docs) == 0 {
+		docs = []*yaml.Node{{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}}
+	}
+
+	// Keys are preserved between the documents at the same position in
+	// multi-document YAML files
+	changed := false
+	for i, doc := range docs {
+		if i >= len(existingDocs) {
+			break
+		}
+		for _, key := range f.preservedKeys {
+			v := lookupNode(documentRoot(existingDocs[i]), strings.Split(key, "."))
+			if v == nil {
+				continue
+			}
+
+			if !setNode(documentRoot(doc), strings.Split(key, "."), v) {
+				return fmt.Errorf("%w: %q", ErrInvalidPreservedKey, key)
+			}
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if isJSON {
+		if err := writeNodeJSON(&buf, docs[0], ""); err != nil {
+			return err
+		}
+		buf.WriteString("\n")
+	} else {
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		for _, doc := range docs {
+			if err := enc.Encode(doc); err != nil {
+				return errors.Wrap(err, "failed to encode file")
+			}
+		}
+		if err := enc.Close(); err != nil {
+			return errors.Wrap(err, "failed to encode file")
+		}
+	}
+
+	f.contents = buf.Bytes()
+	return nil
+}
+
+// decodeDocuments returns every document of a YAML, or JSON, file.
+func decodeDocuments(b []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+}
+
+// documentRoot returns the root node of a parsed document.
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0]
+	}
+	return doc
+}
+
+// lookupNode returns the node at the provided path, or nil if it doesn't
+// exist. Mapping nodes are indexed by key, and sequence nodes by index.
+func lookupNode(n *yaml.Node, path []string) *yaml.Node {
+	for _, part := range path {
+		switch n.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == part {
+					next = n.Content[i+1]
+					break
+				}
+			}
+			if next == nil {
+				return nil
+			}
+			n = next
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(n.Content) {
+				return nil
+			}
+			n = n.Content[i]
+		default:
+			return nil
+		}
+	}
+	return n
+}
+
+// setNode sets the node at the provided path to v, creating any missing
+// mapping nodes along the way. Returns false if the path can't be set.
+func setNode(n *yaml.Node, path []string, v *yaml.Node) bool {
+	for i, part := range path {
+		last := i == len(path)-1
+
+		switch n.Kind {
+		case yaml.MappingNode:
+			idx := -1
+			for j := 0; j+1 < len(n.Content); j += 2 {
+				if n.Content[j].Value == part {
+					idx = j + 1
+					break
+				}
+			}
+			if idx == -1 {
+				n.Content = append(n.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part},
+					&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+				idx = len(n.Content) - 1
+			}
+
+			if last {
+				n.Content[idx] = v
+				return true
+			}
+			n = n.Content[idx]
+		case yaml.SequenceNode:
+			j, err := strconv.Atoi(part)
+			if err != nil || j < 0 || j >= len(n.Content) {
+				return false
+			}
+
+			if last {
+				n.Content[j] = v
+				return true
+			}
+			n = n.Content[j]
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// writeNodeJSON writes n as indented JSON, keeping the order of keys.
+func writeNodeJSON(buf *bytes.Buffer, n *yaml.Node, indent string) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeNodeJSON(buf, n.Content[0], indent)
+	case yaml.AliasNode:
+		return writeNodeJSON(buf, n.Alias, indent)
+	case yaml.MappingNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, err := marshalJSONString(n.Content[i].Value)
+			if err != nil {
+				return err
+			}
+
+			buf.WriteString(indent + "  ")
+			buf.Write(key)
+			buf.WriteString(": ")
+			if err := writeNodeJSON(buf, n.Content[i+1], indent+"  "); err != nil {
+				return err
+			}
+			if i+2 < len(n.Content) {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "}")
+	case yaml.SequenceNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+
+		buf.WriteString("[\n")
+		for i, c := range n.Content {
+			buf.WriteString(indent + "  ")
+			if err := writeNodeJSON(buf, c, indent+"  "); err != nil {
+				return err
+			}
+			if i+1 < len(n.Content) {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "]")
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!null", "!!bool", "!!int", "!!float":
+			buf.WriteString(n.Value)
+		default:
+			b, err := marshalJSONString(n.Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(b)
+		}
+	}
+	return nil
+}
+
+// marshalJSONString returns s encoded as a JSON string, without escaping
+// HTML characters like json.Marshal does.
+func marshalJSONString(s string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
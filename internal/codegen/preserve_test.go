@@ -0,0 +1,140 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for preserving keys of YAML and
+// JSON files
+
+package codegen
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestApplyPreservedKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		keys     []string
+		existing string
+		rendered string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "yaml keeps existing values and comments",
+			path:     "deployment.yaml",
+			keys:     []string{"spec.replicas", "metadata.labels"},
+			existing: "spec:\n  replicas: 5 # scaled up for launch\n",
+			rendered: "# generated\nmetadata:\n  name: app\nspec:\n  replicas: 1\n  image: app:v2\n",
+			want: "# generated\nmetadata:\n  name: app\nspec:\n  replicas: 5 # scaled up for launch\n" +
+				"  image: app:v2\n",
+		},
+		{
+			name:     "yaml creates missing parents",
+			path:     "config.yml",
+			keys:     []string{"a.b.c"},
+			existing: "a:\n  b:\n    c: [1, 2]\n",
+			rendered: "name: app\n",
+			want:     "name: app\na:\n  b:\n    c: [1, 2]\n",
+		},
+		{
+			name:     "json keeps key order",
+			path:     "package.json",
+			keys:     []string{"scripts.test", "workspaces.1"},
+			existing: `{"scripts": {"test": "jest --ci"}, "workspaces": ["a", "c"]}`,
+			rendered: `{"name": "app", "scripts": {"build": "tsc", "test": "jest"}, "workspaces": ["a", "b"], "private": true}`,
+			want: `{
+  "name": "app",
+  "scripts": {
+    "build": "tsc",
+    "test": "jest --ci"
+  },
+  "workspaces": [
+    "a",
+    "c"
+  ],
+  "private": true
+}
+`,
+		},
+		{
+			name:     "yaml keeps every document",
+			path:     "k8s.yaml",
+			keys:     []string{"spec.replicas"},
+			existing: "kind: Deployment\nspec:\n  replicas: 3\n---\nkind: Service\nspec:\n  type: NodePort\n",
+			rendered: "kind: Deployment\nspec:\n  replicas: 1\n---\nkind: Service\nspec:\n  type: ClusterIP\n",
+			want:     "kind: Deployment\nspec:\n  replicas: 3\n---\nkind: Service\nspec:\n  type: ClusterIP\n",
+		},
+		{
+			name:     "json doesn't escape html characters",
+			path:     "package.json",
+			keys:     []string{"scripts.test"},
+			existing: `{"scripts": {"test": "jest <ci>"}}`,
+			rendered: `{"scripts": {"build": "tsc && webpack", "test": "jest"}}`,
+			want: `{
+  "scripts": {
+    "build": "tsc && webpack",
+    "test": "jest <ci>"
+  }
+}
+`,
+		},
+		{
+			name:     "unchanged when the existing file doesn't set the key",
+			path:     "package.json",
+			keys:     []string{"scripts.test"},
+			existing: `{"name": "app"}`,
+			rendered: `{"name":"app"}`,
+			want:     `{"name":"app"}`,
+		},
+		{
+			name:     "unsupported file",
+			path:     "Makefile",
+			keys:     []string{"all"},
+			existing: "all:\n",
+			wantErr:  ErrUnsupportedPreservedKeyFile,
+		},
+		{
+			name:     "parent is a scalar",
+			path:     "config.yaml",
+			keys:     []string{"a.b"},
+			existing: "a:\n  b: 1\n",
+			rendered: "a: 1\n",
+			wantErr:  ErrInvalidPreservedKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			assert.NilError(t, os.WriteFile(tt.path, []byte(tt.existing), 0o644))
+
+			f, err := NewFile(tt.path, 0o644, time.Now())
+			assert.NilError(t, err)
+			f.SetContents(tt.rendered)
+			f.preservedKeys = tt.keys
+
+			err = f.applyPreservedKeys()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, f.String(), tt.want)
+		})
+	}
+}
+
+func TestApplyPreservedKeysNoExistingFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	f, err := NewFile("config.yaml", 0o644, time.Now())
+	assert.NilError(t, err)
+	f.SetContents("a: 1\n")
+	f.preservedKeys = []string{"a"}
+
+	assert.NilError(t, f.applyPreservedKeys())
+	assert.Equal(t, f.String(), "a: 1\n")
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains the detection of the languages and
+// package managers used by the project being rendered, exposed to
+// templates as .Project.
+
+package codegen
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// project contains information about the project being rendered,
+// detected from the files in it.
+type project struct {
+	// GoModule is the module path declared in the go.mod, if there is one
+	GoModule string
+
+	// Languages are the languages used by the project, detected from the
+	// files at its root, e.g. "go" or "typescript". Sorted.
+	Languages []string
+
+	// PackageManagers are the package managers used by the project,
+	// detected from the files at its root, e.g. "go" or "yarn". Sorted.
+	PackageManagers []string
+}
+
+// HasLanguage returns true if the project uses the provided language.
+func (p project) HasLanguage(lang string) bool {
+	return slices.Contains(p.Languages, lang)
+}
+
+// HasPackageManager returns true if the project uses the provided
+// package manager.
+func (p project) HasPackageManager(pm string) bool {
+	return slices.Contains(p.PackageManagers, pm)
+}
+
+// projectMarker is a file whose presence at the root of a project
+// denotes that it uses a language and/or package manager.
+type projectMarker struct {
+	// File is the name of the file
+	File string
+
+	// Language is the language the file denotes, if any
+	Language string
+
+	// PackageManager is the package manager the file denotes, if any
+	PackageManager string
+}
+
+// projectMarkers are the files used to detect the languages and package
+// managers of a project.
+var projectMarkers = []projectMarker{
+	{File: "go.mod", Language: "go", PackageManager: "go"},
+	{File: "package.json", Language: "javascript"},
+	{File: "tsconfig.json", Language: "typescript"},
+	{File: "package-lock.json", PackageManager: "npm"},
+	{File: "yarn.lock", PackageManager: "yarn"},
+	{File: "pnpm-lock.yaml", PackageManager: "pnpm"},
+	{File: "bun.lockb", PackageManager: "bun"},
+	{File: "pyproject.toml", Language: "python"},
+	{File: "setup.py", Language: "python"},
+	{File: "requirements.txt", Language: "python", PackageManager: "pip"},
+	{File: "Pipfile", Language: "python", PackageManager: "pipenv"},
+	{File: "poetry.lock", PackageManager: "poetry"},
+	{File: "uv.lock", PackageManager: "uv"},
+	{File: "Gemfile", Language: "ruby", PackageManager: "bundler"},
+	{File: "Cargo.toml", Language: "rust", PackageManager: "cargo"},
+	{File: "pom.xml", Language: "java", PackageManager: "maven"},
+	{File: "build.gradle", Language: "java", PackageManager: "gradle"},
+	{File: "build.gradle.kts", Language: "kotlin", PackageManager: "gradle"},
+}
+
+// detectProject returns the information about the project in dir.
+func detectProject(dir string) project {
+	var p project
+	for _, m := range projectMarkers {
+		if _, err := os.Stat(filepath.Join(dir, m.File)); err != nil {
+			continue
+		}
+		if m.Language != "" && !p.HasLanguage(m.Language) {
+			p.Languages = append(p.Languages, m.Language)
+		}
+		if m.PackageManager != "" && !p.HasPackageManager(m.PackageManager) {
+			p.PackageManagers = append(p.PackageManagers, m.PackageManager)
+		}
+	}
+
+	// A package.json without a lockfile defaults to npm
+	if p.HasLanguage("javascript") && !slices.ContainsFunc(p.PackageManagers, func(pm string) bool {
+		return pm == "npm" || pm == "yarn" || pm == "pnpm" || pm == "bun"
+	}) {
+		p.PackageManagers = append(p.PackageManagers, "npm")
+	}
+
+	slices.Sort(p.Languages)
+	slices.Sort(p.PackageManagers)
+
+	if b, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		p.GoModule = parseGoModulePath(b)
+	}
+	return p
+}
+
+// parseGoModulePath returns the module path declared in the provided
+// go.mod, or an empty string if it doesn't declare one.
+func parseGoModulePath(gomod []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(gomod))
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+
+		path, ok := strings.CutPrefix(strings.TrimSpace(line), "module")
+		if !ok || path == "" || (path[0] != ' ' && path[0] != '\t' && path[0] != '"') {
+			continue
+		}
+
+		path = strings.TrimSpace(path)
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		return path
+	}
+	return ""
+}
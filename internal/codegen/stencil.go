@@ -9,9 +9,9 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 
 	"github.com/getoutreach/gobox/pkg/app"
@@ -20,12 +20,17 @@ import (
 	"github.com/getoutreach/stencil/pkg/extensions"
 	"github.com/getoutreach/stencil/pkg/extensions/apiv1"
 	"github.com/getoutreach/stencil/pkg/stencil"
+	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/util"
 	"github.com/mitchellh/hashstructure/v2"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+// ErrCapabilityNotGranted is returned when a module attempts a privileged
+// operation that the service manifest has not granted it.
+var ErrCapabilityNotGranted = errors.New("capability not granted to module")
+
 // Stencil provides the basic functions for
 // stencil templates.
 type Stencil struct {
@@ -44,6 +49,22 @@ type Stencil struct {
 
 	// sharedData is the store for module hook data and globals
 	sharedData *sharedData
+
+	// granted is the capabilities granted to each module, keyed by
+	// module name. When nil, capabilities are not enforced.
+	granted map[string][]configuration.Capability
+
+	// secrets are the values of the secrets referenced by the arguments,
+	// see ResolveArguments
+	secrets []string
+
+	// debugger, if set, traces the render of a single template, see
+	// SetDebugger
+	debugger *Debugger
+
+	// lock is the lockfile of the previous run, if there was one, see
+	// SetLockfile
+	lock *stencil.Lockfile
 }
 
 // NewStencil creates a new, fully initialized Stencil renderer function.
@@ -116,10 +137,45 @@ func (*sharedData) key(module, key string) string {
 	return path.Join(module, key)
 }
 
+// GrantCapabilities restricts the privileged operations that modules may
+// perform to the provided capabilities, keyed by module name. Until this
+// is called capabilities are not enforced, which keeps library usage
+// (e.g. stenciltest) unchanged.
+func (s *Stencil) GrantCapabilities(granted map[string][]configuration.Capability) {
+	s.granted = granted
+}
+
+// checkCapability returns ErrCapabilityNotGranted if capabilities are
+// being enforced and the provided module was not granted c.
+func (s *Stencil) checkCapability(module string, c configuration.Capability) error {
+	if s.granted == nil || slices.Contains(s.granted[module], c) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: module %q requires %q (%s), grant it under 'permissions' in service.yaml",
+		ErrCapabilityNotGranted, module, c, c.Description())
+}
+
+// SetLockfile sets the lockfile of the previous run, which is used to
+// remove the keys of merged structured files that are no longer rendered.
+func (s *Stencil) SetLockfile(l *stencil.Lockfile) {
+	s.lock = l
+}
+
 // RegisterExtensions registers all extensions on the currently loaded
 // modules.
 func (s *Stencil) RegisterExtensions(ctx context.Context) error {
 	for _, m := range s.modules {
+		mf, err := m.Manifest(ctx)
+		if err != nil {
+			return err
+		}
+		if mf.Type.Contains(configuration.TemplateRepositoryTypeExt) {
+			if err := s.checkCapability(m.Name, configuration.CapabilityNativeExtensions); err != nil {
+				return err
+			}
+		}
+
 		if err := m.RegisterExtensions(ctx, s.log, s.ext); err != nil {
 			return errors.Wrapf(err, "failed to load extensions from module %q", m.Name)
 		}
@@ -149,19 +205,29 @@ func (s *Stencil) GenerateLockfile(tpls []*Template) *stencil.Lockfile {
 				continue
 			}
 
-			l.Files = append(l.Files, &stencil.LockfileFileEntry{
+			entry := &stencil.LockfileFileEntry{
 				Name:     f.Name(),
 				Template: tpl.Path,
 				Module:   tpl.Module.Name,
-			})
+			}
+			switch {
+			case f.SymlinkTarget() != "":
+				entry.Type = stencil.LockfileFileTypeSymlink
+				entry.Target = f.SymlinkTarget()
+			case tpl.IsAsset():
+				entry.Type = stencil.LockfileFileTypeAsset
+			}
+			entry.Keys = f.mergedKeys
+			l.Files = append(l.Files, entry)
 		}
 	}
 
 	for _, m := range s.modules {
 		l.Modules = append(l.Modules, &stencil.LockfileModuleEntry{
-			Name:    m.Name,
-			URL:     m.URI,
-			Version: m.Version,
+			Name:         m.Name,
+			URL:          m.URI,
+			Version:      m.Version,
+			Capabilities: s.granted[m.Name],
 		})
 	}
 
@@ -186,6 +252,12 @@ func (s *Stencil) Render(ctx context.Context, log logrus.FieldLogger) ([]*Templa
 		return nil, err
 	}
 
+	if s.debugger != nil {
+		if err := s.debugger.resolve(tplfiles); err != nil {
+			return nil, err
+		}
+	}
+
 	if s.extCaller, err = s.ext.GetExtensionCaller(ctx); err != nil {
 		return nil, err
 	}
@@ -214,7 +286,14 @@ func (s *Stencil) Render(ctx context.Context, log logrus.FieldLogger) ([]*Templa
 	// Render the first pass, this is used to populate shared data
 	for _, t := range tplfiles {
 		log.Debugf("First pass render of template %s", t.ImportPath())
-		if err := t.Render(s, vals); err != nil {
+		err := t.Render(s, vals)
+		if err != nil && s.debugger != nil && t == s.debugger.tpl {
+			// The template being debugged fails again in the second pass,
+			// where it's traced
+			log.WithError(err).Debugf("Failed first pass render of template %s", t.ImportPath())
+			err = nil
+		}
+		if err != nil {
 			return nil, errors.Wrapf(err, "failed to render template %q", t.ImportPath())
 		}
 
@@ -228,8 +307,16 @@ func (s *Stencil) Render(ctx context.Context, log logrus.FieldLogger) ([]*Templa
 
 	tpls := make([]*Template, 0)
 	for _, t := range tplfiles {
+		if s.debugger != nil && t != s.debugger.tpl {
+			continue
+		}
+
 		log.Debugf("Second pass render of template %s", t.ImportPath())
-		if err := t.Render(s, vals); err != nil {
+		err := t.Render(s, vals)
+		if s.debugger != nil {
+			s.debugger.record(t)
+		}
+		if err != nil {
 			return nil, errors.Wrapf(err, "failed to render template %q", t.ImportPath())
 		}
 
@@ -237,33 +324,23 @@ func (s *Stencil) Render(ctx context.Context, log logrus.FieldLogger) ([]*Templa
 		tpls = append(tpls, t)
 	}
 
-	return tpls, nil
-}
+	if err := s.mergeStructuredFiles(tpls); err != nil {
+		return nil, err
+	}
 
-// PostRun runs all post run commands specified in the modules that
-// this service depends on.
-func (s *Stencil) PostRun(ctx context.Context, log logrus.FieldLogger) error {
-	log.Info("Running post-run command(s)")
-	for _, m := range s.modules {
-		mf, err := m.Manifest(ctx)
-		if err != nil {
-			return err
-		}
+	if err := s.applyPreservedKeys(tpls); err != nil {
+		return nil, err
+	}
 
-		for _, cmdStr := range mf.PostRunCommand {
-			log.Infof(" - %s", cmdStr.Name)
-			//nolint:gosec // Why: This is by design
-			cmd := exec.CommandContext(ctx, "/usr/bin/env", "bash", "-c", cmdStr.Command)
-			cmd.Stdin = os.Stdin
-			cmd.Stderr = os.Stderr
-			cmd.Stdout = os.Stdout
-			if err := cmd.Run(); err != nil {
-				return errors.Wrapf(err, "failed to run post run command for module %s and command %s", m.Name, cmdStr.Command)
+	for _, t := range tpls {
+		for _, f := range t.Files {
+			if err := f.findOrphanedBlocks(); err != nil {
+				log.WithError(err).Debugf("Failed to check %q for orphaned blocks", f.Name())
 			}
 		}
 	}
 
-	return nil
+	return tpls, nil
 }
 
 // Close closes all resources that should be closed when done
@@ -339,6 +416,12 @@ func (s *Stencil) getTemplates(ctx context.Context, log logrus.FieldLogger) ([]*
 
 		log.Debugf("Discovering templates from module %q", m.Name)
 
+		assets, err := getAssets(m, fs, log)
+		if err != nil {
+			return nil, err
+		}
+		tpls = append(tpls, assets...)
+
 		// default to templates/, but if it's not present fallback to
 		// the root w/ a warning
 		// Note: This behaviour is deprecated and will be removed soon. Put templates
@@ -379,6 +462,7 @@ func (s *Stencil) getTemplates(ctx context.Context, log logrus.FieldLogger) ([]*
 			if err != nil {
 				return errors.Wrapf(err, "failed to create template %q from module %q", path, m.Name)
 			}
+			tpl.commentStyles = mf.CommentStyles
 			tpls = append(tpls, tpl)
 
 			return nil
@@ -392,3 +476,43 @@ func (s *Stencil) getTemplates(ctx context.Context, log logrus.FieldLogger) ([]*
 
 	return tpls, nil
 }
+
+// getAssets returns the non-templated assets of a module, these are all of
+// the files in the assets/ directory of the module, which are copied
+// verbatim to the same path, relative to assets/, in the project.
+func getAssets(m *modules.Module, fs billy.Filesystem, log logrus.FieldLogger) ([]*Template, error) {
+	if inf, err := fs.Stat("assets"); err != nil || !inf.IsDir() {
+		return nil, nil
+	}
+
+	fs, err := fs.Chroot("assets")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to chroot module filesystem to assets/")
+	}
+
+	assets := make([]*Template, 0)
+	err = util.Walk(fs, "", func(path string, inf os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if inf.IsDir() {
+			return nil
+		}
+
+		contents, err := util.ReadFile(fs, path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read asset %q from module %q", path, m.Name)
+		}
+
+		log.Debugf("Discovered asset %q", path)
+		asset, err := NewAsset(m, path, inf.Mode(), inf.ModTime(), contents, log)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create asset %q from module %q", path, m.Name)
+		}
+		assets = append(assets, asset)
+
+		return nil
+	})
+	return assets, err
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/getoutreach/stencil/pkg/configuration"
 	"github.com/getoutreach/stencil/pkg/stencil"
 	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/sirupsen/logrus"
 	"gotest.tools/v3/assert"
 )
@@ -61,6 +62,56 @@ func TestBasicE2ERender(t *testing.T) {
 	})
 }
 
+func TestAssetAndSymlinkRender(t *testing.T) {
+	ctx := context.Background()
+	t.Chdir(t.TempDir())
+
+	fs := createFakeModuleFSWithManifest(t, "name: testing")
+	assert.NilError(t, util.WriteFile(fs, "templates/Makefile.tpl", []byte(`{{ file.Symlink "../Makefile" }}`), 0o644))
+	assert.NilError(t, util.WriteFile(fs, "assets/images/logo.png", []byte{0x89, 'P', 'N', 'G', 0x00}, 0o644))
+
+	st := NewStencil(&configuration.ServiceManifest{Name: "test"}, []*modules.Module{
+		modules.NewWithFS(ctx, "testing", fs),
+	}, logrus.New())
+
+	tpls, err := st.Render(ctx, logrus.New())
+	assert.NilError(t, err, "expected Render() to not fail")
+	assert.Equal(t, len(tpls), 2, "expected Render() to return an asset and a template")
+	assert.DeepEqual(t, tpls[0].Files[0].Bytes(), []byte{0x89, 'P', 'N', 'G', 0x00})
+
+	lock := st.GenerateLockfile(tpls)
+	assert.DeepEqual(t, lock.Files, []*stencil.LockfileFileEntry{
+		{
+			Name:     "Makefile",
+			Template: "Makefile.tpl",
+			Module:   "testing",
+			Type:     stencil.LockfileFileTypeSymlink,
+			Target:   "../Makefile",
+		},
+		{
+			Name:     "images/logo.png",
+			Template: "images/logo.png",
+			Module:   "testing",
+			Type:     stencil.LockfileFileTypeAsset,
+		},
+	})
+}
+
+func TestSymlinkOutsideProjectCapabilityNotGranted(t *testing.T) {
+	ctx := context.Background()
+
+	fs := createFakeModuleFSWithManifest(t, "name: testing")
+	assert.NilError(t, util.WriteFile(fs, "templates/secrets.tpl", []byte(`{{ file.Symlink "/etc/passwd" }}`), 0o644))
+
+	st := NewStencil(&configuration.ServiceManifest{Name: "test"}, []*modules.Module{
+		modules.NewWithFS(ctx, "testing", fs),
+	}, logrus.New())
+	st.GrantCapabilities(map[string][]configuration.Capability{})
+
+	_, err := st.Render(ctx, logrus.New())
+	assert.ErrorIs(t, err, ErrCapabilityNotGranted)
+}
+
 func TestModuleHookRender(t *testing.T) {
 	ctx := context.Background()
 
@@ -110,13 +161,13 @@ func ExampleStencil_PostRun() {
 	}, []*modules.Module{
 		modules.NewWithFS(ctx, "testing", fs),
 	}, logrus.New())
-	err := st.PostRun(ctx, nullLog)
+	_, err := st.PostRun(ctx, nullLog, nil)
 	if err != nil {
 		fmt.Println(err)
 	}
 
 	// Output:
-	// hello
+	// [testing: echo "hello"] hello
 }
 
 func TestStencilPostRunError(t *testing.T) {
@@ -139,5 +190,46 @@ func TestStencilPostRunError(t *testing.T) {
 		logger,
 	)
 
-	assert.ErrorContains(t, st.PostRun(ctx, logger), errMsg)
+	_, err := st.PostRun(ctx, logger, nil)
+	assert.ErrorContains(t, err, errMsg)
+}
+
+func TestStencilPostRunCapabilityNotGranted(t *testing.T) {
+	const name = "TestStencilPostRunCapabilityNotGranted"
+
+	ctx := context.Background()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	fs := createFakeModuleFSWithManifest(t, fmt.Sprintf("name: %s\npostRunCommand:\n- command: exit 1\n", name))
+	st := NewStencil(
+		&configuration.ServiceManifest{Name: name},
+		[]*modules.Module{modules.NewWithFS(ctx, name, fs)},
+		logger,
+	)
+	st.GrantCapabilities(map[string][]configuration.Capability{})
+
+	_, err := st.PostRun(ctx, logger, nil)
+	assert.ErrorIs(t, err, ErrCapabilityNotGranted)
+}
+
+func TestRemoveAllCapabilityNotGranted(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	fs := createFakeModuleFSWithManifest(t, "name: testing")
+	f, err := fs.Create("test-template.tpl")
+	assert.NilError(t, err)
+	_, err = f.Write([]byte(`{{ file.RemoveAll "does-not-exist" }}`))
+	assert.NilError(t, err)
+	assert.NilError(t, f.Close())
+
+	st := NewStencil(&configuration.ServiceManifest{Name: "test"}, []*modules.Module{
+		modules.NewWithFS(ctx, "testing", fs),
+	}, log)
+	st.GrantCapabilities(map[string][]configuration.Capability{})
+
+	_, err = st.Render(ctx, log)
+	assert.ErrorIs(t, err, ErrCapabilityNotGranted)
 }
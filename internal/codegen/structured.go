@@ -0,0 +1,397 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements merging structured (YAML, JSON and
+// TOML) files that multiple templates contribute fragments to.
+
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrUnknownMergeStrategy is returned when file.Merge is called with a
+// strategy that doesn't exist.
+var ErrUnknownMergeStrategy = errors.New("unknown merge strategy")
+
+// ErrUnsupportedStructuredFile is returned when a file that is merged
+// doesn't have a YAML, JSON or TOML extension.
+var ErrUnsupportedStructuredFile = errors.New("unsupported structured file, expected a .yaml, .yml, .json or .toml extension")
+
+// ErrMergeStrategyMismatch is returned when the templates contributing to a
+// structured file declare different merge strategies.
+var ErrMergeStrategyMismatch = errors.New("templates declared different merge strategies for the same file")
+
+// ErrStructuredFileConflict is returned when two fragments of a structured
+// file using the error strategy set a key to different values.
+var ErrStructuredFileConflict = errors.New("conflicting values for key")
+
+// ErrMultiDocumentStructuredFile is returned when a YAML file that is
+// merged, or one of its fragments, contains more than one document.
+var ErrMultiDocumentStructuredFile = errors.New("merging YAML files with more than one document isn't supported")
+
+// MergeStrategy is how the fragments of a structured file are merged
+// together. Maps are always merged recursively, the strategy controls how
+// lists and scalars are merged.
+type MergeStrategy string
+
+// This block contains all of the MergeStrategy values.
+const (
+	// MergeStrategyAppend appends the items of lists that aren't already
+	// present, and overrides scalars with the value of the later fragment.
+	MergeStrategyAppend MergeStrategy = "append"
+
+	// MergeStrategyOverride replaces lists and scalars with the value of
+	// the later fragment.
+	MergeStrategyOverride MergeStrategy = "override"
+
+	// MergeStrategyError appends the items of lists that aren't already
+	// present, and returns an error when fragments set a scalar to
+	// different values.
+	MergeStrategyError MergeStrategy = "error"
+)
+
+// IsValid reports whether m is one of the recognized merge strategies.
+func (m MergeStrategy) IsValid() bool {
+	return slices.Contains([]MergeStrategy{MergeStrategyAppend, MergeStrategyOverride, MergeStrategyError}, m)
+}
+
+// structuredCodec decodes and encodes a structured file format.
+type structuredCodec struct {
+	decode func(string) (any, error)
+	encode func(any) (string, error)
+}
+
+// structuredCodecFor returns the codec for the provided path, based on its
+// extension.
+func structuredCodecFor(path string) (*structuredCodec, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return &structuredCodec{fromYAMLDocument, toYAML}, nil
+	case ".json":
+		return &structuredCodec{fromJSON, toIndentedJSON}, nil
+	case ".toml":
+		return &structuredCodec{fromTOML, toTOML}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedStructuredFile, path)
+	}
+}
+
+// fromYAMLDocument decodes a YAML file containing a single document, an
+// error is returned if it contains more than one since the others would
+// be lost when the merged file is written. Empty documents are ignored.
+func fromYAMLDocument(str string) (any, error) {
+	var out any
+	found := false
+	dec := yaml.NewDecoder(strings.NewReader(str))
+	for {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return out, nil
+			}
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		if found {
+			return nil, ErrMultiDocumentStructuredFile
+		}
+		out, found = v, true
+	}
+}
+
+// toIndentedJSON encodes v as indented JSON, without escaping HTML
+// characters, e.g. "&&" in a package.json script.
+func toIndentedJSON(v any) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// mergeStructuredFiles merges the files that were marked for merging via
+// file.Merge, grouped by path, into a single file. The first file rendered
+// for a path holds the merged contents and the rest are removed from their
+// templates. Keys in the existing file on disk that aren't set by any
+// fragment are preserved, unless a fragment rendered them in the previous
+// run.
+func (s *Stencil) mergeStructuredFiles(tpls []*Template) error {
+	paths := make([]string, 0)
+	fragments := make(map[string][]*File)
+	for _, t := range tpls {
+		for _, f := range t.Files {
+			if f.mergeStrategy == "" || f.Deleted || f.Skipped {
+				continue
+			}
+
+			if _, ok := fragments[f.path]; !ok {
+				paths = append(paths, f.path)
+			}
+			fragments[f.path] = append(fragments[f.path], f)
+		}
+	}
+
+	merged := make(map[*File]bool)
+	for _, path := range paths {
+		files := fragments[path]
+		if err := mergeStructuredFile(path, files, s.previousMergedKeys(path)); err != nil {
+			return errors.Wrapf(err, "failed to merge %q", path)
+		}
+
+		for _, f := range files[1:] {
+			merged[f] = true
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	for _, t := range tpls {
+		t.Files = slices.DeleteFunc(t.Files, func(f *File) bool { return merged[f] })
+	}
+	return nil
+}
+
+// previousMergedKeys returns the keys rendered into the merged structured
+// file at path in the previous run, according to the lockfile.
+func (s *Stencil) previousMergedKeys(path string) []string {
+	if s.lock == nil {
+		return nil
+	}
+
+	for _, f := range s.lock.Files {
+		if f.Name == path {
+			return f.Keys
+		}
+	}
+	return nil
+}
+
+// mergeStructuredFile merges the contents of files, all fragments of path,
+// with the existing file at path and stores the result in files[0]. The
+// previous keys, which the fragments rendered in the previous run, are
+// removed from the existing file first, so that only keys added by users
+// are preserved and keys and list items that are no longer rendered go
+// away.
+func mergeStructuredFile(path string, files []*File, previous []string) error {
+	strategy := files[0].mergeStrategy
+	codec, err := structuredCodecFor(path)
+	if err != nil {
+		return err
+	}
+
+	var out any
+	for _, f := range files {
+		if f.mergeStrategy != strategy {
+			return fmt.Errorf("%w: %q and %q", ErrMergeStrategyMismatch, strategy, f.mergeStrategy)
+		}
+
+		if strings.TrimSpace(f.String()) == "" {
+			continue
+		}
+
+		v, err := codec.decode(f.String())
+		if err != nil {
+			return errors.Wrap(err, "failed to decode fragment")
+		}
+
+		if out, err = mergeValues(out, normalizeValue(v), strategy, ""); err != nil {
+			return err
+		}
+	}
+
+	keys := structuredKeys(out, "", nil)
+	slices.Sort(keys)
+
+	// Merge the fragments onto the existing file to preserve any keys
+	// that were added to it. Conflicts with the existing file aren't
+	// errors, the fragments always win.
+	if existing, err := os.ReadFile(path); err == nil && len(bytes.TrimSpace(existing)) != 0 {
+		v, err := codec.decode(string(existing))
+		if err != nil {
+			return errors.Wrap(err, "failed to decode existing file")
+		}
+
+		if m, ok := v.(map[string]any); ok {
+			for _, key := range previous {
+				removeStructuredKey(m, splitJSONPointer(key))
+			}
+		}
+
+		existingStrategy := strategy
+		if existingStrategy == MergeStrategyError {
+			existingStrategy = MergeStrategyAppend
+		}
+		if out, err = mergeValues(normalizeValue(v), out, existingStrategy, ""); err != nil {
+			return err
+		}
+	}
+
+	contents, err := codec.encode(out)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode merged file")
+	}
+	files[0].SetContents(contents + "\n")
+	files[0].mergedKeys = keys
+	return nil
+}
+
+// structuredKeys appends the JSON pointers of the leaves of v, under
+// prefix, to keys. Lists and empty maps are leaves.
+func structuredKeys(v any, prefix string, keys []string) []string {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) == 0 {
+		if prefix == "" {
+			return keys
+		}
+		return append(keys, prefix)
+	}
+
+	for k, e := range m {
+		keys = structuredKeys(e, prefix+"/"+jsonPointerEscaper.Replace(k), keys)
+	}
+	return keys
+}
+
+// jsonPointerEscaper escapes a key for use in a JSON pointer.
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonPointerUnescaper reverses jsonPointerEscaper.
+var jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// splitJSONPointer returns the unescaped keys of the JSON pointer p.
+func splitJSONPointer(p string) []string {
+	if p == "" {
+		return nil
+	}
+
+	keys := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, k := range keys {
+		keys[i] = jsonPointerUnescaper.Replace(k)
+	}
+	return keys
+}
+
+// removeStructuredKey removes the key at path from m, along with any maps
+// left empty by removing it.
+func removeStructuredKey(m map[string]any, path []string) {
+	switch len(path) {
+	case 0:
+		return
+	case 1:
+		delete(m, path[0])
+		return
+	}
+
+	child, ok := m[path[0]].(map[string]any)
+	if !ok {
+		return
+	}
+	removeStructuredKey(child, path[1:])
+	if len(child) == 0 {
+		delete(m, path[0])
+	}
+}
+
+// mergeValues merges src onto dst using the provided strategy, key is the
+// dot-separated path to the values being merged and is used for errors.
+func mergeValues(dst, src any, strategy MergeStrategy, key string) (any, error) {
+	if dst == nil {
+		return src, nil
+	}
+	if src == nil {
+		return dst, nil
+	}
+
+	switch s := src.(type) {
+	case map[string]any:
+		d, ok := dst.(map[string]any)
+		if !ok {
+			return mergeScalar(dst, src, strategy, key)
+		}
+
+		out := make(map[string]any, len(d)+len(s))
+		for k, v := range d {
+			out[k] = v
+		}
+		for k, v := range s {
+			var err error
+			if out[k], err = mergeValues(d[k], v, strategy, strings.TrimPrefix(key+"."+k, ".")); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case []any:
+		d, ok := dst.([]any)
+		if !ok {
+			return mergeScalar(dst, src, strategy, key)
+		}
+		if strategy == MergeStrategyOverride {
+			return s, nil
+		}
+
+		out := slices.Clone(d)
+		for _, v := range s {
+			if !slices.ContainsFunc(out, func(o any) bool { return reflect.DeepEqual(o, v) }) {
+				out = append(out, v)
+			}
+		}
+		return out, nil
+	default:
+		return mergeScalar(dst, src, strategy, key)
+	}
+}
+
+// mergeScalar returns src, unless the strategy is MergeStrategyError and
+// dst is a different value.
+func mergeScalar(dst, src any, strategy MergeStrategy, key string) (any, error) {
+	if strategy == MergeStrategyError && !reflect.DeepEqual(dst, src) {
+		return nil, fmt.Errorf("%w %q: %v and %v", ErrStructuredFileConflict, key, dst, src)
+	}
+	return src, nil
+}
+
+// normalizeValue converts all of the slices in v into []any, the TOML
+// decoder returns typed slices, e.g. []map[string]any for arrays of tables.
+func normalizeValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			t[k] = normalizeValue(e)
+		}
+		return t
+	case []any:
+		for i, e := range t {
+			t[i] = normalizeValue(e)
+		}
+		return t
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return v
+	}
+
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = normalizeValue(rv.Index(i).Interface())
+	}
+	return out
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for merging structured files
+
+package codegen
+
+import (
+	"context"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getoutreach/stencil/internal/modules"
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+)
+
+func TestMergeValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy MergeStrategy
+		dst      any
+		src      any
+		want     any
+		wantErr  error
+	}{
+		{
+			name:     "append merges maps and appends lists",
+			strategy: MergeStrategyAppend,
+			dst:      map[string]any{"a": "1", "list": []any{"x"}, "nested": map[string]any{"b": "2"}},
+			src:      map[string]any{"a": "3", "list": []any{"x", "y"}, "nested": map[string]any{"c": "4"}},
+			want:     map[string]any{"a": "3", "list": []any{"x", "y"}, "nested": map[string]any{"b": "2", "c": "4"}},
+		},
+		{
+			name:     "override replaces lists",
+			strategy: MergeStrategyOverride,
+			dst:      map[string]any{"list": []any{"x"}},
+			src:      map[string]any{"list": []any{"y"}},
+			want:     map[string]any{"list": []any{"y"}},
+		},
+		{
+			name:     "error allows equal scalars",
+			strategy: MergeStrategyError,
+			dst:      map[string]any{"a": "1", "b": "2"},
+			src:      map[string]any{"a": "1", "c": "3"},
+			want:     map[string]any{"a": "1", "b": "2", "c": "3"},
+		},
+		{
+			name:     "error fails on conflicting scalars",
+			strategy: MergeStrategyError,
+			dst:      map[string]any{"nested": map[string]any{"a": "1"}},
+			src:      map[string]any{"nested": map[string]any{"a": "2"}},
+			wantErr:  ErrStructuredFileConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeValues(tt.dst, tt.src, tt.strategy, "")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestMergeStructuredFilesRender(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	t.Chdir(t.TempDir())
+
+	// The existing file has a key added by a user that should be preserved
+	assert.NilError(t, os.WriteFile("config.yaml", []byte("user: added\nlinters:\n  enable: [errcheck]\n"), 0o644))
+
+	m1fs := createFakeModuleFSWithManifest(t, "name: m1")
+	assert.NilError(t, util.WriteFile(m1fs, "templates/config.yaml.tpl",
+		[]byte("{{ $_ := file.Merge \"append\" }}linters:\n  enable: [errcheck, govet]\nrun:\n  timeout: 1m\n"), 0o644))
+	m2fs := createFakeModuleFSWithManifest(t, "name: m2")
+	assert.NilError(t, util.WriteFile(m2fs, "templates/golangci.tpl",
+		[]byte("{{ $_ := file.SetPath \"config.yaml\" }}{{ $_ := file.Merge \"append\" }}linters:\n  enable: [gosec]\nrun:\n  timeout: 5m\n"), 0o644))
+
+	st := NewStencil(&configuration.ServiceManifest{Name: "test"}, []*modules.Module{
+		modules.NewWithFS(ctx, "m1", m1fs),
+		modules.NewWithFS(ctx, "m2", m2fs),
+	}, log)
+
+	tpls, err := st.Render(ctx, log)
+	assert.NilError(t, err)
+	assert.Equal(t, len(tpls), 2)
+	assert.Equal(t, len(tpls[0].Files), 1, "expected the first template to hold the merged file")
+	assert.Equal(t, len(tpls[1].Files), 0, "expected the fragment to be removed from the second template")
+	assert.Equal(t, tpls[0].Files[0].String(), `linters:
+  enable:
+  - errcheck
+  - govet
+  - gosec
+run:
+  timeout: 5m
+user: added
+`)
+	assert.DeepEqual(t, st.GenerateLockfile(tpls).Files[0].Keys, []string{"/linters/enable", "/run/timeout"})
+}
+
+func TestMergeStructuredFilesRemovesPreviouslyRenderedKeys(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// The previous run rendered run.timeout, linters.enable and an
+	// annotation, the user added user and linters.disable
+	assert.NilError(t, os.WriteFile("config.yaml", []byte("user: added\nrun:\n  timeout: 1m\n"+
+		"linters:\n  enable: [errcheck, govet]\n  disable: [lll]\nmetadata:\n  a.b/c: x\n"), 0o644))
+
+	f, err := NewFile("config.yaml", 0o644, time.Now())
+	assert.NilError(t, err)
+	f.mergeStrategy = MergeStrategyAppend
+	f.SetContents("linters:\n  enable: [errcheck]\n")
+
+	previous := []string{"/linters/enable", "/metadata/a.b~1c", "/run/timeout"}
+	assert.NilError(t, mergeStructuredFile("config.yaml", []*File{f}, previous))
+	assert.Equal(t, f.String(), `linters:
+  disable:
+  - lll
+  enable:
+  - errcheck
+user: added
+`)
+	assert.DeepEqual(t, f.mergedKeys, []string{"/linters/enable"})
+}
+
+func TestStructuredKeys(t *testing.T) {
+	keys := structuredKeys(map[string]any{
+		"a":   map[string]any{"b": 1, "c": []any{1}},
+		"d/e": map[string]any{},
+		"f~g": "h",
+	}, "", nil)
+	slices.Sort(keys)
+	assert.DeepEqual(t, keys, []string{"/a/b", "/a/c", "/d~1e", "/f~0g"})
+
+	for _, key := range keys {
+		assert.Equal(t, "/"+strings.Join(escapeAll(splitJSONPointer(key)), "/"), key)
+	}
+}
+
+// escapeAll escapes each of keys for use in a JSON pointer.
+func escapeAll(keys []string) []string {
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = jsonPointerEscaper.Replace(k)
+	}
+	return out
+}
+
+func TestMergeStructuredFilesStrategyMismatch(t *testing.T) {
+	ctx := context.Background()
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	t.Chdir(t.TempDir())
+
+	fs := createFakeModuleFSWithManifest(t, "name: testing")
+	assert.NilError(t, util.WriteFile(fs, "templates/a.tpl",
+		[]byte(`{{ $_ := file.SetPath "package.json" }}{{ $_ := file.Merge "append" }}{"name": "a"}`), 0o644))
+	assert.NilError(t, util.WriteFile(fs, "templates/b.tpl",
+		[]byte(`{{ $_ := file.SetPath "package.json" }}{{ $_ := file.Merge "override" }}{"name": "b"}`), 0o644))
+
+	st := NewStencil(&configuration.ServiceManifest{Name: "test"}, []*modules.Module{
+		modules.NewWithFS(ctx, "testing", fs),
+	}, log)
+
+	_, err := st.Render(ctx, log)
+	assert.ErrorIs(t, err, ErrMergeStrategyMismatch)
+}
+
+func TestMergeStructuredFilesJSONDoesNotEscapeHTML(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	f1, err := NewFile("package.json", 0o644, time.Now())
+	assert.NilError(t, err)
+	f1.SetContents(`{"scripts": {"build": "tsc && webpack"}}`)
+	f1.mergeStrategy = MergeStrategyAppend
+	f2, err := NewFile("package.json", 0o644, time.Now())
+	assert.NilError(t, err)
+	f2.SetContents(`{"scripts": {"test": "jest <ci>"}}`)
+	f2.mergeStrategy = MergeStrategyAppend
+
+	assert.NilError(t, mergeStructuredFile("package.json", []*File{f1, f2}, nil))
+	assert.Equal(t, f1.String(), `{
+  "scripts": {
+    "build": "tsc && webpack",
+    "test": "jest <ci>"
+  }
+}
+`)
+}
+
+func TestMergeStructuredFilesMultipleYAMLDocuments(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	newFragment := func(contents string) *File {
+		f, err := NewFile("k8s.yaml", 0o644, time.Now())
+		assert.NilError(t, err)
+		f.mergeStrategy = MergeStrategyAppend
+		f.SetContents(contents)
+		return f
+	}
+
+	// A trailing separator is fine
+	f := newFragment("kind: Deployment\n---\n")
+	assert.NilError(t, mergeStructuredFile("k8s.yaml", []*File{f}, nil))
+	assert.Equal(t, f.String(), "kind: Deployment\n")
+
+	err := mergeStructuredFile("k8s.yaml", []*File{newFragment("kind: Deployment\n---\nkind: Service\n")}, nil)
+	assert.ErrorIs(t, err, ErrMultiDocumentStructuredFile)
+
+	assert.NilError(t, os.WriteFile("k8s.yaml", []byte("kind: Deployment\n---\nkind: Service\n"), 0o644))
+	err = mergeStructuredFile("k8s.yaml", []*File{newFragment("kind: Deployment\n")}, nil)
+	assert.ErrorIs(t, err, ErrMultiDocumentStructuredFile)
+}
+
+func TestMergeStructuredFilesTOML(t *testing.T) {
+	f1, err := NewFile("pyproject.toml", 0o644, time.Now())
+	assert.NilError(t, err)
+	f1.mergeStrategy = MergeStrategyAppend
+	f1.SetContents("[[tool.entries]]\nname = \"a\"\n")
+
+	f2, err := NewFile("pyproject.toml", 0o644, time.Now())
+	assert.NilError(t, err)
+	f2.mergeStrategy = MergeStrategyAppend
+	f2.SetContents("[[tool.entries]]\nname = \"b\"\n")
+
+	t.Chdir(t.TempDir())
+	assert.NilError(t, mergeStructuredFile("pyproject.toml", []*File{f1, f2}, nil))
+
+	v, err := fromTOML(f1.String())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, normalizeValue(v), map[string]any{
+		"tool": map[string]any{"entries": []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}}},
+	})
+}
@@ -21,6 +21,10 @@ type Template struct {
 	// parsed denotes if this template has been parsed or not
 	parsed bool
 
+	// asset denotes that this template is a non-templated asset, its
+	// contents are copied verbatim instead of being rendered
+	asset bool
+
 	// args are the arguments passed to the template
 	args *Values
 
@@ -35,6 +39,10 @@ type Template struct {
 	// for the default file if not modified during render time
 	modTime time.Time
 
+	// commentStyles are the comment styles declared by the module's
+	// manifest, these extend DefaultCommentStyles
+	commentStyles map[string]string
+
 	// Module is the underlying module that's creating this template
 	Module *modules.Module
 
@@ -62,6 +70,23 @@ func NewTemplate(m *modules.Module, fpath string, mode os.FileMode,
 	}, nil
 }
 
+// NewAsset creates a new Template for a non-templated asset, e.g. an image,
+// that is copied verbatim to fpath when rendered.
+func NewAsset(m *modules.Module, fpath string, mode os.FileMode,
+	modTime time.Time, contents []byte, log logrus.FieldLogger) (*Template, error) {
+	t, err := NewTemplate(m, fpath, mode, modTime, contents, log)
+	if err != nil {
+		return nil, err
+	}
+	t.asset = true
+	return t, nil
+}
+
+// IsAsset returns true if this template is a non-templated asset.
+func (t *Template) IsAsset() bool {
+	return t.asset
+}
+
 // ImportPath returns the path to this template, this is meant to denote
 // which module this template is attached to.
 func (t *Template) ImportPath() string {
@@ -71,6 +96,12 @@ func (t *Template) ImportPath() string {
 // Parse parses the provided template and makes it available to be Rendered
 // in the context of the current module.
 func (t *Template) Parse(st *Stencil) error {
+	// Assets aren't templates, so there's nothing to parse
+	if t.asset {
+		t.parsed = true
+		return nil
+	}
+
 	// Add the current template to the template object on the module that we're
 	// attached to. This enables us to call functions in other templates within our
 	// 'module context'.
@@ -87,8 +118,15 @@ func (t *Template) Parse(st *Stencil) error {
 // Render renders the provided template, the produced files
 // are rendered onto the Files field of the template struct.
 func (t *Template) Render(st *Stencil, vals *Values) error {
+	// Assets are copied verbatim. Blocks aren't parsed from the existing
+	// file since it's likely binary.
+	if t.asset {
+		t.Files = []*File{{path: t.Path, mode: t.mode, modTime: t.modTime, contents: t.Contents}}
+		return nil
+	}
+
 	if len(t.Files) == 0 {
-		f, err := NewFile(strings.TrimSuffix(t.Path, ".tpl"), t.mode, t.modTime)
+		f, err := newFile(strings.TrimSuffix(t.Path, ".tpl"), t.mode, t.modTime, t.commentStyles)
 		if err != nil {
 			return err
 		}
@@ -50,6 +50,37 @@ func TestSingleFileRender(t *testing.T) {
 	assert.Equal(t, tpl.Files[0].String(), "hello world!", "expected Render() to modify first created file")
 }
 
+func TestSetExecutableRender(t *testing.T) {
+	m := modules.NewWithFS(context.Background(), "testing", memfs.New())
+
+	log := logrus.New()
+	tpl, err := NewTemplate(m, "script.sh.tpl", 0o644, time.Now(), []byte("{{ $_ := file.SetExecutable }}"), log)
+	assert.NilError(t, err, "failed to create basic template")
+
+	sm := &configuration.ServiceManifest{Name: "testing"}
+
+	st := NewStencil(sm, []*modules.Module{m}, log)
+	err = tpl.Render(st, NewValues(context.Background(), sm, nil, log))
+	assert.NilError(t, err, "expected Render() to not fail")
+	assert.Equal(t, tpl.Files[0].String(), "", "expected SetExecutable to not output anything")
+	assert.Equal(t, tpl.Files[0].Mode(), os.FileMode(0o755), "expected SetExecutable to add execute bits")
+}
+
+func TestSetModeRender(t *testing.T) {
+	m := modules.NewWithFS(context.Background(), "testing", memfs.New())
+
+	log := logrus.New()
+	tpl, err := NewTemplate(m, "secret.txt.tpl", 0o644, time.Now(), []byte("{{ $_ := file.SetMode 0600 }}"), log)
+	assert.NilError(t, err, "failed to create basic template")
+
+	sm := &configuration.ServiceManifest{Name: "testing"}
+
+	st := NewStencil(sm, []*modules.Module{m}, log)
+	err = tpl.Render(st, NewValues(context.Background(), sm, nil, log))
+	assert.NilError(t, err, "expected Render() to not fail")
+	assert.Equal(t, tpl.Files[0].Mode(), os.FileMode(0o600), "expected SetMode to change the mode")
+}
+
 func TestMultiFileRender(t *testing.T) {
 	fs := createFakeModuleFSWithManifest(t, "name: testing\narguments:\n  commands:\n    type: list")
 
@@ -0,0 +1,7 @@
+(ns app.core)
+
+;; <<Stencil::Block(deps)>>
+(require '[clojure.string :as str])
+;; <</Stencil::Block>>
+
+/* <<Stencil::Block(notABlock)>> */
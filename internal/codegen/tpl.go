@@ -24,7 +24,7 @@ func NewFuncMap(st *Stencil, t *Template, log logrus.FieldLogger) template.FuncM
 		tplst = &TplStencil{st, t, log}
 	}
 	if t != nil {
-		tplf = &TplFile{t.Files[0], t, log}
+		tplf = &TplFile{t.Files[0], t, st, log}
 	}
 
 	// build the function map
@@ -6,9 +6,12 @@
 package codegen
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/getoutreach/stencil/pkg/configuration"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,6 +27,9 @@ type TplFile struct {
 	// t is the current template
 	t *Template
 
+	// s is the stencil renderer the template is being rendered by
+	s *Stencil
+
 	// log is the logger to use for debugging
 	log logrus.FieldLogger
 }
@@ -56,10 +62,111 @@ func (f *TplFile) Block(name string) string {
 // Note: The $_ is required to ensure <nil> isn't outputted into
 // the template.
 func (f *TplFile) SetPath(path string) (out, err error) {
+	if err := f.checkPath(path); err != nil {
+		return err, err
+	}
+
 	err = f.f.SetPath(path)
 	return err, err
 }
 
+// Symlink turns the current file being rendered into a symlink that
+// points to target. Relative targets are relative to the directory of the
+// file, and the rendered contents of the file are ignored.
+//
+//	{{ $_ := file.Symlink "../shared/Makefile" }}
+func (f *TplFile) Symlink(target string) (out, err error) {
+	resolved := target
+	if !filepath.IsAbs(target) {
+		resolved = filepath.Join(filepath.Dir(f.f.path), target)
+	}
+	if err := f.checkPath(resolved); err != nil {
+		return err, err
+	}
+
+	f.f.SetSymlink(target)
+	return nil, nil
+}
+
+// Merge marks the current file being rendered as a fragment of a
+// structured (YAML, JSON or TOML) file. Every template that merges into
+// the same path contributes a fragment, and the fragments are deep-merged,
+// in render order, with the provided strategy. The "append" strategy
+// appends to lists and overrides scalars, "override" overrides both lists
+// and scalars, and "error" appends to lists but fails if fragments set a
+// scalar to different values.
+//
+// Keys in the existing file that aren't set by any fragment, e.g. ones
+// added by a user, are preserved. The keys rendered by the fragments are
+// recorded in stencil.lock, and ones that a later run no longer renders
+// are removed from the file, as are list items that are no longer
+// rendered, since lists are owned by the fragments rendering them. Until
+// a run has recorded the rendered keys, every existing key is kept.
+//
+// YAML files, and their fragments, must contain a single document,
+// merging fails otherwise instead of dropping the other documents.
+//
+//	{{ $_ := file.SetPath ".golangci.yml" }}
+//	{{ $_ := file.Merge "append" }}
+//	linters:
+//	  enable:
+//	    - errcheck
+func (f *TplFile) Merge(strategy string) (out, err error) {
+	if !MergeStrategy(strategy).IsValid() {
+		err := fmt.Errorf("%w: %q", ErrUnknownMergeStrategy, strategy)
+		return err, err
+	}
+
+	f.f.mergeStrategy = MergeStrategy(strategy)
+	return nil, nil
+}
+
+// PreserveKey marks a key of the current file being rendered, which must
+// be a YAML or JSON file, as user-owned. If the key is set in the existing
+// file its value, including any comments, replaces the rendered value.
+// Keys are dot-separated paths, with lists indexed by number. In files
+// with multiple YAML documents, keys are preserved between the documents
+// at the same position.
+//
+// This is an alternative to blocks for files that don't support comments,
+// or where blocks would break indentation.
+//
+//	{{ $_ := file.PreserveKey "spec.replicas" }}
+//	{{ $_ := file.PreserveKey "scripts.test" }}
+func (f *TplFile) PreserveKey(key string) (out, err error) {
+	f.f.preservedKeys = append(f.f.preservedKeys, key)
+	return nil, nil
+}
+
+// SetCommentStyle sets the comment prefix that block tags can be written
+// after in the current file being rendered, in addition to the default
+// "//", "##", "--" and "<!--" prefixes. This overrides the prefix for the
+// file's extension, from the module's manifest or the defaults, and must
+// be called before file.Block.
+//
+//	{{ $_ := file.SetCommentStyle ";;" }}
+//	;; <<Stencil::Block(deps)>>
+//	{{ file.Block "deps" }}
+//	;; <</Stencil::Block>>
+func (f *TplFile) SetCommentStyle(prefix string) (out, err error) {
+	err = f.f.SetCommentStyle(prefix)
+	return err, err
+}
+
+// RenameBlock carries the contents of a block that was renamed, in the
+// existing file, over to its new name. This must be called before
+// file.Block is called with the new name. Without it, the contents of
+// the old block are orphaned.
+//
+//	{{ $_ := file.RenameBlock "imports" "extraImports" }}
+//	// <<Stencil::Block(extraImports)>>
+//	{{ file.Block "extraImports" }}
+//	// <</Stencil::Block>>
+func (f *TplFile) RenameBlock(oldName, newName string) (out, err error) {
+	f.f.RenameBlock(oldName, newName)
+	return nil, nil
+}
+
 // SetContents sets the contents of file being rendered to the value
 //
 // This is useful for programmatic file generation within a template.
@@ -70,6 +177,26 @@ func (f *TplFile) SetContents(contents string) error {
 	return nil
 }
 
+// SetMode sets the file mode of the current file being rendered. The
+// mode is also applied to the file if it already exists on disk.
+//
+//	{{ $_ := file.SetMode 0600 }}
+func (f *TplFile) SetMode(mode os.FileMode) error {
+	f.f.SetMode(mode)
+	return nil
+}
+
+// SetExecutable marks the current file being rendered as executable by
+// adding the execute bit for everyone that can read it, e.g. 0644 becomes
+// 0755.
+//
+//	{{ $_ := file.SetExecutable }}
+func (f *TplFile) SetExecutable() error {
+	mode := f.f.Mode()
+	f.f.SetMode(mode | (mode&0o444)>>2)
+	return nil
+}
+
 // Skip skips the current file being rendered
 //
 //	{{ $_ := file.Skip "A reason to skip this reason" }}
@@ -137,7 +264,11 @@ func (f *TplFile) Path() string {
 //	{{- stencil.ApplyTemplate "command" | file.SetContents }}
 //	{{- end }}
 func (f *TplFile) Create(path string, mode os.FileMode, modTime time.Time) (out, err error) {
-	f.f, err = NewFile(path, mode, modTime)
+	if err := f.checkPath(path); err != nil {
+		return err, err
+	}
+
+	f.f, err = newFile(path, mode, modTime, f.t.commentStyles)
 	if err != nil {
 		return err, err
 	}
@@ -150,8 +281,26 @@ func (f *TplFile) Create(path string, mode os.FileMode, modTime time.Time) (out,
 //
 //	{{ file.RemoveAll "path" }}
 func (f *TplFile) RemoveAll(path string) (out, err error) {
+	if err := f.s.checkCapability(f.t.Module.Name, configuration.CapabilityDeleteFiles); err != nil {
+		return err, err
+	}
+	if err := f.checkPath(path); err != nil {
+		return err, err
+	}
+
 	if err := os.RemoveAll(path); err != nil {
 		return err, err
 	}
 	return nil, nil
 }
+
+// checkPath ensures that the current module is allowed to write to the
+// provided path, paths outside of the project require the
+// writeOutsideProject capability.
+func (f *TplFile) checkPath(path string) error {
+	if filepath.IsLocal(path) {
+		return nil
+	}
+
+	return f.s.checkCapability(f.t.Module.Name, configuration.CapabilityWriteOutsideProject)
+}
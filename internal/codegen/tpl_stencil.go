@@ -51,9 +51,16 @@ func (s *TplStencil) GetModuleHook(name string) []any {
 	k := s.s.sharedData.key(s.t.Module.Name, name)
 	v := s.s.sharedData.moduleHooks[k]
 	if v == nil {
+		if tr := s.trace(); tr != nil {
+			tr.ModuleHooks = append(tr.ModuleHooks, ModuleHookRead{Name: k, Values: []any{}})
+		}
+
 		// No data, return nothing
 		return []any{}
 	}
+	if tr := s.trace(); tr != nil {
+		tr.ModuleHooks = append(tr.ModuleHooks, ModuleHookRead{Name: k, Values: traceValues(v.values)})
+	}
 
 	s.log.WithField("template", s.t.ImportPath()).WithField("path", k).
 		WithField("data", spew.Sdump(v)).Debug("getting module hook")
@@ -101,7 +108,12 @@ func (s *TplStencil) SetGlobal(name string, data any) error {
 func (s *TplStencil) GetGlobal(name string) any {
 	k := s.s.sharedData.key(s.t.Module.Name, name)
 
-	if v, ok := s.s.sharedData.globals[k]; ok {
+	v, ok := s.s.sharedData.globals[k]
+	if tr := s.trace(); tr != nil {
+		tr.Globals = append(tr.Globals, GlobalRead{Name: k, Value: traceValue(v.value), SetBy: v.template})
+	}
+
+	if ok {
 		s.log.WithField("template", s.t.ImportPath()).WithField("path", k).
 			WithField("data", spew.Sdump(v)).WithField("definingTemplate", v.template).
 			Debug("retrieved data from global store")
@@ -279,7 +291,12 @@ func (s *TplStencil) ReadBlocks(fpath string) (map[string]string, error) {
 		return nil, err
 	}
 
-	data, err := parseBlocks(fpath)
+	var commentStyles map[string]string
+	if s.t != nil {
+		commentStyles = s.t.commentStyles
+	}
+
+	data, err := parseBlocks(fpath, CommentStyleFor(fpath, commentStyles))
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +60,23 @@ func (s *TplStencil) Arg(pth string) (any, error) {
 		return s.Args(), nil
 	}
 
+	read := ArgRead{Name: pth}
+	v, err := s.arg(pth, &read)
+	if tr := s.trace(); tr != nil {
+		if err != nil {
+			read.Source = ""
+			read.Error = err.Error()
+		} else {
+			read.Value = traceValue(v)
+		}
+		tr.Args = append(tr.Args, read)
+	}
+	return v, err
+}
+
+// arg implements Arg, setting the source of the value and the module the
+// argument was declared by on read.
+func (s *TplStencil) arg(pth string, read *ArgRead) (any, error) {
 	// This is a TODO because I don't know if template functions
 	// can even get a context passed to them
 	ctx := context.TODO()
@@ -80,6 +97,7 @@ func (s *TplStencil) Arg(pth string) (any, error) {
 	// If there's a "from" we should handle that now before anything else,
 	// so that its definition is used.
 	if arg.From != "" {
+		read.From = arg.From
 		fromArg, err := s.resolveFrom(ctx, pth, &arg)
 		if err != nil {
 			return "", err
@@ -94,8 +112,13 @@ func (s *TplStencil) Arg(pth string) (any, error) {
 	}
 
 	// if not set then we return a default value based on the denoted type
+	read.Source = ArgSourceServiceManifest
 	v, err := dotnotation.Get(mapInf, pth)
 	if err != nil {
+		read.Source = ArgSourceDefault
+		if arg.Default == nil {
+			read.Source = ArgSourceZero
+		}
 		v, err = s.resolveDefault(pth, &arg)
 		if err != nil {
 			// No type information means there is no default value to
@@ -219,22 +242,9 @@ func (s *TplStencil) resolveFrom(ctx context.Context, pth string, arg *configura
 
 // validateArg validates an argument against the schema.
 func (s *TplStencil) validateArg(pth string, arg *configuration.Argument, v any) error {
-	schemaBuf := new(bytes.Buffer)
-	if err := json.NewEncoder(schemaBuf).Encode(arg.Schema); err != nil {
-		return errors.Wrap(err, "failed to encode schema into JSON")
-	}
-
-	jsc := jsonschema.NewCompiler()
-	jsc.Draft = jsonschema.Draft2020
-
-	schemaURL := "manifest.yaml/arguments/" + pth
-	if err := jsc.AddResource(schemaURL, schemaBuf); err != nil {
-		return errors.Wrapf(err, "failed to add argument '%s' json schema to compiler", pth)
-	}
-
-	schema, err := jsc.Compile(schemaURL)
+	schema, err := compileArgSchema(pth, arg)
 	if err != nil {
-		return errors.Wrapf(err, "failed to compile argument '%s' schema", pth)
+		return err
 	}
 
 	if err := schema.Validate(v); err != nil {
@@ -257,6 +267,28 @@ func (s *TplStencil) validateArg(pth string, arg *configuration.Argument, v any)
 	return nil
 }
 
+// compileArgSchema compiles the JSON schema of the argument at pth.
+func compileArgSchema(pth string, arg *configuration.Argument) (*jsonschema.Schema, error) {
+	schemaBuf := new(bytes.Buffer)
+	if err := json.NewEncoder(schemaBuf).Encode(arg.Schema); err != nil {
+		return nil, errors.Wrap(err, "failed to encode schema into JSON")
+	}
+
+	jsc := jsonschema.NewCompiler()
+	jsc.Draft = jsonschema.Draft2020
+
+	schemaURL := "manifest.yaml/arguments/" + pth
+	if err := jsc.AddResource(schemaURL, schemaBuf); err != nil {
+		return nil, errors.Wrapf(err, "failed to add argument '%s' json schema to compiler", pth)
+	}
+
+	schema, err := jsc.Compile(schemaURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compile argument '%s' schema", pth)
+	}
+	return schema, nil
+}
+
 // buildErrorPath builds an error path from the provided absoluteKeywordLocation from jsonschema errors.
 func buildErrorPath(absoluteKeywordLocation string) (string, error) {
 	// Splits on manifest to retrieve only the path declared inside the manifest file.
@@ -8,13 +8,19 @@ package codegen
 
 import (
 	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/getoutreach/gobox/pkg/app"
 	"github.com/getoutreach/gobox/pkg/box"
 	stencilgit "github.com/getoutreach/stencil/internal/git"
 	"github.com/getoutreach/stencil/internal/modules"
 	"github.com/getoutreach/stencil/pkg/configuration"
 	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/sirupsen/logrus"
 )
 
@@ -58,6 +64,36 @@ type git struct {
 	// generally this is equal to "main", but some repositories
 	// use other values.
 	DefaultBranch string
+
+	// AuthorDate is the author date of the current commit
+	AuthorDate time.Time
+
+	// Remote is the origin remote of the repository
+	Remote remote
+
+	// Tags are the names of the tags in the repository, sorted
+	Tags []string
+
+	// LatestTag is the tag with the highest semantic version, excluding
+	// prereleases, e.g. "v1.2.3". Empty if there are none.
+	LatestTag string
+}
+
+// remote contains information about a git remote.
+type remote struct {
+	// URL is the URL of the remote, as configured
+	URL string
+
+	// Host is the host of the remote, e.g. github.com
+	Host string
+
+	// Owner is the owner of the repository on the remote, the
+	// organization or user on GitHub. For nested groups, e.g. on GitLab,
+	// this is every group separated by a slash.
+	Owner string
+
+	// Repo is the name of the repository on the remote, without .git
+	Repo string
 }
 
 // config contains a small amount of configuration that
@@ -119,6 +155,10 @@ type Values struct {
 
 	// Template is the name of the template being rendered
 	Template stencilTemplate
+
+	// Project is information about the project being rendered, detected
+	// from its files
+	Project project
 }
 
 // NewValues returns a fully initialized Values
@@ -137,6 +177,7 @@ func NewValues(ctx context.Context, sm *configuration.ServiceManifest, mods []*m
 		},
 		Module:   module{},
 		Template: stencilTemplate{},
+		Project:  detectProject("."),
 	}
 
 	for _, m := range mods {
@@ -154,10 +195,12 @@ func NewValues(ctx context.Context, sm *configuration.ServiceManifest, mods []*m
 
 	// If we're a repository, add repository information
 	if r, err := gogit.PlainOpen(""); err == nil {
-		db, err := stencilgit.GetDefaultBranch(ctx, "")
-		if err != nil {
-			log.Warnf("Failed to get default branch, defaulting to 'main': %v", err)
-			db = "main"
+		db := sm.DefaultBranch
+		if db == "" {
+			if db, err = stencilgit.GetDefaultBranch(ctx, ""); err != nil {
+				log.Warnf("Failed to get default branch, defaulting to 'main': %v", err)
+				db = "main"
+			}
 		}
 		vals.Git.DefaultBranch = db
 
@@ -165,8 +208,17 @@ func NewValues(ctx context.Context, sm *configuration.ServiceManifest, mods []*m
 		if pref, err := r.Head(); err == nil {
 			vals.Git.Ref = pref.Name().String()
 			vals.Git.Commit = pref.Hash().String()
+			if c, err := r.CommitObject(pref.Hash()); err == nil {
+				vals.Git.AuthorDate = c.Author.When
+			}
 		}
 
+		if rem, err := r.Remote("origin"); err == nil && len(rem.Config().URLs) > 0 {
+			vals.Git.Remote = parseRemoteURL(rem.Config().URLs[0])
+		}
+
+		vals.Git.Tags, vals.Git.LatestTag = repositoryTags(r)
+
 		// Check if the worktree is clean
 		if wrk, err := r.Worktree(); err == nil {
 			if stat, err := wrk.Status(); err == nil {
@@ -200,3 +252,56 @@ func (v *Values) WithTemplate(name string) *Values {
 	nv.Template.Name = name
 	return nv
 }
+
+// parseRemoteURL returns the information about the remote with the
+// provided URL, in either the URL or the scp-like (git@host:owner/repo)
+// syntax. Only the URL is set if it can't be parsed.
+func parseRemoteURL(rawURL string) remote {
+	r := remote{URL: rawURL}
+
+	var host, path string
+	if u, err := url.Parse(rawURL); err == nil && u.Scheme != "" && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if before, after, ok := strings.Cut(rawURL, ":"); ok && !strings.Contains(before, "/") {
+		// scp-like syntax, e.g. git@github.com:getoutreach/stencil.git
+		host, path = before[strings.LastIndex(before, "@")+1:], after
+	} else {
+		return r
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return r
+	}
+
+	r.Host, r.Owner, r.Repo = host, path[:i], path[i+1:]
+	return r
+}
+
+// repositoryTags returns the sorted names of the tags in r, and the tag
+// with the highest semantic version, excluding prereleases.
+func repositoryTags(r *gogit.Repository) (tags []string, latest string) {
+	iter, err := r.Tags()
+	if err != nil {
+		return nil, ""
+	}
+
+	var latestVersion *semver.Version
+	//nolint:errcheck // Why: the callback never returns an error
+	iter.ForEach(func(ref *plumbing.Reference) error {
+		tag := ref.Name().Short()
+		tags = append(tags, tag)
+
+		v, err := semver.NewVersion(tag)
+		if err != nil || v.Prerelease() != "" {
+			return nil
+		}
+		if latestVersion == nil || v.GreaterThan(latestVersion) {
+			latestVersion, latest = v, tag
+		}
+		return nil
+	})
+	sort.Strings(tags)
+	return tags, latest
+}
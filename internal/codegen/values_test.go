@@ -7,6 +7,7 @@ package codegen
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -16,8 +17,10 @@ import (
 	"github.com/getoutreach/stencil/internal/modules/modulestest"
 	"github.com/getoutreach/stencil/pkg/configuration"
 	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-cmp/cmp"
 	"github.com/sirupsen/logrus"
 	"gotest.tools/v3/assert"
 )
@@ -40,16 +43,31 @@ func TestValues(t *testing.T) {
 	wrk, err := r.Worktree()
 	assert.NilError(t, err, "expected gogit.(Repository).Worktree() not to fail")
 
+	assert.NilError(t, os.WriteFile("go.mod", []byte("module github.com/getoutreach/stencil\n"), 0o644))
+	_, err = wrk.Add("go.mod")
+	assert.NilError(t, err, "expected worktree.Add() not to fail")
+
+	authorDate := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
 	cmt, err := wrk.Commit("initial commit", &gogit.CommitOptions{
-		AllowEmptyCommits: true,
 		Author: &object.Signature{
 			Name:  "Stencil",
 			Email: "email@example.com",
-			When:  time.Now(),
+			When:  authorDate,
 		},
 	})
 	assert.NilError(t, err, "expected worktree.Commit() not to fail")
 
+	for _, tag := range []string{"v1.0.0", "v1.2.0", "v2.0.0-rc.1", "latest"} {
+		_, err := r.CreateTag(tag, cmt, nil)
+		assert.NilError(t, err, "expected gogit.(Repository).CreateTag() not to fail")
+	}
+
+	_, err = r.CreateRemote(&gitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"git@github.com:getoutreach/stencil.git"},
+	})
+	assert.NilError(t, err, "expected gogit.(Repository).CreateRemote() not to fail")
+
 	err = wrk.Checkout(&gogit.CheckoutOptions{
 		Create: true,
 		Branch: plumbing.NewBranchReferenceName("main"),
@@ -57,7 +75,8 @@ func TestValues(t *testing.T) {
 	assert.NilError(t, err, "expected worktree.Checkout() not to fail")
 
 	sm := &configuration.ServiceManifest{
-		Name: "testing",
+		Name:          "testing",
+		DefaultBranch: "main",
 	}
 
 	boxConf, _ := box.LoadBox()
@@ -74,6 +93,15 @@ func TestValues(t *testing.T) {
 			Commit:        cmt.String(),
 			Dirty:         false,
 			DefaultBranch: "main",
+			AuthorDate:    authorDate,
+			Remote: remote{
+				URL:   "git@github.com:getoutreach/stencil.git",
+				Host:  "github.com",
+				Owner: "getoutreach",
+				Repo:  "stencil",
+			},
+			Tags:      []string{"latest", "v1.0.0", "v1.2.0", "v2.0.0-rc.1"},
+			LatestTag: "v1.2.0",
 		},
 		Runtime: runtime{
 			Generator:        app.Info().Name,
@@ -89,9 +117,63 @@ func TestValues(t *testing.T) {
 		Config: config{
 			Name: sm.Name,
 		},
+		Project: project{
+			GoModule:        "github.com/getoutreach/stencil",
+			Languages:       []string{"go"},
+			PackageManagers: []string{"go"},
+		},
 	}, vals)
 }
 
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want remote
+	}{
+		{
+			url:  "https://github.com/getoutreach/stencil.git",
+			want: remote{Host: "github.com", Owner: "getoutreach", Repo: "stencil"},
+		},
+		{
+			url:  "git@github.com:getoutreach/stencil",
+			want: remote{Host: "github.com", Owner: "getoutreach", Repo: "stencil"},
+		},
+		{
+			url:  "ssh://git@gitlab.example.com:2222/group/subgroup/project.git",
+			want: remote{Host: "gitlab.example.com", Owner: "group/subgroup", Repo: "project"},
+		},
+		{
+			url:  "/tmp/repo",
+			want: remote{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			tt.want.URL = tt.url
+			assert.DeepEqual(t, parseRemoteURL(tt.url), tt.want, cmp.AllowUnexported(remote{}))
+		})
+	}
+}
+
+func TestDetectProject(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"go.mod":        "// the module\nmodule \"example.com/foo\" // comment\n\ngo 1.22\n",
+		"package.json":  "{}",
+		"tsconfig.json": "{}",
+		"yarn.lock":     "",
+	} {
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644))
+	}
+
+	assert.DeepEqual(t, detectProject(dir), project{
+		GoModule:        "example.com/foo",
+		Languages:       []string{"go", "javascript", "typescript"},
+		PackageManagers: []string{"go", "yarn"},
+	}, cmp.AllowUnexported(project{}))
+	assert.DeepEqual(t, detectProject(t.TempDir()), project{}, cmp.AllowUnexported(project{}))
+}
+
 func TestGeneratedValues(t *testing.T) {
 	log := logrus.New()
 
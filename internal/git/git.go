@@ -6,15 +6,21 @@
 package git
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"os/exec"
-	"regexp"
+	"strings"
 
+	giturls "github.com/chainguard-dev/git-urls"
+	"github.com/getoutreach/gobox/pkg/cli/github"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/pkg/errors"
 )
 
-// This block contains errors and regexes.
+// This block contains errors.
 var (
 	// ErrNoHeadBranch is returned when a repository's HEAD (aka default) branch cannot
 	// be determine.
@@ -24,27 +30,137 @@ var (
 	// cannot be determined.
 	ErrNoRemoteHeadBranch = errors.New("failed to get head branch from remote origin")
 
-	// headPattern is used to parse git output to determine the head branch.
-	headPattern = regexp.MustCompile(`HEAD branch: ([[:alpha:]]+)`)
+	// ErrDirtyWorktree is returned when a repository has uncommitted
+	// changes.
+	ErrDirtyWorktree = errors.New("git worktree has uncommitted changes")
 )
 
 // GetDefaultBranch determines the default/HEAD branch for a given git
-// repository.
+// repository, from the remote origin's HEAD. The locally known HEAD,
+// refs/remotes/origin/HEAD, is used if it's set, otherwise the remote
+// is asked for it. The GitHub token, if there is one, is used to
+// authenticate to GitHub over HTTPS, and SSH remotes use the SSH agent.
 func GetDefaultBranch(ctx context.Context, path string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "remote", "show", "origin")
-	cmd.Dir = path
-	env := os.Environ()
-	env = append(env, "LC_ALL=C")
-	cmd.Env = env
+	if path == "" {
+		path = "."
+	}
+
+	r, err := gogit.PlainOpenWithOptions(path, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open git repository")
+	}
+
+	remoteHead := plumbing.NewRemoteHEADReferenceName("origin")
+	if ref, err := r.Reference(remoteHead, false); err == nil && ref.Type() == plumbing.SymbolicReference {
+		if branch, ok := strings.CutPrefix(ref.Target().String(), "refs/remotes/origin/"); ok {
+			return branch, nil
+		}
+	}
+
+	remote, err := r.Remote("origin")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get remote origin")
+	}
+
+	opts := &gogit.ListOptions{}
+	if isGitHubHTTPS(remote.Config().URLs) {
+		if token, err := github.GetToken(); err == nil {
+			opts.Auth = &githttp.BasicAuth{
+				Username: "x-access-token",
+				Password: string(token),
+			}
+		}
+	}
+
+	refs, err := remote.ListContext(ctx, opts)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list references of remote origin")
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch() {
+			return ref.Target().Short(), nil
+		}
+	}
+	return "", ErrNoRemoteHeadBranch
+}
+
+// isGitHubHTTPS returns true if urls, the URLs of a remote, are for
+// GitHub over HTTPS, so that the GitHub token is never sent to another
+// host.
+func isGitHubHTTPS(urls []string) bool {
+	if len(urls) == 0 {
+		return false
+	}
+
+	u, err := giturls.Parse(urls[0])
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "https" && u.Hostname() == "github.com"
+}
+
+// run runs git with the provided arguments in dir and returns its
+// trimmed output.
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 	out, err := cmd.Output()
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get head branch from remote origin")
+		return "", errors.Wrapf(err, "git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// IsClean returns true if the git repository at path has no uncommitted
+// changes, including untracked files that aren't ignored.
+func IsClean(ctx context.Context, path string) (bool, error) {
+	out, err := run(ctx, path, "status", "--porcelain")
+	if err != nil {
+		return false, err
 	}
+	return out == "", nil
+}
 
-	matches := headPattern.FindStringSubmatch(string(out))
-	if len(matches) != 2 {
-		return "", ErrNoRemoteHeadBranch
+// CurrentBranch returns the name of the branch checked out in the git
+// repository at path, or the commit if HEAD is detached.
+func CurrentBranch(ctx context.Context, path string) (string, error) {
+	if branch, err := run(ctx, path, "symbolic-ref", "--short", "-q", "HEAD"); err == nil && branch != "" {
+		return branch, nil
 	}
+	return run(ctx, path, "rev-parse", "HEAD")
+}
 
-	return matches[1], nil
+// CreateBranch creates a branch with the provided name at HEAD, in the git
+// repository at path, and checks it out. Uncommitted changes are kept.
+func CreateBranch(ctx context.Context, path, name string) error {
+	_, err := run(ctx, path, "checkout", "-q", "-b", name)
+	return err
+}
+
+// Checkout checks out ref in the git repository at path. Uncommitted
+// changes are kept.
+func Checkout(ctx context.Context, path, ref string) error {
+	_, err := run(ctx, path, "checkout", "-q", ref)
+	return err
+}
+
+// DeleteBranch force deletes the branch with the provided name in the git
+// repository at path.
+func DeleteBranch(ctx context.Context, path, name string) error {
+	_, err := run(ctx, path, "branch", "-q", "-D", name)
+	return err
+}
+
+// CommitAll stages every change in the git repository at path, including
+// untracked files, and commits them with the provided message.
+func CommitAll(ctx context.Context, path, message string) error {
+	if _, err := run(ctx, path, "add", "-A"); err != nil {
+		return err
+	}
+	_, err := run(ctx, path, "commit", "-q", "-m", message)
+	return err
 }
@@ -12,6 +12,7 @@ import (
 
 	stgit "github.com/getoutreach/stencil/internal/git"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"gotest.tools/v3/assert"
@@ -69,3 +70,90 @@ func TestGetDefaultBranchMaster(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Equal(t, defaultBranch, "master", "Expected default branch to be 'master'")
 }
+
+func TestGetDefaultBranchWithoutLocalRemoteHead(t *testing.T) {
+	ctx := context.Background()
+
+	repoDir := t.TempDir()
+	repo, err := git.PlainCloneContext(ctx, repoDir, false, &git.CloneOptions{
+		URL: createTempGitRepo(t, plumbing.Main),
+	})
+	assert.NilError(t, err)
+
+	// Make sure the remote is asked for its HEAD
+	assert.NilError(t, repo.Storer.RemoveReference(plumbing.NewRemoteHEADReferenceName("origin")))
+
+	defaultBranch, err := stgit.GetDefaultBranch(ctx, repoDir)
+	assert.NilError(t, err)
+	assert.Equal(t, defaultBranch, "main")
+}
+
+func TestCommitAll(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("GIT_AUTHOR_NAME", "Test Author")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test Author")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repoDir := createTempGitRepo(t, plumbing.Main)
+	clean, err := stgit.IsClean(ctx, repoDir)
+	assert.NilError(t, err)
+	assert.Assert(t, clean)
+
+	assert.NilError(t, os.WriteFile(filepath.Join(repoDir, "new.txt"), []byte("new"), 0o644))
+	clean, err = stgit.IsClean(ctx, repoDir)
+	assert.NilError(t, err)
+	assert.Assert(t, !clean)
+
+	assert.NilError(t, stgit.CreateBranch(ctx, repoDir, "stencil/update"))
+	branch, err := stgit.CurrentBranch(ctx, repoDir)
+	assert.NilError(t, err)
+	assert.Equal(t, branch, "stencil/update")
+
+	assert.NilError(t, stgit.CommitAll(ctx, repoDir, "chore: update"))
+	clean, err = stgit.IsClean(ctx, repoDir)
+	assert.NilError(t, err)
+	assert.Assert(t, clean)
+
+	assert.NilError(t, stgit.Checkout(ctx, repoDir, "main"))
+	_, err = os.Stat(filepath.Join(repoDir, "new.txt"))
+	assert.Assert(t, os.IsNotExist(err))
+	assert.NilError(t, stgit.DeleteBranch(ctx, repoDir, "stencil/update"))
+}
+
+func TestGetDefaultBranchNonAlphabetic(t *testing.T) {
+	ctx := context.Background()
+
+	repoDir := t.TempDir()
+	_, err := git.PlainCloneContext(ctx, repoDir, false, &git.CloneOptions{
+		URL: createTempGitRepo(t, plumbing.NewBranchReferenceName("release-1.x")),
+	})
+	assert.NilError(t, err)
+
+	defaultBranch, err := stgit.GetDefaultBranch(ctx, repoDir)
+	assert.NilError(t, err)
+	assert.Equal(t, defaultBranch, "release-1.x")
+}
+
+// Makes sure that a locally known remote HEAD is used without contacting
+// the remote.
+func TestGetDefaultBranchLocalRemoteHead(t *testing.T) {
+	ctx := context.Background()
+
+	repoDir := createTempGitRepo(t, plumbing.Main)
+	r, err := git.PlainOpen(repoDir)
+	assert.NilError(t, err)
+	_, err = r.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{filepath.Join(t.TempDir(), "does-not-exist")},
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, r.Storer.SetReference(plumbing.NewSymbolicReference(
+		plumbing.NewRemoteHEADReferenceName("origin"),
+		plumbing.NewRemoteReferenceName("origin", "dev_main"),
+	)))
+
+	defaultBranch, err := stgit.GetDefaultBranch(ctx, repoDir)
+	assert.NilError(t, err)
+	assert.Equal(t, defaultBranch, "dev_main")
+}
@@ -121,6 +121,7 @@ func Validate(res *LoadResult) []lint.Finding {
 	checkStencilVersion(&f, mf)
 	checkArguments(&f, mf)
 	checkModules(&f, mf)
+	checkCapabilities(&f, mf)
 
 	// Annotate each finding with its source line where resolvable.
 	findings := f.Items()
@@ -229,6 +230,18 @@ func checkModules(f *lint.Findings, mf *configuration.TemplateRepositoryManifest
 	}
 }
 
+// checkCapabilities reports capabilities that stencil does not recognize,
+// in declaration order.
+func checkCapabilities(f *lint.Findings, mf *configuration.TemplateRepositoryManifest) {
+	for _, c := range mf.Capabilities {
+		if !c.IsValid() {
+			f.Errorf("capabilities", "unknown capability %q (valid: %s, %s, %s, %s)", c,
+				configuration.CapabilityPostRunCommands, configuration.CapabilityNativeExtensions,
+				configuration.CapabilityDeleteFiles, configuration.CapabilityWriteOutsideProject)
+		}
+	}
+}
+
 // moduleIDPath builds the finding path for module i, preferring its name over
 // its slice index. Delegates to modulefix.ModulePath so the checker and the
 // fixer produce identical paths from a single implementation.
@@ -127,6 +127,10 @@ func TestValidate(t *testing.T) {
 			name: "unknown type",
 			in:   "name: testing\ntype: templaes\n",
 		},
+		{
+			name: "unknown capability",
+			in:   "name: testing\ncapabilities: [deleteFiles, everything]\n",
+		},
 		{
 			name: "invalid stencilVersion",
 			in:   "name: testing\nstencilVersion: not-a-constraint\n",
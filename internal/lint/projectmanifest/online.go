@@ -20,6 +20,7 @@ import (
 
 	"github.com/santhosh-tekuri/jsonschema/v5"
 
+	"github.com/getoutreach/stencil/internal/codegen"
 	"github.com/getoutreach/stencil/internal/dotnotation"
 	"github.com/getoutreach/stencil/internal/lint"
 	"github.com/getoutreach/stencil/pkg/configuration"
@@ -142,6 +143,9 @@ func checkUndeclaredArgs(res *LoadResult, idx map[string][]declaration, mods []R
 		if _, ok := declaredTop[key]; ok {
 			continue // this top-level key is (a prefix of) a declared name
 		}
+		if key == codegen.ArgumentRefKey {
+			continue // a file reference, resolved at render time
+		}
 		f.Warnf("arguments."+key,
 			"no resolved module declares argument %q; check for a typo or remove it", key)
 	}
@@ -347,9 +351,16 @@ func checkArguments(res *LoadResult, idx map[string][]declaration) []lint.Findin
 	}
 	sort.Strings(names)
 
+	// Arguments may be provided by a referenced file, so required arguments
+	// can't be checked when there is one.
+	hasRef := containsFileRef(res.Manifest.Arguments)
+
 	for _, name := range names {
 		value, provided := providedValue(res.Manifest.Arguments, name)
 		for _, d := range idx[name] {
+			if provided && codegen.ContainsArgumentReference(value) {
+				continue // the value is only known at render time
+			}
 			if provided {
 				// O2: validate the value against this declaration's schema.
 				if len(d.arg.Schema) > 0 {
@@ -361,7 +372,7 @@ func checkArguments(res *LoadResult, idx map[string][]declaration) []lint.Findin
 				}
 			} else {
 				// O3: required with no default and no value.
-				if d.arg.Required && d.arg.Default == nil {
+				if d.arg.Required && d.arg.Default == nil && !hasRef {
 					f.Errorf("arguments."+name,
 						"argument %q is required by module %q but is not set; "+
 							"set 'arguments.%s' in service.yaml", name, d.importPath, name)
@@ -396,6 +407,25 @@ func providedValue(args map[string]any, name string) (any, bool) {
 	return v, true
 }
 
+// containsFileRef reports whether v contains a mapping with a $ref key,
+// which is replaced by the contents of a file at render time.
+func containsFileRef(v any) bool {
+	switch t := v.(type) {
+	case map[string]any:
+		if _, ok := t[codegen.ArgumentRefKey]; ok {
+			return true
+		}
+		for _, e := range t {
+			if containsFileRef(e) {
+				return true
+			}
+		}
+	case []any:
+		return slices.ContainsFunc(t, containsFileRef)
+	}
+	return false
+}
+
 // validateValue compiles a single argument schema (Draft 2020-12) HERMETICALLY
 // (external $ref rejected — no filesystem/network) and validates v against it.
 // Mirrors internal/lint/manifest/compileSchema, extended to also validate a
@@ -236,6 +236,19 @@ func TestCheckArgumentsO3RequiredSatisfiedByDefault(t *testing.T) {
 	assert.Equal(t, 0, len(checkArguments(res, idx)))
 }
 
+func TestCheckArgumentsSkipsReferences(t *testing.T) {
+	mods := []ResolvedModule{mod("github.com/x/a", map[string]configuration.Argument{
+		"port": {Schema: map[string]any{"type": "integer"}},
+		"team": {Required: true},
+	})}
+	idx, _ := buildArgIndex(mods)
+	res := &LoadResult{Manifest: &configuration.ServiceManifest{
+		Arguments: map[string]any{"$ref": "../shared/args.yaml", "port": "${env:PORT}"},
+	}}
+	assert.Equal(t, 0, len(checkArguments(res, idx)))
+	assert.Equal(t, 0, len(checkUndeclaredArgs(res, idx, mods)))
+}
+
 func TestCheckArgumentsExplicitNullIsNotProvided(t *testing.T) {
 	// foo: null must count as NOT provided → required arg still missing (O3).
 	mods := []ResolvedModule{mod("github.com/x/a", map[string]configuration.Argument{
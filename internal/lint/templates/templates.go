@@ -72,8 +72,18 @@ func addf(f *lint.Findings, name string, line int, sev lint.Severity, format str
 // "<stdin>") and returns every finding. It never returns an error for lint
 // problems; a non-nil error is reserved for an I/O failure reading r.
 func LintReader(name string, r io.Reader) ([]lint.Finding, error) {
+	return LintReaderWithCommentStyles(name, r, nil)
+}
+
+// LintReaderWithCommentStyles is LintReader for a template from a module
+// whose manifest declares commentStyles. Block tags are recognized after the
+// comment prefix that codegen.CommentStyleFor returns for the file the
+// template renders (name without its .tpl extension), exactly as they are
+// at render time.
+func LintReaderWithCommentStyles(name string, r io.Reader, commentStyles map[string]string) ([]lint.Finding, error) {
 	var f lint.Findings
-	if err := scan(name, r, &f); err != nil {
+	prefix := codegen.CommentStyleFor(strings.TrimSuffix(name, ".tpl"), commentStyles)
+	if err := scan(name, r, prefix, &f); err != nil {
 		return nil, err
 	}
 	items := f.Items()
@@ -90,8 +100,9 @@ func LintReader(name string, r io.Reader) ([]lint.Finding, error) {
 
 // scan walks the template line by line and appends findings. A single open
 // block plus a pendingNested counter (which absorbs the end tags of illegally
-// nested starts) is enough because blocks cannot legally nest.
-func scan(name string, r io.Reader, f *lint.Findings) error {
+// nested starts) is enough because blocks cannot legally nest. Tags written
+// after commentPrefix are normalized to "//" before being classified.
+func scan(name string, r io.Reader, commentPrefix string, f *lint.Findings) error {
 	sc := bufio.NewScanner(r)
 	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 
@@ -118,7 +129,7 @@ func scan(name string, r io.Reader, f *lint.Findings) error {
 	for line := 1; line <= len(lines); line++ {
 		text := lines[line-1]
 
-		tok := classify(text)
+		tok := classify(codegen.NormalizeCommentPrefix(text, commentPrefix))
 
 		switch {
 		case tok.misuse != "":
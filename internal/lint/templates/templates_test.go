@@ -187,3 +187,25 @@ func TestLint(t *testing.T) {
 		})
 	}
 }
+
+func TestLintCommentStyles(t *testing.T) {
+	// A block without file.Block, written in a comment style that's only
+	// recognized for some files, is only reported for those files.
+	in := ";; <<Stencil::Block(deps)>>\n(require 'x)\n;; <</Stencil::Block>>\n"
+
+	findings, err := linttemplates.LintReader("core.clj.tpl", strings.NewReader(in))
+	if err != nil || len(findings) != 1 {
+		t.Fatalf("expected 1 finding for a default comment style, got %v (err: %v)", findings, err)
+	}
+
+	findings, err = linttemplates.LintReader("notes.txt.tpl", strings.NewReader(in))
+	if err != nil || len(findings) != 0 {
+		t.Fatalf("expected no findings for an unrecognized comment style, got %v (err: %v)", findings, err)
+	}
+
+	findings, err = linttemplates.LintReaderWithCommentStyles("notes.txt.tpl", strings.NewReader(in),
+		map[string]string{".txt": ";;"})
+	if err != nil || len(findings) != 1 {
+		t.Fatalf("expected 1 finding for a module comment style, got %v (err: %v)", findings, err)
+	}
+}
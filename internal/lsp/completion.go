@@ -0,0 +1,239 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements completion and hover documentation
+// for template functions and the arguments of a module.
+
+package lsp
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getoutreach/stencil/pkg/configuration"
+)
+
+// function is the documentation of a template function.
+type function struct {
+	// Name is the name of the function in templates, e.g. stencil.Arg
+	Name string
+
+	// Signature is the Go signature of the function
+	Signature string
+
+	// Doc is the doc comment of the function
+	Doc string
+}
+
+// markdown returns the documentation of f as markdown.
+func (f *function) markdown() *MarkupContent {
+	return markdown("```go\n" + f.Signature + "\n```\n\n" + f.Doc)
+}
+
+// namespaces are the objects, e.g. stencil, whose methods are template
+// functions.
+var namespaces = []string{"file", "stencil"}
+
+// This block contains the patterns matching the text before the cursor
+// that completions are offered for.
+var (
+	// argPrefix matches a partial argument name passed to stencil.Arg
+	argPrefix = regexp.MustCompile(`stencil\.Arg\s+"([^"]*)$`)
+
+	// methodPrefix matches a partial method of a namespace
+	methodPrefix = regexp.MustCompile(`\b(file|stencil)\.(\w*)$`)
+
+	// wordPrefix matches a partial function name
+	wordPrefix = regexp.MustCompile(`\w*$`)
+)
+
+// completion returns the completions at pos in the template with the
+// provided URI.
+func (s *Server) completion(uri string, pos Position) *CompletionList {
+	list := &CompletionList{Items: []CompletionItem{}}
+	text, ok := s.docs[uri]
+	path := uriToPath(uri)
+	if !ok || documentKind(path) != documentTemplate {
+		return list
+	}
+
+	off := offsetAt(text, pos)
+	if !inAction(text, off) {
+		return list
+	}
+	prefix := text[strings.LastIndexByte(text[:off], '\n')+1 : off]
+
+	// replace returns an edit replacing the partial word of length n,
+	// before the cursor, with newText
+	replace := func(n int, newText string) *TextEdit {
+		start := positionAt(text, off-n)
+		return &TextEdit{Range: Range{Start: start, End: pos}, NewText: newText}
+	}
+
+	if m := argPrefix.FindStringSubmatch(prefix); m != nil {
+		mf, _ := s.moduleManifest(path)
+		if mf == nil {
+			return list
+		}
+		for _, name := range argumentNames(mf) {
+			arg := mf.Arguments[name]
+			list.Items = append(list.Items, CompletionItem{
+				Label:         name,
+				Kind:          CompletionItemKindField,
+				Documentation: argumentDoc(name, &arg),
+				TextEdit:      replace(len(m[1]), name),
+			})
+		}
+		return list
+	}
+
+	if m := methodPrefix.FindStringSubmatch(prefix); m != nil {
+		for i := range functions {
+			f := &functions[i]
+			ns, method, ok := strings.Cut(f.Name, ".")
+			if !ok || ns != m[1] {
+				continue
+			}
+			list.Items = append(list.Items, CompletionItem{
+				Label:         method,
+				Kind:          CompletionItemKindFunction,
+				Detail:        f.Signature,
+				Documentation: f.markdown(),
+				TextEdit:      replace(len(m[2]), method),
+			})
+		}
+		return list
+	}
+
+	word := wordPrefix.FindString(prefix)
+	for _, ns := range namespaces {
+		list.Items = append(list.Items, CompletionItem{
+			Label:    ns,
+			Kind:     CompletionItemKindModule,
+			TextEdit: replace(len(word), ns),
+		})
+	}
+	for i := range functions {
+		f := &functions[i]
+		if strings.Contains(f.Name, ".") {
+			continue
+		}
+		list.Items = append(list.Items, CompletionItem{
+			Label:         f.Name,
+			Kind:          CompletionItemKindFunction,
+			Detail:        f.Signature,
+			Documentation: f.markdown(),
+			TextEdit:      replace(len(word), f.Name),
+		})
+	}
+	return list
+}
+
+// hover returns the documentation of the function, or the argument
+// passed to stencil.Arg, at pos in the template with the provided URI.
+func (s *Server) hover(uri string, pos Position) *Hover {
+	text, ok := s.docs[uri]
+	path := uriToPath(uri)
+	if !ok || documentKind(path) != documentTemplate {
+		return nil
+	}
+	off := offsetAt(text, pos)
+
+	if name, start, end, ok := stringAt(text, off); ok && strings.HasSuffix(strings.TrimSpace(text[:start-1]), "stencil.Arg") {
+		mf, _ := s.moduleManifest(path)
+		if mf == nil {
+			return nil
+		}
+		arg, ok := mf.Arguments[name]
+		if !ok {
+			return nil
+		}
+		r := Range{Start: positionAt(text, start), End: positionAt(text, end)}
+		return &Hover{Contents: *argumentDoc(name, &arg), Range: &r}
+	}
+
+	start, end := identifierAt(text, off)
+	for i := range functions {
+		if functions[i].Name == text[start:end] {
+			r := Range{Start: positionAt(text, start), End: positionAt(text, end)}
+			return &Hover{Contents: *functions[i].markdown(), Range: &r}
+		}
+	}
+	return nil
+}
+
+// argumentNames returns the sorted names of the arguments of mf.
+func argumentNames(mf *configuration.TemplateRepositoryManifest) []string {
+	names := make([]string, 0, len(mf.Arguments))
+	for name := range mf.Arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// argumentDoc returns the documentation of the argument arg.
+func argumentDoc(name string, arg *configuration.Argument) *MarkupContent {
+	var b strings.Builder
+	b.WriteString("**" + name + "**")
+	if arg.Required {
+		b.WriteString(" (required)")
+	}
+	if arg.Description != "" {
+		b.WriteString("\n\n" + strings.TrimSpace(arg.Description))
+	}
+	if arg.From != "" {
+		b.WriteString("\n\nFrom " + arg.From + ".")
+	}
+	if arg.Deprecated != "" {
+		b.WriteString("\n\nDeprecated: " + string(arg.Deprecated))
+	}
+	return markdown(b.String())
+}
+
+// inAction returns true if the byte offset off in text is inside of a
+// template action, {{ ... }}.
+func inAction(text string, off int) bool {
+	open := strings.LastIndex(text[:off], "{{")
+	return open >= 0 && open > strings.LastIndex(text[:off], "}}")
+}
+
+// identifierAt returns the bounds of the, possibly dotted, identifier
+// around the byte offset off in text.
+func identifierAt(text string, off int) (start, end int) {
+	isIdent := func(c byte) bool {
+		return c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+
+	start, end = off, off
+	for start > 0 && isIdent(text[start-1]) {
+		start--
+	}
+	for end < len(text) && isIdent(text[end]) {
+		end++
+	}
+	return start, end
+}
+
+// stringAt returns the contents, and their bounds, of the string literal
+// on the line around the byte offset off in text.
+func stringAt(text string, off int) (s string, start, end int, ok bool) {
+	lineStart := strings.LastIndexByte(text[:off], '\n') + 1
+	lineEnd := len(text)
+	if i := strings.IndexByte(text[off:], '\n'); i >= 0 {
+		lineEnd = off + i
+	}
+
+	// Quotes before the cursor alternate between opening and closing
+	quotes := strings.Count(text[lineStart:off], `"`)
+	if quotes%2 == 0 {
+		return "", 0, 0, false
+	}
+	start = lineStart + strings.LastIndexByte(text[lineStart:off], '"') + 1
+	i := strings.IndexByte(text[off:lineEnd], '"')
+	if i < 0 {
+		return "", 0, 0, false
+	}
+	end = off + i
+	return text[start:end], start, end, true
+}
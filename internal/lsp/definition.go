@@ -0,0 +1,103 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements go-to-definition for the names of
+// templates, e.g. those passed to stencil.ApplyTemplate.
+
+package lsp
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// templateReference matches the text before a template name, the name
+// being the string after it.
+var templateReference = regexp.MustCompile(`\b(define|template|block|stencil\.ApplyTemplate)\s*$`)
+
+// definition returns the locations of the define actions of the template
+// whose name is at pos, in the template with the provided URI. Templates
+// are searched for in the module the template belongs to.
+func (s *Server) definition(uri string, pos Position) []Location {
+	locations := []Location{}
+	text, ok := s.docs[uri]
+	path := uriToPath(uri)
+	if !ok || documentKind(path) != documentTemplate {
+		return locations
+	}
+
+	name, start, _, ok := stringAt(text, offsetAt(text, pos))
+	if !ok || !templateReference.MatchString(text[:start-1]) {
+		return locations
+	}
+
+	define := regexp.MustCompile(`\{\{-?\s*define\s+"` + regexp.QuoteMeta(name) + `"`)
+	for _, p := range s.moduleTemplates(path) {
+		contents, ok := s.docs[pathToURI(p)]
+		if !ok {
+			b, err := os.ReadFile(p)
+			if err != nil {
+				continue
+			}
+			contents = string(b)
+		}
+
+		for _, m := range define.FindAllStringIndex(contents, -1) {
+			// Point at the name of the template
+			nameStart := m[1] - len(name) - 1
+			locations = append(locations, Location{
+				URI: pathToURI(p),
+				Range: Range{
+					Start: positionAt(contents, nameStart),
+					End:   positionAt(contents, nameStart+len(name)),
+				},
+			})
+		}
+	}
+	return locations
+}
+
+// moduleTemplates returns the paths of the templates of the module the
+// template at path belongs to, or of the templates in its directory if
+// it doesn't belong to a module. Templates opened by the client are
+// included.
+func (s *Server) moduleTemplates(path string) []string {
+	dir := filepath.Dir(path)
+	if _, mfDir := s.moduleManifest(path); mfDir != "" {
+		dir = mfDir
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	//nolint:errcheck // Why: best effort, unreadable directories are skipped
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && p != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(p, ".tpl") {
+			seen[p] = true
+		}
+		return nil
+	})
+	for uri := range s.docs {
+		if p := uriToPath(uri); documentKind(p) == documentTemplate && strings.HasPrefix(p, dir+string(filepath.Separator)) {
+			seen[p] = true
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
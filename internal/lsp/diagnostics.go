@@ -0,0 +1,164 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file produces diagnostics from the findings of the
+// template, manifest.yaml and service.yaml linters.
+
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/getoutreach/stencil/internal/lint"
+	lintmanifest "github.com/getoutreach/stencil/internal/lint/manifest"
+	lintprojectmanifest "github.com/getoutreach/stencil/internal/lint/projectmanifest"
+	linttemplates "github.com/getoutreach/stencil/internal/lint/templates"
+	"github.com/getoutreach/stencil/pkg/configuration"
+)
+
+// diagnosticSource is the source of every diagnostic.
+const diagnosticSource = "stencil"
+
+// This block contains the kinds of documents the server understands.
+const (
+	documentUnknown = iota
+	documentTemplate
+	documentModuleManifest
+	documentProjectManifest
+)
+
+// documentKind returns the kind of the document at path.
+func documentKind(path string) int {
+	switch {
+	case filepath.Base(path) == "manifest.yaml":
+		return documentModuleManifest
+	case filepath.Base(path) == "service.yaml":
+		return documentProjectManifest
+	case strings.HasSuffix(path, ".tpl"):
+		return documentTemplate
+	default:
+		return documentUnknown
+	}
+}
+
+// diagnostics returns the diagnostics of the document with the provided
+// URI and contents, using the offline checks of the linters.
+func (s *Server) diagnostics(uri, text string) []Diagnostic {
+	path := uriToPath(uri)
+
+	var findings []lint.Finding
+	switch documentKind(path) {
+	case documentTemplate:
+		var styles map[string]string
+		if mf, _ := s.moduleManifest(path); mf != nil {
+			styles = mf.CommentStyles
+		}
+
+		var err error
+		findings, err = linttemplates.LintReaderWithCommentStyles(path, strings.NewReader(text), styles)
+		if err != nil {
+			return []Diagnostic{errorDiagnostic(text, err)}
+		}
+
+		// The path of template findings is the template itself
+		for i := range findings {
+			findings[i].Path = ""
+		}
+	case documentModuleManifest:
+		res, err := lintmanifest.Load(strings.NewReader(text))
+		if err != nil {
+			return []Diagnostic{errorDiagnostic(text, err)}
+		}
+		findings = lintmanifest.Validate(res)
+	case documentProjectManifest:
+		res, err := lintprojectmanifest.Load(strings.NewReader(text))
+		if err != nil {
+			return []Diagnostic{errorDiagnostic(text, err)}
+		}
+		findings = lintprojectmanifest.Validate(res)
+	default:
+		return nil
+	}
+
+	diags := make([]Diagnostic, 0, len(findings))
+	for _, f := range findings {
+		line := 0
+		if f.Line > 0 {
+			line = f.Line - 1
+		}
+
+		msg := f.Message
+		if f.Path != "" {
+			msg = f.Path + ": " + msg
+		}
+		diags = append(diags, Diagnostic{
+			Range:    lineRange(text, line),
+			Severity: severity(f.Severity),
+			Source:   diagnosticSource,
+			Message:  msg,
+		})
+	}
+	return diags
+}
+
+// errorDiagnostic returns a diagnostic, on the first line of text, for
+// err.
+func errorDiagnostic(text string, err error) Diagnostic {
+	return Diagnostic{
+		Range:    lineRange(text, 0),
+		Severity: DiagnosticSeverityError,
+		Source:   diagnosticSource,
+		Message:  err.Error(),
+	}
+}
+
+// severity returns the diagnostic severity of a finding with the
+// provided severity.
+func severity(s lint.Severity) DiagnosticSeverity {
+	switch s {
+	case lint.SeverityError:
+		return DiagnosticSeverityError
+	case lint.SeverityWarning:
+		return DiagnosticSeverityWarning
+	default:
+		return DiagnosticSeverityInformation
+	}
+}
+
+// moduleManifest returns the manifest of the module the file at path
+// belongs to, found by walking up from path to the first directory
+// containing a manifest.yaml, and that directory. The manifest is read
+// from the client if it's open. Nil is returned if there's no manifest or
+// it can't be parsed.
+func (s *Server) moduleManifest(path string) (*configuration.TemplateRepositoryManifest, string) {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, ""
+	}
+
+	for {
+		mfPath := filepath.Join(dir, "manifest.yaml")
+		raw, ok := s.docs[pathToURI(mfPath)]
+		if !ok {
+			if b, err := os.ReadFile(mfPath); err == nil {
+				raw, ok = string(b), true
+			}
+		}
+		if ok {
+			var mf configuration.TemplateRepositoryManifest
+			if err := yaml.Unmarshal([]byte(raw), &mf); err != nil {
+				return nil, dir
+			}
+			return &mf, dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,43 @@
+// Code generated by genfunctions. DO NOT EDIT.
+
+package lsp
+
+// functions are the template functions, sorted by name.
+var functions = []function{
+	{Name: "Dereference", Signature: "Dereference(i any) any", Doc: "Dereference dereferences a pointer returning the\nreferenced data type. If the provided value is not\na pointer, it is returned."},
+	{Name: "QuoteJoinStrings", Signature: "QuoteJoinStrings(elems []string, sep string) string", Doc: "QuoteJoinStrings takes a slice of strings and joins\nthem with the provided seperator, sep, while quoting all\nvalues."},
+	{Name: "file.Block", Signature: "file.Block(name string) string", Doc: "file.Block returns the contents of a given block\n\n\t###Block(name)\n\tHello, world!\n\t###EndBlock(name)\n\n\t###Block(name)\n\t{{- /* Only output if the block is set */}}\n\t{{- if not (empty (file.Block \"name\")) }}\n\t{{ file.Block \"name\" }}\n\t{{- end }}\n\t###EndBlock(name)\n\n\t###Block(name)\n\t{{ - /* Short hand syntax, but adds newline if no contents */}}\n\t{{ file.Block \"name\" }}\n\t###EndBlock(name)"},
+	{Name: "file.Create", Signature: "file.Create(path string, mode os.FileMode, modTime time.Time) (out, err error)", Doc: "file.Create creates a new file that is rendered by the current template\n\nIf the template has a single file with no contents\nthis file replaces it.\n\n\t{{- define \"command\" }}\n\tpackage main\n\n\timport \"fmt\"\n\n\tfunc main() {\n\t  fmt.Println(\"hello, world!\")\n\t}\n\n\t{{- end }}\n\n\t# Generate a \"<commandName>.go\" file for each command in .arguments.commands\n\t{{- range $_, $commandName := (stencil.Arg \"commands\") }}\n\t{{- file.Create (printf \"cmd/%s.go\" $commandName) 0600 now }}\n\t{{- stencil.ApplyTemplate \"command\" | file.SetContents }}\n\t{{- end }}"},
+	{Name: "file.Delete", Signature: "file.Delete() error", Doc: "file.Delete deletes the current file being rendered\n\n\t{{ file.Delete }}"},
+	{Name: "file.Merge", Signature: "file.Merge(strategy string) (out, err error)", Doc: "file.Merge marks the current file being rendered as a fragment of a\nstructured (YAML, JSON or TOML) file. Every template that merges into\nthe same path contributes a fragment, and the fragments are deep-merged,\nin render order, with the provided strategy. The \"append\" strategy\nappends to lists and overrides scalars, \"override\" overrides both lists\nand scalars, and \"error\" appends to lists but fails if fragments set a\nscalar to different values.\n\nKeys in the existing file that aren't set by any fragment, e.g. ones\nadded by a user, are preserved. The keys rendered by the fragments are\nrecorded in stencil.lock, and ones that a later run no longer renders\nare removed from the file, as are list items that are no longer\nrendered, since lists are owned by the fragments rendering them. Until\na run has recorded the rendered keys, every existing key is kept.\n\nYAML files, and their fragments, must contain a single document,\nmerging fails otherwise instead of dropping the other documents.\n\n\t{{ $_ := file.SetPath \".golangci.yml\" }}\n\t{{ $_ := file.Merge \"append\" }}\n\tlinters:\n\t  enable:\n\t    - errcheck"},
+	{Name: "file.Path", Signature: "file.Path() string", Doc: "file.Path returns the current path of the file we're writing to\n\n\t{{ file.Path }}"},
+	{Name: "file.PreserveKey", Signature: "file.PreserveKey(key string) (out, err error)", Doc: "file.PreserveKey marks a key of the current file being rendered, which must\nbe a YAML or JSON file, as user-owned. If the key is set in the existing\nfile its value, including any comments, replaces the rendered value.\nKeys are dot-separated paths, with lists indexed by number. In files\nwith multiple YAML documents, keys are preserved between the documents\nat the same position.\n\nThis is an alternative to blocks for files that don't support comments,\nor where blocks would break indentation.\n\n\t{{ $_ := file.PreserveKey \"spec.replicas\" }}\n\t{{ $_ := file.PreserveKey \"scripts.test\" }}"},
+	{Name: "file.RemoveAll", Signature: "file.RemoveAll(path string) (out, err error)", Doc: "file.RemoveAll deletes all the contents in the provided path\n\n\t{{ file.RemoveAll \"path\" }}"},
+	{Name: "file.RenameBlock", Signature: "file.RenameBlock(oldName, newName string) (out, err error)", Doc: "file.RenameBlock carries the contents of a block that was renamed, in the\nexisting file, over to its new name. This must be called before\nfile.Block is called with the new name. Without it, the contents of\nthe old block are orphaned.\n\n\t{{ $_ := file.RenameBlock \"imports\" \"extraImports\" }}\n\t// <<Stencil::Block(extraImports)>>\n\t{{ file.Block \"extraImports\" }}\n\t// <</Stencil::Block>>"},
+	{Name: "file.SetCommentStyle", Signature: "file.SetCommentStyle(prefix string) (out, err error)", Doc: "file.SetCommentStyle sets the comment prefix that block tags can be written\nafter in the current file being rendered, in addition to the default\n\"//\", \"##\", \"--\" and \"<!--\" prefixes. This overrides the prefix for the\nfile's extension, from the module's manifest or the defaults, and must\nbe called before file.Block.\n\n\t{{ $_ := file.SetCommentStyle \";;\" }}\n\t;; <<Stencil::Block(deps)>>\n\t{{ file.Block \"deps\" }}\n\t;; <</Stencil::Block>>"},
+	{Name: "file.SetContents", Signature: "file.SetContents(contents string) error", Doc: "file.SetContents sets the contents of file being rendered to the value\n\nThis is useful for programmatic file generation within a template.\n\n\t{{ file.SetContents \"Hello, world!\" }}"},
+	{Name: "file.SetExecutable", Signature: "file.SetExecutable() error", Doc: "file.SetExecutable marks the current file being rendered as executable by\nadding the execute bit for everyone that can read it, e.g. 0644 becomes\n0755.\n\n\t{{ $_ := file.SetExecutable }}"},
+	{Name: "file.SetMode", Signature: "file.SetMode(mode os.FileMode) error", Doc: "file.SetMode sets the file mode of the current file being rendered. The\nmode is also applied to the file if it already exists on disk.\n\n\t{{ $_ := file.SetMode 0600 }}"},
+	{Name: "file.SetPath", Signature: "file.SetPath(path string) (out, err error)", Doc: "file.SetPath changes the path of the current file being rendered\n\n\t{{ $_ := file.SetPath \"new/path/to/file.txt\" }}\n\nNote: The $_ is required to ensure <nil> isn't outputted into\nthe template."},
+	{Name: "file.Skip", Signature: "file.Skip(reason string) error", Doc: "file.Skip skips the current file being rendered\n\n\t{{ $_ := file.Skip \"A reason to skip this reason\" }}"},
+	{Name: "file.Static", Signature: "file.Static() (out, err error)", Doc: "file.Static marks the current file as static\n\nMarking a file is equivalent to calling file.Skip, but instead\nfile.Skip is only called if the file already exists. This is useful\nfor files you want to generate but only once. It's generally\nrecommended that you do not do this as it limits your ability to change\nthe file in the future.\n\n\t{{ $_ := file.Static }}"},
+	{Name: "file.Symlink", Signature: "file.Symlink(target string) (out, err error)", Doc: "file.Symlink turns the current file being rendered into a symlink that\npoints to target. Relative targets are relative to the directory of the\nfile, and the rendered contents of the file are ignored.\n\n\t{{ $_ := file.Symlink \"../shared/Makefile\" }}"},
+	{Name: "fromJson", Signature: "fromJson(str string) (any, error)", Doc: "fromJson converts a JSON document into a interface{}."},
+	{Name: "fromToml", Signature: "fromToml(str string) (any, error)", Doc: "fromToml converts a TOML document into a value."},
+	{Name: "fromYaml", Signature: "fromYaml(str string) (any, error)", Doc: "fromYaml converts a YAML document into a interface{}.\n\nBased on: https://github.com/helm/helm/blob/a499b4b179307c267bdf3ec49b880e3dbd2a5591/pkg/engine/funcs.go#L98"},
+	{Name: "stencil.AddToModuleHook", Signature: "stencil.AddToModuleHook(module, name string, data any) (out, err error)", Doc: "stencil.AddToModuleHook adds to a hook in another module\n\nThis functions write to module hook owned by another module for\nit to operate on. These are not strongly typed so it's best practice\nto look at how the owning module uses it for now. Module hooks must always\nbe written to with a list to ensure that they can always be written to multiple\ntimes.\n\n\t{{- /* This writes to a module hook */}}\n\t{{ stencil.AddToModuleHook \"github.com/myorg/repo\" \"myModuleHook\" (list \"myData\") }}"},
+	{Name: "stencil.ApplyTemplate", Signature: "stencil.ApplyTemplate(name string, dataSli ...any) (string, error)", Doc: "stencil.ApplyTemplate executes a template inside of the current module\n\nThis function does not support rendering a template from another module.\n\n\t{{- define \"command\"}}\n\tpackage main\n\n\timport \"fmt\"\n\n\tfunc main() {\n\t  fmt.Println(\"hello, world!\")\n\t}\n\n\t{{- end }}\n\n\t{{- stencil.ApplyTemplate \"command\" | file.SetContents }}"},
+	{Name: "stencil.Arg", Signature: "stencil.Arg(pth string) (any, error)", Doc: "stencil.Arg returns the value of an argument in the service's manifest\n\n\t{{- stencil.Arg \"name\" }}\n\nNote: Using `stencil.Arg` with no path returns all arguments\nand is equivalent to `stencil.Args`. However, that is DEPRECATED\nalong with `stencil.Args` as it doesn't provide default types, or\ncheck the JSON schema, or track which module calls what argument."},
+	{Name: "stencil.Args", Signature: "stencil.Args() map[string]any", Doc: "Deprecated: Use Arg instead.\nArgs returns all arguments passed to stencil from the service's manifest\n\nNote: This doesn't set default values and is instead\nrepresentative of _all_ data passed in its raw form.\n\nThis is deprecated and will be removed in a future release.\n\n\t{{- (stencil.Args).name }}"},
+	{Name: "stencil.Break", Signature: "stencil.Break(data ...any) (string, error)", Doc: "stencil.Break is a breakpoint for `stencil debug`. When the template is being\ndebugged, the data passed to it is recorded and, in interactive mode,\nrendering is paused to inspect the values and what the template has\nread so far. Otherwise, it does nothing.\n\n\t{{- stencil.Break }}\n\t{{- stencil.Break $myVariable }}"},
+	{Name: "stencil.Debug", Signature: "stencil.Debug(args ...any) error", Doc: "stencil.Debug logs the provided arguments under the DEBUG log level (must run stencil with --debug).\n\n\t{{- $_ := stencil.Debug \"I'm a log!\" }}"},
+	{Name: "stencil.Exists", Signature: "stencil.Exists(name string) bool", Doc: "stencil.Exists returns true if the file exists in the current directory\n\n\t{{- if stencil.Exists \"myfile.txt\" }}\n\t{{ stencil.ReadFile \"myfile.txt\" }}\n\t{{- end }}"},
+	{Name: "stencil.GetGlobal", Signature: "stencil.GetGlobal(name string) any", Doc: "stencil.GetGlobal retrieves a global variable set by SetGlobal. The data returned from this function\nis unstructured so by averse to panics - look at where it was set to ensure you're dealing\nwith the proper type of data that you think it is.\n\n\t{{- /* This retrieves a global from the current context of the template module repository */}}\n\t{{ $isGeorgeCool := stencil.GetGlobal \"IsGeorgeCool\" }}"},
+	{Name: "stencil.GetModuleHook", Signature: "stencil.GetModuleHook(name string) []any", Doc: "stencil.GetModuleHook returns a module block in the scope of this module\n\nThis is incredibly useful for allowing other modules to write\nto files that your module owns. Think of them as extension points\nfor your module. The value returned by this function is always a\n[]any, aka a list.\n\n\t{{- /* This returns a []any */}}\n\t{{ $hook := stencil.GetModuleHook \"myModuleHook\" }}\n\t{{- range $hook }}\n\t  {{ . }}\n\t{{- end }}"},
+	{Name: "stencil.ReadBlocks", Signature: "stencil.ReadBlocks(fpath string) (map[string]string, error)", Doc: "stencil.ReadBlocks parses a file and attempts to read the blocks from it, and their data.\n\nAs a special case, if the file does not exist, an empty map is returned instead of an error.\n\n**NOTE**: This function does not guarantee that blocks are able to be read during runtime.\nfor example, if you try to read the blocks of a file from another module there is no guarantee\nthat that file will exist before you run this function. Nor is there the ability to tell stencil\nto do that (stencil does not have any order guarantees). Keep that in mind when using this function.\n\n\t{{- $blocks := stencil.ReadBlocks \"myfile.txt\" }}\n\t{{- range $name, $data := $blocks }}\n\t  {{- $name }}\n\t  {{- $data }}\n\t{{- end }}"},
+	{Name: "stencil.ReadFile", Signature: "stencil.ReadFile(name string) (string, error)", Doc: "stencil.ReadFile reads a file from the current directory and returns it's contents\n\n\t{{ stencil.ReadFile \"myfile.txt\" }}"},
+	{Name: "stencil.SetGlobal", Signature: "stencil.SetGlobal(name string, data any) error", Doc: "stencil.SetGlobal sets a global to be used in the context of the current template module\nrepository. This is useful because sometimes you want to define variables inside\nof a helpers template file after doing manifest argument processing and then use\nthem within one or more template files to be rendered; however, go templates limit\nthe scope of symbols to the current template they are defined in, so this is not\npossible without external tooling like this function.\n\nThis template function stores (and its inverse, GetGlobal, retrieves) data that is\nnot strongly typed, so use this at your own risk and be averse to panics that could\noccur if you're using the data it returns in the wrong way.\n\n\t{{- /* This writes a global into the current context of the template module repository */}}\n\t{{ stencil.SetGlobal \"IsGeorgeCool\" true }}"},
+	{Name: "toJson", Signature: "toJson(v any) (string, error)", Doc: "toJson converts a interface{} into a JSON document."},
+	{Name: "toToml", Signature: "toToml(v any) (string, error)", Doc: "toToml converts any value into a TOML document."},
+	{Name: "toYaml", Signature: "toYaml(v any) (string, error)", Doc: "toYaml is a clone of the helm toYaml function, which takes\nan interface{} and turns it into yaml\n\nBased on:\nhttps://github.com/helm/helm/blob/a499b4b179307c267bdf3ec49b880e3dbd2a5591/pkg/engine/funcs.go#L83"},
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file generates the documentation of the template
+// functions, used for completion and hover, from the doc comments of
+// the codegen package, like docs/gen/functions.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// output is the file the documentation is written to.
+const output = "functions.go"
+
+// function is the documentation of a template function.
+type function struct {
+	// Name is the name of the function in templates, e.g. stencil.Arg
+	Name string
+
+	// Signature is the Go signature of the function
+	Signature string
+
+	// Doc is the doc comment of the function
+	Doc string
+}
+
+// generate returns the source of the file containing the documentation of
+// the template functions declared in the codegen package in dir: the
+// methods of the Tpl* types and the functions in Default.
+func generate(dir string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := make(map[string]*ast.FuncDecl)
+	var defaults map[string]string
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				switch d := decl.(type) {
+				case *ast.FuncDecl:
+					funcs[funcKey(d)] = d
+				case *ast.GenDecl:
+					if m := defaultFuncs(d); m != nil {
+						defaults = m
+					}
+				}
+			}
+		}
+	}
+
+	var out []function
+	for key, d := range funcs {
+		typ, method, ok := strings.Cut(key, ".")
+		if !ok || !strings.HasPrefix(typ, "Tpl") || !ast.IsExported(method) {
+			continue
+		}
+		out = append(out, newFunction(fset, strings.ToLower(strings.TrimPrefix(typ, "Tpl"))+"."+method, d))
+	}
+	for name, fn := range defaults {
+		if d, ok := funcs[fn]; ok {
+			out = append(out, newFunction(fset, name, d))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by genfunctions. DO NOT EDIT.\n\n")
+	buf.WriteString("package lsp\n\n")
+	buf.WriteString("// functions are the template functions, sorted by name.\n")
+	buf.WriteString("var functions = []function{\n")
+	for _, f := range out {
+		fmt.Fprintf(&buf, "{Name: %s, Signature: %s, Doc: %s},\n",
+			strconv.Quote(f.Name), strconv.Quote(f.Signature), strconv.Quote(f.Doc))
+	}
+	buf.WriteString("}\n")
+	return format.Source(buf.Bytes())
+}
+
+// funcKey returns the name of d, prefixed with the name of its receiver's
+// type if it's a method.
+func funcKey(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return d.Name.Name
+	}
+
+	t := d.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name + "." + d.Name.Name
+	}
+	return d.Name.Name
+}
+
+// defaultFuncs returns the names of the functions in the Default
+// function map, keyed by their template name, if d declares it.
+func defaultFuncs(d *ast.GenDecl) map[string]string {
+	for _, spec := range d.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok || len(vs.Names) != 1 || vs.Names[0].Name != "Default" || len(vs.Values) != 1 {
+			continue
+		}
+		lit, ok := vs.Values[0].(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+
+		m := make(map[string]string)
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, kok := kv.Key.(*ast.BasicLit)
+			fn, fok := kv.Value.(*ast.Ident)
+			if !kok || !fok {
+				continue
+			}
+			if name, err := strconv.Unquote(key.Value); err == nil {
+				m[name] = fn.Name
+			}
+		}
+		return m
+	}
+	return nil
+}
+
+// newFunction returns the documentation of d, named name in templates.
+func newFunction(fset *token.FileSet, name string, d *ast.FuncDecl) function {
+	var sig bytes.Buffer
+	//nolint:errcheck // Why: writing to a bytes.Buffer can't fail
+	printer.Fprint(&sig, fset, d.Type)
+
+	// Refer to the function by its name in templates
+	doc := strings.TrimSpace(d.Doc.Text())
+	if rest, ok := strings.CutPrefix(doc, d.Name.Name+" "); ok {
+		doc = name + " " + rest
+	}
+
+	return function{
+		Name:      name,
+		Signature: name + strings.TrimPrefix(sig.String(), "func"),
+		Doc:       doc,
+	}
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: genfunctions <codegen package dir>")
+		os.Exit(1)
+	}
+
+	b, err := generate(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(output, b, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file ensures the generated function documentation is
+// up to date.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestFunctionsUpToDate(t *testing.T) {
+	want, err := generate("../../codegen")
+	assert.NilError(t, err)
+
+	got, err := os.ReadFile(filepath.Join("..", output))
+	assert.NilError(t, err)
+	assert.Equal(t, string(got), string(want), "function documentation is out of date, run go generate ./internal/lsp")
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements the language server, dispatching the
+// messages from the client to the handlers.
+
+//go:generate go run ./genfunctions ../codegen
+
+// Package lsp implements a language server for stencil templates,
+// manifest.yaml and service.yaml files.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"github.com/getoutreach/gobox/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Server is a language server. It offers diagnostics from the linters,
+// completion and hover documentation for template functions and
+// arguments, and go-to-definition for named templates.
+type Server struct {
+	log  logrus.FieldLogger
+	conn *conn
+
+	// docs are the contents of the documents opened by the client, keyed
+	// by URI
+	docs map[string]string
+}
+
+// NewServer returns a new Server.
+func NewServer(log logrus.FieldLogger) *Server {
+	return &Server{log: log, docs: make(map[string]string)}
+}
+
+// Serve serves a client, reading requests from r and writing responses
+// to w, until the client exits, r is closed or ctx is canceled.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for ctx.Err() == nil {
+		msg, err := s.conn.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var rerr *responseError
+		if errors.As(err, &rerr) {
+			if err := s.conn.reply(nil, nil, rerr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, err := s.handle(msg)
+		if msg.ID == nil {
+			// Notifications don't have a response
+			if err != nil {
+				s.log.WithError(err).Warnf("Failed to handle %s", msg.Method)
+			}
+			continue
+		}
+		if err := s.conn.reply(msg.ID, result, err); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// handle handles msg, returning the result to respond with if it's a
+// request.
+func (s *Server) handle(msg *message) (any, error) {
+	switch msg.Method {
+	case "initialize":
+		return &InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:   1,
+				CompletionProvider: &CompletionOptions{TriggerCharacters: []string{".", "\""}},
+				HoverProvider:      true,
+				DefinitionProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "stencil", Version: app.Info().Version},
+		}, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		s.docs[params.TextDocument.URI] = params.TextDocument.Text
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.docs[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params.TextDocument.URI, params.Position), nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params.TextDocument.URI, params.Position), nil
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params.TextDocument.URI, params.Position), nil
+	}
+
+	if msg.ID == nil {
+		// Unknown notifications, e.g. initialized, are ignored
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+// publishDiagnostics sends the diagnostics of the document with the
+// provided URI to the client.
+func (s *Server) publishDiagnostics(uri string) error {
+	diags := s.diagnostics(uri, s.docs[uri])
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	return s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{URI: uri, Diagnostics: diags})
+}
+
+// unmarshalParams decodes the parameters of msg into v.
+func unmarshalParams(msg *message, v any) error {
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// uriToPath returns the path of the file with the provided URI.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// pathToURI returns the URI of the file at path.
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,241 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for the language server.
+
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gotest.tools/v3/assert"
+)
+
+// session runs a server with the provided requests, numbered from 1,
+// and notifications, and returns the messages it wrote.
+func session(t *testing.T, msgs ...map[string]any) []map[string]any {
+	t.Helper()
+
+	var in bytes.Buffer
+	for _, msg := range append(msgs, map[string]any{"method": "exit"}) {
+		msg["jsonrpc"] = "2.0"
+		b, err := json.Marshal(msg)
+		assert.NilError(t, err)
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	var out bytes.Buffer
+	assert.NilError(t, NewServer(log).Serve(context.Background(), &in, &out))
+
+	var resps []map[string]any
+	r := textproto.NewReader(bufio.NewReader(&out))
+	for {
+		header, err := r.ReadMIMEHeader()
+		if err == io.EOF {
+			return resps
+		}
+		assert.NilError(t, err)
+
+		n, err := strconv.Atoi(header.Get("Content-Length"))
+		assert.NilError(t, err)
+		body := make([]byte, n)
+		_, err = io.ReadFull(r.R, body)
+		assert.NilError(t, err)
+
+		var resp map[string]any
+		assert.NilError(t, json.Unmarshal(body, &resp))
+		resps = append(resps, resp)
+	}
+}
+
+// request returns a request with the provided id.
+func request(id int, method string, params any) map[string]any {
+	return map[string]any{"id": id, "method": method, "params": params}
+}
+
+// notification returns a notification.
+func notification(method string, params any) map[string]any {
+	return map[string]any{"method": method, "params": params}
+}
+
+// position returns the parameters of a request about a position.
+func position(uri string, line, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": character},
+	}
+}
+
+// open returns the notification opening the document with the provided
+// URI and text.
+func open(uri, text string) map[string]any {
+	return notification("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "gotmpl", "version": 1, "text": text},
+	})
+}
+
+// labels returns the labels of the items of a completion result.
+func labels(result any) []string {
+	var out []string
+	for _, item := range result.(map[string]any)["items"].([]any) {
+		out = append(out, item.(map[string]any)["label"].(string))
+	}
+	return out
+}
+
+// newModule creates a module with a manifest and a template defining
+// "command", and returns its directory.
+func newModule(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte(`name: github.com/getoutreach/stencil-test
+arguments:
+  team:
+    description: The team owning the service
+    required: true
+  aws.region:
+    description: The AWS region
+`), 0o644))
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "templates", "_helpers.tpl"),
+		[]byte("{{- define \"command\" }}\npackage main\n{{- end }}\n"), 0o644))
+	return dir
+}
+
+func TestInitialize(t *testing.T) {
+	resps := session(t, request(1, "initialize", map[string]any{}), request(2, "unknown", nil), request(3, "shutdown", nil))
+	assert.Equal(t, len(resps), 3)
+
+	caps := resps[0]["result"].(map[string]any)["capabilities"].(map[string]any)
+	assert.Equal(t, caps["hoverProvider"], true)
+	assert.Equal(t, caps["definitionProvider"], true)
+	assert.Equal(t, resps[1]["error"].(map[string]any)["code"], float64(codeMethodNotFound))
+	assert.Equal(t, resps[2]["result"], nil)
+}
+
+func TestDiagnostics(t *testing.T) {
+	dir := newModule(t)
+	uri := pathToURI(filepath.Join(dir, "templates", "a.tpl"))
+	mfURI := pathToURI(filepath.Join(dir, "manifest.yaml"))
+
+	resps := session(t,
+		open(uri, "line\n## <<Stencil::Block(a)>>\nno file block\n"),
+		open(mfURI, "name: a\nunknown: true\n"),
+		notification("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": uri}}),
+	)
+	assert.Equal(t, len(resps), 3)
+
+	params := resps[0]["params"].(map[string]any)
+	assert.Equal(t, resps[0]["method"], "textDocument/publishDiagnostics")
+	assert.Equal(t, params["uri"], uri)
+	diags := params["diagnostics"].([]any)
+	assert.Assert(t, len(diags) > 0)
+	diag := diags[0].(map[string]any)
+	assert.Equal(t, diag["source"], diagnosticSource)
+	assert.DeepEqual(t, diag["range"], map[string]any{
+		"start": map[string]any{"line": float64(1), "character": float64(0)},
+		"end":   map[string]any{"line": float64(1), "character": float64(24)},
+	})
+
+	assert.Assert(t, len(resps[1]["params"].(map[string]any)["diagnostics"].([]any)) > 0)
+	assert.DeepEqual(t, resps[2]["params"].(map[string]any)["diagnostics"], []any{})
+}
+
+func TestCompletion(t *testing.T) {
+	dir := newModule(t)
+	uri := pathToURI(filepath.Join(dir, "templates", "a.tpl"))
+
+	resps := session(t,
+		open(uri, "outside stencil.\n{{ stencil.Ar }}\n{{ stencil.Arg \"aws. }}\n{{ to }}\n"),
+		request(1, "textDocument/completion", position(uri, 0, 16)),
+		request(2, "textDocument/completion", position(uri, 1, 13)),
+		request(3, "textDocument/completion", position(uri, 2, 20)),
+		request(4, "textDocument/completion", position(uri, 3, 5)),
+	)
+	assert.Equal(t, len(resps), 5)
+
+	assert.Equal(t, len(labels(resps[1]["result"])), 0)
+	assert.Assert(t, len(labels(resps[2]["result"])) > 0)
+	assert.Assert(t, slices.Contains(labels(resps[2]["result"]), "Arg"))
+	assert.Assert(t, !slices.Contains(labels(resps[2]["result"]), "Block"))
+
+	assert.DeepEqual(t, labels(resps[3]["result"]), []string{"aws.region", "team"})
+	edit := resps[3]["result"].(map[string]any)["items"].([]any)[0].(map[string]any)["textEdit"]
+	assert.DeepEqual(t, edit, map[string]any{
+		"newText": "aws.region",
+		"range": map[string]any{
+			"start": map[string]any{"line": float64(2), "character": float64(16)},
+			"end":   map[string]any{"line": float64(2), "character": float64(20)},
+		},
+	})
+
+	top := labels(resps[4]["result"])
+	assert.Assert(t, len(top) > 2)
+	assert.DeepEqual(t, top[:2], []string{"file", "stencil"})
+	assert.Assert(t, slices.Contains(top, "toYaml"))
+}
+
+func TestHover(t *testing.T) {
+	dir := newModule(t)
+	uri := pathToURI(filepath.Join(dir, "templates", "a.tpl"))
+
+	resps := session(t,
+		open(uri, "{{ stencil.Arg \"team\" | toYaml }}\n"),
+		request(1, "textDocument/hover", position(uri, 0, 8)),
+		request(2, "textDocument/hover", position(uri, 0, 17)),
+		request(3, "textDocument/hover", position(uri, 0, 1)),
+	)
+	assert.Equal(t, len(resps), 4)
+
+	hover := resps[1]["result"].(map[string]any)
+	assert.Assert(t, bytes.HasPrefix([]byte(hover["contents"].(map[string]any)["value"].(string)),
+		[]byte("```go\nstencil.Arg(pth string) (any, error)\n```")))
+
+	arg := resps[2]["result"].(map[string]any)["contents"].(map[string]any)["value"]
+	assert.Equal(t, arg, "**team** (required)\n\nThe team owning the service")
+	assert.Equal(t, resps[3]["result"], nil)
+}
+
+func TestDefinition(t *testing.T) {
+	dir := newModule(t)
+	uri := pathToURI(filepath.Join(dir, "templates", "a.tpl"))
+
+	resps := session(t,
+		open(uri, "{{ stencil.ApplyTemplate \"command\" }}\n{{ stencil.Arg \"command\" }}\n"),
+		request(1, "textDocument/definition", position(uri, 0, 28)),
+		request(2, "textDocument/definition", position(uri, 1, 18)),
+	)
+	assert.Equal(t, len(resps), 3)
+
+	assert.DeepEqual(t, resps[1]["result"], []any{map[string]any{
+		"uri": pathToURI(filepath.Join(dir, "templates", "_helpers.tpl")),
+		"range": map[string]any{
+			"start": map[string]any{"line": float64(0), "character": float64(12)},
+			"end":   map[string]any{"line": float64(0), "character": float64(19)},
+		},
+	}})
+	assert.DeepEqual(t, resps[2]["result"], []any{})
+}
+
+func TestOffsetAt(t *testing.T) {
+	text := "a😀b\nc"
+	assert.Equal(t, offsetAt(text, Position{Line: 0, Character: 3}), 5)
+	assert.Equal(t, offsetAt(text, Position{Line: 0, Character: 10}), 6)
+	assert.Equal(t, offsetAt(text, Position{Line: 1, Character: 1}), 8)
+	assert.Equal(t, positionAt(text, 5), Position{Line: 0, Character: 3})
+}
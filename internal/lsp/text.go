@@ -0,0 +1,60 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains helpers for converting between LSP
+// positions, which count UTF-16 code units, and byte offsets.
+
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// offsetAt returns the byte offset of pos in text, clamped to the end of
+// its line.
+func offsetAt(text string, pos Position) int {
+	i := 0
+	for line := 0; line < pos.Line; line++ {
+		j := strings.IndexByte(text[i:], '\n')
+		if j < 0 {
+			return len(text)
+		}
+		i += j + 1
+	}
+
+	for col := 0; i < len(text) && text[i] != '\n' && col < pos.Character; {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		col += utf16.RuneLen(r)
+		i += size
+	}
+	return i
+}
+
+// positionAt returns the position of the byte offset off in text.
+func positionAt(text string, off int) Position {
+	start := strings.LastIndexByte(text[:off], '\n') + 1
+	return Position{Line: strings.Count(text[:off], "\n"), Character: utf16Len(text[start:off])}
+}
+
+// lineRange returns the range of the zero-based line in text, excluding
+// the newline.
+func lineRange(text string, line int) Range {
+	lines := strings.Split(text, "\n")
+	if line >= len(lines) {
+		return Range{Start: Position{Line: line}, End: Position{Line: line}}
+	}
+	return Range{
+		Start: Position{Line: line},
+		End:   Position{Line: line, Character: utf16Len(strings.TrimSuffix(lines[line], "\r"))},
+	}
+}
+
+// utf16Len returns the length of s in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements an in-memory cache of resolved
+// modules shared across multiple resolutions.
+
+package modules
+
+import (
+	"sync"
+
+	"github.com/getoutreach/gobox/pkg/cli/updater/resolver"
+	"github.com/go-git/go-billy/v5"
+)
+
+// ResolveCache caches the resolved versions and the filesystems of
+// modules across multiple calls to GetModulesForService, e.g. when
+// rendering several projects in one invocation, so that each module is
+// only resolved and downloaded once. It is safe for concurrent use, and
+// a nil *ResolveCache caches nothing.
+type ResolveCache struct {
+	mu sync.Mutex
+
+	// versions are the resolved versions, keyed by the module ID of the
+	// URI, channel and constraints they were resolved with
+	versions map[string]*resolver.Version
+
+	// filesystems are the filesystems of modules, keyed by their path
+	// slug
+	filesystems map[string]billy.Filesystem
+}
+
+// NewResolveCache returns an empty ResolveCache.
+func NewResolveCache() *ResolveCache {
+	return &ResolveCache{
+		versions:    make(map[string]*resolver.Version),
+		filesystems: make(map[string]billy.Filesystem),
+	}
+}
+
+// version returns the cached version for moduleID.
+func (c *ResolveCache) version(moduleID string) (*resolver.Version, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.versions[moduleID]
+	return v, ok
+}
+
+// setVersion caches v for moduleID.
+func (c *ResolveCache) setVersion(moduleID string, v *resolver.Version) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versions[moduleID] = v
+}
+
+// loadFS sets the filesystem of m to the cached one, if there is one.
+func (c *ResolveCache) loadFS(m *Module) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if fs, ok := c.filesystems[m.PathSlug()]; ok {
+		m.fs = fs
+	}
+}
+
+// storeFS caches the filesystem of m, if it has been fetched.
+func (c *ResolveCache) storeFS(m *Module) {
+	if c == nil || m.fs == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.filesystems[m.PathSlug()] = m.fs
+}
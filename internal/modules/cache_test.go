@@ -0,0 +1,45 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for the resolve cache.
+
+package modules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getoutreach/gobox/pkg/cli/updater/resolver"
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/go-git/go-billy/v5/memfs"
+	"gotest.tools/v3/assert"
+)
+
+func TestResolveCache(t *testing.T) {
+	var nilCache *ResolveCache
+	nilCache.setVersion("a", &resolver.Version{Tag: "v1.0.0"})
+	_, ok := nilCache.version("a")
+	assert.Assert(t, !ok)
+
+	c := NewResolveCache()
+	c.setVersion("a", &resolver.Version{Tag: "v1.0.0"})
+	v, ok := c.version("a")
+	assert.Assert(t, ok)
+	assert.Equal(t, v.Tag, "v1.0.0")
+
+	ctx := context.Background()
+	tr := &configuration.TemplateRepository{Name: "github.com/getoutreach/stencil-base", Version: "v1.0.0"}
+	a, err := New(ctx, "", tr)
+	assert.NilError(t, err)
+	c.storeFS(a)
+
+	// Not fetched yet, nothing to share
+	b, err := New(ctx, "", tr)
+	assert.NilError(t, err)
+	c.loadFS(b)
+	assert.Assert(t, b.fs == nil)
+
+	a.fs = memfs.New()
+	c.storeFS(a)
+	c.loadFS(b)
+	assert.Equal(t, b.fs, a.fs)
+}
@@ -94,6 +94,10 @@ type ModuleResolveOptions struct {
 	// ConcurrentResolvers is the number of concurrent resolvers to use
 	// when resolving modules.
 	ConcurrentResolvers int
+
+	// Cache, if set, is used to share the resolved versions and the
+	// filesystems of modules with other resolutions.
+	Cache *ResolveCache
 }
 
 // GetModulesForService returns a list of modules that have been resolved from the provided
@@ -190,12 +194,14 @@ func work(ctx context.Context, opts *ModuleResolveOptions, item *workItem, wl *w
 		if err != nil {
 			return err
 		}
+		opts.Cache.loadFS(m)
 	}
 
 	mf, err := m.Manifest(ctx)
 	if err != nil {
 		return err
 	}
+	opts.Cache.storeFS(m)
 
 	// add the dependencies of this module to the stack to be resolved
 	for i := range mf.Modules {
@@ -39,6 +39,9 @@ type workList struct {
 	// to track previous resolutions/constraints for re-resolving modules.
 	resolved map[string]*resolvedModule
 
+	// cache, if set, is shared with other resolutions
+	cache *ResolveCache
+
 	// mu protects all the collections in this struct
 	mu  sync.Mutex
 	log logrus.FieldLogger
@@ -93,6 +96,7 @@ func newWorkList(opts *ModuleResolveOptions) workList {
 	return workList{
 		tasks:        modulesToResolve,
 		replacements: strReplacements,
+		cache:        opts.Cache,
 		log:          opts.Log,
 		resolved:     make(map[string]*resolvedModule),
 	}
@@ -201,6 +205,10 @@ func (list *workList) getLatestModuleForConstraints(ctx context.Context, item *w
 
 	versionID := fmt.Sprintf("ch_%s_cons_%v", channel, constraints)
 	moduleID := PathSlug(item.uri, versionID)
+	if v, ok := list.cache.version(moduleID); ok {
+		return v, nil
+	}
+
 	lockDir := VersionLockDir(moduleID)
 	lock, err := exclusiveLockDirectory(lockDir)
 	if err != nil {
@@ -213,7 +221,11 @@ func (list *workList) getLatestModuleForConstraints(ctx context.Context, item *w
 	cacheDir := VersionCacheDir(moduleID)
 	cacheFile := filepath.Join(cacheDir, "version.json")
 	if useCache(cacheDir) {
-		return getCachedVersion(cacheFile)
+		v, err := getCachedVersion(cacheFile)
+		if err == nil {
+			list.cache.setVersion(moduleID, v)
+		}
+		return v, err
 	}
 
 	v, err := resolver.Resolve(ctx, token, &resolver.Criteria{
@@ -247,6 +259,7 @@ func (list *workList) getLatestModuleForConstraints(ctx context.Context, item *w
 	if err != nil {
 		return nil, err
 	}
+	list.cache.setVersion(moduleID, v)
 
 	return v, nil
 }
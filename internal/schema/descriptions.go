@@ -0,0 +1,100 @@
+// Code generated by gendescriptions. DO NOT EDIT.
+
+package schema
+
+// descriptions are the doc comments of the types, keyed by
+// <package>.<type>, and their fields, keyed by <package>.<type>.<field>.
+var descriptions = map[string]string{
+	"configuration.Archetype":                                 "Archetype is a starter project, the modules and arguments of a service.yaml along with the questions to ask when creating a project from it.",
+	"configuration.Archetype.Arguments":                       "Arguments are the arguments set in projects created from this archetype.",
+	"configuration.Archetype.Description":                     "Description is a description of the archetype.",
+	"configuration.Archetype.Modules":                         "Modules are the modules used by projects created from this archetype.",
+	"configuration.Archetype.Name":                            "Name is the name of the archetype.",
+	"configuration.Archetype.Questions":                       "Questions are asked when creating a project from this archetype, in order, and the answers are set as arguments.",
+	"configuration.ArchetypeCatalog":                          "ArchetypeCatalog is a file listing archetypes.",
+	"configuration.ArchetypeCatalog.Archetypes":               "Archetypes are the archetypes in the catalog.",
+	"configuration.ArchetypeQuestion":                         "ArchetypeQuestion is a question asked when creating a project from an archetype.",
+	"configuration.ArchetypeQuestion.Argument":                "Argument is the argument, in dot notation, the answer is set as.",
+	"configuration.ArchetypeQuestion.Default":                 "Default is the default answer, used as-is when not running in a terminal.",
+	"configuration.ArchetypeQuestion.Description":             "Description is shown underneath the question.",
+	"configuration.ArchetypeQuestion.Message":                 "Message is the question shown to the user, defaults to the name of the argument.",
+	"configuration.ArchetypeQuestion.Schema":                  "Schema is a JSON schema, in YAML, the answer is validated against. The values of its enum are offered as choices.",
+	"configuration.Argument":                                  "Argument is a user-input argument that can be passed to templates.",
+	"configuration.Argument.Default":                          "Default is the default value for this argument if it's not set. This cannot be set when required is true.",
+	"configuration.Argument.Deprecated":                       "When non-empty, this marks the argument as deprecated and is the human-readable migration message shown to consumers. An empty or absent value means the argument is not deprecated.\n\nIn YAML this must be a string, e.g. `deprecated: \"Use newArg instead.\"`. The bool form (`deprecated: true`) is rejected by DeprecationMessage.UnmarshalYAML; authors must supply a message string.",
+	"configuration.Argument.Description":                      "Description is a description of this argument.",
+	"configuration.Argument.From":                             "From is a reference to an argument in another module, if this is set, all other fields are ignored and instead the module referenced field's are used instead. The name of the argument, the key in the map, must be the same across both modules.",
+	"configuration.Argument.Required":                         "Required denotes this argument as required.",
+	"configuration.Argument.Schema":                           "Schema is a JSON schema, in YAML, for the argument.",
+	"configuration.Argument.Type":                             "Deprecated: Use schema instead Type declares the type of the argument. This is not implemented yet, so is likely to change in the future.",
+	"configuration.Argument.Values":                           "Deprecated: Use schema instead. Values is a list of possible values for this, if empty all input is considered valid.",
+	"configuration.Capability":                                "Capability is a privileged operation that a module must request in its manifest.yaml, and a service must grant in its service.yaml, before stencil will perform it on the module's behalf.",
+	"configuration.DeprecationMessage":                        "DeprecationMessage is an argument's deprecation message. It is a string: non-empty means the argument is deprecated and this is the migration message shown to consumers; empty means not deprecated. Its custom UnmarshalYAML rejects non-string YAML scalars (e.g. the legacy `deprecated: true` bool form) so authors must supply a message string rather than a bare bool.",
+	"configuration.ModuleFileReader":                          "ModuleFileReader returns the contents of the file at path, relative to the root of the provided version of module. An empty version is the latest version.",
+	"configuration.Permissions":                               "Permissions grants capabilities to modules rendered into a service.",
+	"configuration.Permissions.Modules":                       "Modules is a map of module import path to the capabilities granted to that module.",
+	"configuration.Permissions.TrustedModules":                "TrustedModules is a list of module import paths that are granted every capability they request.",
+	"configuration.PostRunCommandCondition":                   "PostRunCommandCondition is a condition that determines if a post-run command should be ran.",
+	"configuration.PostRunCommandCondition.FilesChanged":      "FilesChanged is a list of globs, relative to the project root, the command is only ran if a file matching one of them was created, updated or deleted by the render. \"**\" matches any number of directories, and a glob without a \"/\" matches the file name in any directory.",
+	"configuration.PostRunCommandRef":                         "PostRunCommandRef is a reference to the post-run command(s) of a module.",
+	"configuration.PostRunCommandRef.Module":                  "Module is the import path of the module that declares the command",
+	"configuration.PostRunCommandRef.Name":                    "Name is the name of the command, if not set all of the module's commands are referenced.",
+	"configuration.PostRunCommandSpec":                        "PostRunCommandSpec is the spec of a command to be ran and its friendly name.",
+	"configuration.PostRunCommandSpec.After":                  "After is a list of post-run commands, from any module, that must be ran before this command. By default commands are ran in module order.",
+	"configuration.PostRunCommandSpec.Command":                "Command is the command to be ran, note: this is ran inside of a bash shell.",
+	"configuration.PostRunCommandSpec.ContinueOnError":        "ContinueOnError denotes that a failure of this command should be reported, but not stop the remaining commands from being ran or fail the stencil run.",
+	"configuration.PostRunCommandSpec.Dir":                    "Dir is the working directory, relative to the project root, that the command is ran in. Defaults to the project root.",
+	"configuration.PostRunCommandSpec.Env":                    "Env is a map of environment variables to set, in addition to the environment stencil was ran with, when running the command.",
+	"configuration.PostRunCommandSpec.Name":                   "Name is the name of the command being ran, used for UX",
+	"configuration.PostRunCommandSpec.Timeout":                "Timeout is the maximum duration (e.g. \"5m\") the command is allowed to run for. Defaults to no timeout.",
+	"configuration.PostRunCommandSpec.When":                   "When is a condition that must be met for this command to be ran, if not set the command is always ran.",
+	"configuration.ServiceManifest":                           "ServiceManifest is a manifest used to describe a service and impact what files are included.",
+	"configuration.ServiceManifest.Arguments":                 "Arguments is a map of arbitrary arguments to pass to the generator",
+	"configuration.ServiceManifest.DefaultBranch":             "DefaultBranch is the default branch of the git repository, exposed to templates as .Git.DefaultBranch. Detected from the remote origin if not set.",
+	"configuration.ServiceManifest.Extends":                   "Extends are the service manifests this service manifest inherits from, see LoadServiceManifest.",
+	"configuration.ServiceManifest.Modules":                   "Modules are the template modules that this service depends on and utilizes",
+	"configuration.ServiceManifest.Name":                      "Name is the name of the service",
+	"configuration.ServiceManifest.Permissions":               "Permissions grants modules the capabilities they request in their manifest.yaml, see Capability.",
+	"configuration.ServiceManifest.Replacements":              "Replacements is a list of module names to replace their URI. Expected format: - local file: file://path/to/module - remote file: https://github.com/getoutreach/stencil-base",
+	"configuration.ServiceManifest.Versions":                  "Versions is a map of versions of certain tools, this is used by templates and will likely be replaced with something better in the future.",
+	"configuration.ServiceManifestBase":                       "ServiceManifestBase is a service manifest extended by another, either a path on disk or a file inside of a module. In YAML it can be written as a string, which is treated as Path.",
+	"configuration.ServiceManifestBase.Module":                "Module is the import path of the module containing the service manifest, e.g. github.com/getoutreach/stencil-base.",
+	"configuration.ServiceManifestBase.Path":                  "Path is the path to the service manifest. Relative paths are relative to the extending service manifest, or to the root of Module if set.",
+	"configuration.ServiceManifestBase.Version":               "Version is the version of Module to read the service manifest from, the latest version if not set.",
+	"configuration.ServiceManifestDocument":                   "ServiceManifestDocument is a service manifest parsed into a yaml.Node tree. Unlike ServiceManifest, editing it preserves the comments and the order of the keys in the file.",
+	"configuration.TemplateRepository":                        "TemplateRepository is a repository of template files.",
+	"configuration.TemplateRepository.Channel":                "Channel is the channel to use for updates to this module Defaults to \"stable\"",
+	"configuration.TemplateRepository.Name":                   "Name is the name of this module. This should be a valid go import path",
+	"configuration.TemplateRepository.Prerelease":             "Deprecated: Use 'channel' instead, prerelease sets 'channel' to 'rc'. Prerelease is a boolean indicating whether or not to consider prerelease versions",
+	"configuration.TemplateRepository.URL":                    "Deprecated: Use name instead URL is a full URL for a given module",
+	"configuration.TemplateRepository.Version":                "Version is a semantic version or branch of the template repository that should be downloaded if not set then the latest version is used.\n\nVersion can also be a constraint as supported by the underlying resolver: https://pkg.go.dev/github.com/getoutreach/gobox/pkg/cli/updater/resolver#Resolve",
+	"configuration.TemplateRepositoryManifest":                "TemplateRepositoryManifest is a manifest of a template repository.",
+	"configuration.TemplateRepositoryManifest.Archetypes":     "Archetypes are starter projects offered by `stencil create project` when creating a project from this module.",
+	"configuration.TemplateRepositoryManifest.Arguments":      "Arguments are a declaration of arguments to the template generator",
+	"configuration.TemplateRepositoryManifest.Capabilities":   "Capabilities are the privileged operations this module needs, which must be granted by the service.yaml before stencil performs them. Capabilities implied by other fields (e.g. PostRunCommand) do not need to be listed, see RequestedCapabilities.",
+	"configuration.TemplateRepositoryManifest.CommentStyles":  "CommentStyles maps file extensions (e.g. \".clj\"), or file names, to an additional comment prefix that block tags can be written after in files rendered by this module, e.g. \";;\". These extend the default comment styles.",
+	"configuration.TemplateRepositoryManifest.Modules":        "Modules are template repositories that this manifest requires",
+	"configuration.TemplateRepositoryManifest.Name":           "Name is the name of this template repository. This must match the import path.",
+	"configuration.TemplateRepositoryManifest.PostRunCommand": "PostRunCommand is a command to be ran after rendering and post-processors have been ran on the project",
+	"configuration.TemplateRepositoryManifest.StencilVersion": "StencilVersion is the version constraint which describes what versions of Stencil can render this module. It conforms to the constraint syntax as supported by github.com/Masterminds/semver/v3.",
+	"configuration.TemplateRepositoryManifest.Type":           "Type stores a comma-separated list of template repository types served by the current module. Use the TemplateRepositoryTypes.Contains method to check.",
+	"configuration.TemplateRepositoryType":                    "TemplateRepositoryType specifies what type of a stencil repository the current one is.",
+	"configuration.TemplateRepositoryTypes":                   "TemplateRepositoryTypes specifies what type of a stencil repository the current one is. Use Contains to check for a type - it has special handling for the default case. Even though it is a struct, it is marshalled and unmarshalled as a string with comma separated values of TemplateRepositoryType.",
+	"stencil.Lockfile":                                        "Lockfile is generated by stencil on a ran to store version information.",
+	"stencil.Lockfile.Files":                                  "Files is a list of files and metadata about them that were generated by stencil",
+	"stencil.Lockfile.Modules":                                "Modules is a list of modules and their versions that was used the last time stencil was ran. Note: This is only set in stencil.lock",
+	"stencil.Lockfile.Version":                                "Version correlates to the version of bootstrap that generated this file.",
+	"stencil.LockfileFileEntry":                               "LockfileFileEntry is an entry in the lockfile for a file that was generated by stencil. This contains metadata on what generated it among other future information.",
+	"stencil.LockfileFileEntry.Keys":                          "Keys are the keys of a structured file merged via file.Merge that were rendered by its fragments, as JSON pointers (RFC 6901). Keys that are no longer rendered are removed from the file on the next run.",
+	"stencil.LockfileFileEntry.Module":                        "Module is the URL of the module that generated this file.",
+	"stencil.LockfileFileEntry.Name":                          "Name is the relative file path, to the invocation of stencil, of the generated file",
+	"stencil.LockfileFileEntry.Target":                        "Target is the target of the symlink, only set when Type is LockfileFileTypeSymlink.",
+	"stencil.LockfileFileEntry.Template":                      "Template is the template that generated this file in the given module.",
+	"stencil.LockfileFileEntry.Type":                          "Type is the type of the generated file, an empty type denotes a file rendered from a template.",
+	"stencil.LockfileFileType":                                "LockfileFileType is the type of a file generated by stencil.",
+	"stencil.LockfileModuleEntry":                             "LockfileModuleEntry is an entry in the lockfile for a module that was used during the last run of stencil.",
+	"stencil.LockfileModuleEntry.Capabilities":                "Capabilities are the capabilities that were granted to the module the last time stencil was ran.",
+	"stencil.LockfileModuleEntry.Name":                        "Name is the name of the module. This usually comes from the TemplateManifest entry, but is up to the module package.",
+	"stencil.LockfileModuleEntry.URL":                         "URL is the url of the module that was used.",
+	"stencil.LockfileModuleEntry.Version":                     "Version is the version of the module that was downloaded at the time.",
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file generates the descriptions used by the JSON
+// schemas from the doc comments of the types in the provided packages.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// output is the file the descriptions are written to.
+const output = "descriptions.go"
+
+// generate returns the source of the file containing the descriptions of
+// the exported types, and their fields, in the packages in dirs.
+func generate(dirs []string) ([]byte, error) {
+	descriptions := make(map[string]string)
+	for _, dir := range dirs {
+		fset := token.NewFileSet()
+		pkgs, err := parser.ParseDir(fset, dir, func(fi fs.FileInfo) bool {
+			return !strings.HasSuffix(fi.Name(), "_test.go")
+		}, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pkg := range pkgs {
+			for _, f := range pkg.Files {
+				addDescriptions(descriptions, pkg.Name, f)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(descriptions))
+	for k := range descriptions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gendescriptions. DO NOT EDIT.\n\n")
+	buf.WriteString("package schema\n\n")
+	buf.WriteString("// descriptions are the doc comments of the types, keyed by\n")
+	buf.WriteString("// <package>.<type>, and their fields, keyed by <package>.<type>.<field>.\n")
+	buf.WriteString("var descriptions = map[string]string{\n")
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s: %s,\n", strconv.Quote(k), strconv.Quote(descriptions[k]))
+	}
+	buf.WriteString("}\n")
+	return format.Source(buf.Bytes())
+}
+
+// addDescriptions adds the descriptions of the exported types, and their
+// fields, declared in f to descriptions.
+func addDescriptions(descriptions map[string]string, pkg string, f *ast.File) {
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gd.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || !ts.Name.IsExported() {
+				continue
+			}
+
+			doc := ts.Doc
+			if doc == nil && len(gd.Specs) == 1 {
+				doc = gd.Doc
+			}
+			key := pkg + "." + ts.Name.Name
+			if d := commentText(doc); d != "" {
+				descriptions[key] = d
+			}
+
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				d := commentText(field.Doc)
+				if d == "" {
+					continue
+				}
+				for _, name := range field.Names {
+					if name.IsExported() {
+						descriptions[key+"."+name.Name] = d
+					}
+				}
+			}
+		}
+	}
+}
+
+// commentText returns the text of c, with the lines of each paragraph
+// joined. Directives, e.g. //nolint, are dropped.
+func commentText(c *ast.CommentGroup) string {
+	if c == nil {
+		return ""
+	}
+
+	var paragraphs []string
+	for _, p := range strings.Split(strings.TrimSpace(c.Text()), "\n\n") {
+		if p = strings.Join(strings.Fields(p), " "); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func main() {
+	b, err := generate(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(output, b, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file ensures the generated descriptions are up to date.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDescriptionsUpToDate(t *testing.T) {
+	want, err := generate([]string{"../../../pkg/configuration", "../../../pkg/stencil"})
+	assert.NilError(t, err)
+
+	got, err := os.ReadFile(filepath.Join("..", output))
+	assert.NilError(t, err)
+	assert.Equal(t, string(got), string(want), "descriptions are out of date, run go generate ./internal/schema")
+}
@@ -0,0 +1,368 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file generates JSON schemas for the service.yaml,
+// manifest.yaml and stencil.lock from the Go types they're decoded into.
+
+//go:generate go run ./gendescriptions ../../pkg/configuration ../../pkg/stencil
+
+// Package schema implements generating JSON schemas for the files stencil
+// reads, for use by editors and other tools.
+package schema
+
+import (
+	"encoding/json"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"github.com/getoutreach/stencil/pkg/stencil"
+)
+
+// Draft is the JSON schema draft the schemas conform to.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// BaseURL is the URL the schemas are published under.
+const BaseURL = "https://engineering.outreach.io/stencil/schemas/"
+
+// Schema is a JSON schema, limited to the keywords stencil uses.
+type Schema struct {
+	Schema      string    `json:"$schema,omitempty"`
+	ID          string    `json:"$id,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Deprecated  bool      `json:"deprecated,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Pattern     string    `json:"pattern,omitempty"`
+	Enum        []any     `json:"enum,omitempty"`
+	OneOf       []*Schema `json:"oneOf,omitempty"`
+	AnyOf       []*Schema `json:"anyOf,omitempty"`
+	Not         *Schema   `json:"not,omitempty"`
+
+	// Ref is the URI of a schema values must also conform to
+	Ref string `json:"$ref,omitempty"`
+
+	// Defs are schemas referenced by Ref
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+
+	// If and Else are conditional schemas: values not conforming to If
+	// must conform to Else
+	If   *Schema `json:"if,omitempty"`
+	Else *Schema `json:"else,omitempty"`
+
+	// Properties are the schemas of the properties of an object
+	Properties map[string]*Schema `json:"properties,omitempty"`
+
+	// Required are the properties an object must have
+	Required []string `json:"required,omitempty"`
+
+	// AdditionalProperties is the schema of the properties of an object
+	// that aren't in Properties, false if they're not allowed. Any value
+	// is allowed if nil.
+	AdditionalProperties any `json:"additionalProperties,omitempty"`
+
+	// Items is the schema of the items of an array
+	Items *Schema `json:"items,omitempty"`
+
+	// Contains is a schema at least one of the items of an array must
+	// conform to
+	Contains *Schema `json:"contains,omitempty"`
+
+	// Raw is used as-is instead of the other fields if set, e.g. for
+	// the schemas of arguments declared by modules
+	Raw map[string]any `json:"-"`
+}
+
+// MarshalJSON encodes s, or s.Raw if it's set.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.Raw != nil {
+		return json.Marshal(s.Raw)
+	}
+
+	// Use a type without MarshalJSON to avoid recursing
+	type schema Schema
+	return json.Marshal((*schema)(s))
+}
+
+// File is a file stencil reads that a schema can be generated for.
+type File string
+
+// This block contains all of the File values.
+const (
+	// FileServiceManifest is the service.yaml of a project.
+	FileServiceManifest File = "service.yaml"
+
+	// FileTemplateRepositoryManifest is the manifest.yaml of a module.
+	FileTemplateRepositoryManifest File = "manifest.yaml"
+
+	// FileLockfile is the stencil.lock of a project.
+	FileLockfile File = "stencil.lock"
+)
+
+// Files are all of the files a schema can be generated for.
+var Files = []File{FileServiceManifest, FileTemplateRepositoryManifest, FileLockfile}
+
+// For returns the schema of f.
+func For(f File) *Schema {
+	var s *Schema
+	switch f {
+	case FileServiceManifest:
+		s = reflectType(reflect.TypeFor[configuration.ServiceManifest]())
+	case FileTemplateRepositoryManifest:
+		s = reflectType(reflect.TypeFor[configuration.TemplateRepositoryManifest]())
+	case FileLockfile:
+		s = reflectType(reflect.TypeFor[stencil.Lockfile]())
+	default:
+		return nil
+	}
+
+	s.Schema = Draft
+	s.ID = BaseURL + string(f) + ".json"
+	s.Title = string(f)
+	return s
+}
+
+// ServiceManifestForModules returns the schema of a service.yaml that
+// uses the modules with the provided manifests, which includes the
+// arguments they declare. When more than one module declares an
+// argument, the first one is used.
+//
+// Like the linter, arguments may be set to a value containing a
+// reference, see configuration.ArgumentReferencePattern, and required
+// arguments aren't required when they may be provided by a service
+// manifest the service.yaml extends or by a file referenced by the
+// arguments.
+func ServiceManifestForModules(manifests []*configuration.TemplateRepositoryManifest) *Schema {
+	s := For(FileServiceManifest)
+	s.ID = ""
+	fileRef := &Schema{Type: "object", Required: []string{configuration.ArgumentRefKey}}
+	s.Defs = map[string]*Schema{
+		argumentReferenceDef: containsSchema(argumentReferenceDef,
+			&Schema{Type: "string", Pattern: configuration.ArgumentReferencePattern}, fileRef),
+		argumentFileReferenceDef: containsSchema(argumentFileReferenceDef, fileRef),
+	}
+
+	args := &Schema{
+		Type:        "object",
+		Description: s.Properties["arguments"].Description,
+		Properties:  make(map[string]*Schema),
+	}
+	var required []string
+	for _, mf := range manifests {
+		for name, arg := range mf.Arguments {
+			// Arguments from another module are declared by that module
+			if _, ok := args.Properties[name]; ok || arg.From != "" {
+				continue
+			}
+
+			args.Properties[name] = argumentSchema(mf.Name, &arg)
+			if arg.Required && arg.Default == nil {
+				required = append(required, name)
+			}
+		}
+	}
+	s.Properties["arguments"] = args
+
+	if len(required) > 0 {
+		slices.Sort(required)
+		s.If = &Schema{Required: []string{"extends"}}
+		s.Else = &Schema{Properties: map[string]*Schema{
+			"arguments": {
+				If:   &Schema{Ref: "#/$defs/" + argumentFileReferenceDef},
+				Else: &Schema{Required: required},
+			},
+		}}
+	}
+	return s
+}
+
+// These are the names of the definitions in the schema of a service.yaml
+// returned by ServiceManifestForModules.
+const (
+	// argumentReferenceDef is the schema of argument values containing a
+	// reference, as reported by codegen.ContainsArgumentReference.
+	argumentReferenceDef = "argumentReference"
+
+	// argumentFileReferenceDef is the schema of argument values containing
+	// a mapping with a $ref key.
+	argumentFileReferenceDef = "argumentFileReference"
+)
+
+// containsSchema returns the schema of values conforming to one of
+// leaves, or mappings or arrays with a value that conforms to the schema
+// def, the name of the returned schema.
+func containsSchema(def string, leaves ...*Schema) *Schema {
+	ref := &Schema{Ref: "#/$defs/" + def}
+	return &Schema{AnyOf: append(leaves,
+		&Schema{Type: "object", Not: &Schema{AdditionalProperties: &Schema{Not: ref}}},
+		&Schema{Type: "array", Contains: ref},
+	)}
+}
+
+// argumentSchema returns the schema of arg, declared by module. Values
+// containing a reference are allowed, since they're only known once
+// resolved.
+func argumentSchema(module string, arg *configuration.Argument) *Schema {
+	raw := maps.Clone(arg.Schema)
+	if raw == nil {
+		raw = make(map[string]any)
+	}
+	if _, ok := raw["enum"]; !ok && len(arg.Values) > 0 {
+		raw["enum"] = arg.Values
+	}
+	if _, ok := raw["default"]; !ok && arg.Default != nil {
+		raw["default"] = arg.Default
+	}
+
+	desc := strings.TrimSpace(arg.Description)
+	if arg.Deprecated != "" {
+		raw["deprecated"] = true
+		desc = strings.TrimSpace(desc + "\n\nDeprecated: " + string(arg.Deprecated))
+	}
+	if _, ok := raw["description"]; !ok {
+		raw["description"] = strings.TrimSpace(desc + "\n\nDeclared by " + module + ".")
+	}
+
+	// Editors show the description of the outermost schema
+	s := &Schema{AnyOf: []*Schema{{Raw: raw}, {Ref: "#/$defs/" + argumentReferenceDef}}}
+	s.Description, _ = raw["description"].(string)
+	s.Deprecated, _ = raw["deprecated"].(bool)
+	return s
+}
+
+// durationPattern matches a duration as parsed by time.ParseDuration.
+const durationPattern = `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// enums are the values of the string types that are enumerations.
+var enums = map[reflect.Type][]any{
+	reflect.TypeFor[configuration.Capability](): {
+		configuration.CapabilityPostRunCommands,
+		configuration.CapabilityNativeExtensions,
+		configuration.CapabilityDeleteFiles,
+		configuration.CapabilityWriteOutsideProject,
+	},
+	reflect.TypeFor[stencil.LockfileFileType](): {
+		stencil.LockfileFileTypeAsset,
+		stencil.LockfileFileTypeSymlink,
+	},
+}
+
+// requiredFields are the fields, by their YAML name, that must be set in
+// the structs they're in. The name of a service manifest isn't required,
+// shared service manifests used with extends don't have one, it's checked
+// once the service manifest is loaded instead.
+var requiredFields = map[reflect.Type][]string{
+	reflect.TypeFor[configuration.TemplateRepository]():  {"name"},
+	reflect.TypeFor[configuration.PostRunCommandSpec]():  {"name", "command"},
+	reflect.TypeFor[configuration.PostRunCommandRef]():   {"module"},
+	reflect.TypeFor[configuration.Archetype]():           {"name"},
+	reflect.TypeFor[configuration.ArchetypeQuestion]():   {"argument"},
+	reflect.TypeFor[configuration.ServiceManifestBase](): {"path"},
+}
+
+// reflectType returns the schema of the values of t, as decoded from
+// YAML.
+func reflectType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	s := reflectKind(t)
+	if d := description(t, ""); d != "" && s.Description == "" {
+		s.Description = d
+	}
+	return s
+}
+
+// reflectKind returns the schema of the values of t, without its
+// description.
+func reflectKind(t reflect.Type) *Schema {
+	switch t {
+	case reflect.TypeFor[time.Duration]():
+		return &Schema{Type: "string", Pattern: durationPattern}
+	case reflect.TypeFor[configuration.TemplateRepositoryTypes]():
+		return &Schema{Type: "string", Pattern: `^((extension|templates)(,(extension|templates))*)?$`}
+	case reflect.TypeFor[configuration.ServiceManifestBase]():
+		return &Schema{OneOf: []*Schema{
+			{Type: "string", Description: description(t, "Path")},
+			reflectStruct(t),
+		}}
+	}
+
+	if values, ok := enums[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return reflectStruct(t)
+	case reflect.Map:
+		s := &Schema{Type: "object"}
+		if elem := reflectType(t.Elem()); !isEmpty(elem) {
+			s.AdditionalProperties = elem
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: reflectType(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		// Any value
+		return &Schema{}
+	}
+}
+
+// reflectStruct returns the schema of the struct t, an object with a
+// property for each of its exported fields.
+func reflectStruct(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		Required:             requiredFields[t],
+		AdditionalProperties: false,
+	}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			// The default of go.yaml.in/yaml/v3
+			name = strings.ToLower(f.Name)
+		}
+
+		fs := reflectType(f.Type)
+		if d := description(t, f.Name); d != "" {
+			fs.Description = d
+			fs.Deprecated = strings.HasPrefix(d, "Deprecated:")
+		}
+		s.Properties[name] = fs
+	}
+	return s
+}
+
+// description returns the doc comment of the field of t, or of t itself
+// if field is empty.
+func description(t reflect.Type, field string) string {
+	key := path.Base(t.PkgPath()) + "." + t.Name()
+	if field != "" {
+		key += "." + field
+	}
+	return descriptions[key]
+}
+
+// isEmpty returns true if s allows any value.
+func isEmpty(s *Schema) bool {
+	return reflect.DeepEqual(s, &Schema{Description: s.Description})
+}
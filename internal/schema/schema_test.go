@@ -0,0 +1,152 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for the JSON schemas.
+
+package schema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"go.yaml.in/yaml/v3"
+	"gotest.tools/v3/assert"
+
+	"github.com/getoutreach/stencil/internal/schema"
+	"github.com/getoutreach/stencil/pkg/configuration"
+)
+
+// validate validates the YAML document doc against s.
+func validate(t *testing.T, s *schema.Schema, doc string) error {
+	t.Helper()
+
+	b, err := json.Marshal(s)
+	assert.NilError(t, err)
+
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft2020
+	assert.NilError(t, c.AddResource("schema.json", bytes.NewReader(b)))
+	compiled, err := c.Compile("schema.json")
+	assert.NilError(t, err)
+
+	var v any
+	assert.NilError(t, yaml.Unmarshal([]byte(doc), &v))
+	return compiled.Validate(v)
+}
+
+func TestServiceManifestSchema(t *testing.T) {
+	s := schema.For(schema.FileServiceManifest)
+	assert.Equal(t, s.ID, schema.BaseURL+"service.yaml.json")
+	assert.Equal(t, s.Properties["name"].Description, "Name is the name of the service")
+	assert.Assert(t, s.Properties["modules"].Items.Properties["url"].Deprecated)
+
+	assert.NilError(t, validate(t, s, `name: a
+extends:
+  - ../base.yaml
+  - module: github.com/getoutreach/stencil-base
+    path: bases/service.yaml
+arguments:
+  anything: [1, 2]
+modules:
+  - name: github.com/getoutreach/stencil-base
+    version: ">=1.0.0"
+permissions:
+  modules:
+    github.com/getoutreach/stencil-golang: [postRunCommands]
+`))
+	assert.ErrorContains(t, validate(t, s, "name: a\nmodule: []\n"), "additionalProperties 'module' not allowed")
+	// Shared service manifests used with extends don't have a name
+	assert.NilError(t, validate(t, s, "arguments:\n  team: devx\n"))
+	assert.ErrorContains(t, validate(t, s, "modules:\n  - version: v1.0.0\n"), "missing properties: 'name'")
+	assert.ErrorContains(t, validate(t, s, "name: a\npermissions:\n  trustedModules: [a]\n  modules:\n    a: [everything]\n"),
+		"value must be one of")
+}
+
+func TestTemplateRepositoryManifestSchema(t *testing.T) {
+	s := schema.For(schema.FileTemplateRepositoryManifest)
+	assert.NilError(t, validate(t, s, `name: github.com/getoutreach/stencil-base
+type: templates,extension
+postRunCommand:
+  - name: tidy
+    command: go mod tidy
+    timeout: 1m30s
+arguments:
+  team:
+    description: The team
+    required: true
+    schema:
+      type: string
+`))
+	assert.ErrorContains(t, validate(t, s, "name: a\ntype: plugin\n"), "does not match pattern")
+	assert.ErrorContains(t, validate(t, s, "name: a\npostRunCommand:\n  - name: a\n    command: a\n    timeout: soon\n"),
+		"does not match pattern")
+}
+
+func TestLockfileSchema(t *testing.T) {
+	s := schema.For(schema.FileLockfile)
+	assert.NilError(t, validate(t, s, `version: v1.0.0
+modules:
+  - name: github.com/getoutreach/stencil-base
+    url: https://github.com/getoutreach/stencil-base
+    version: v1.2.3
+files:
+  - name: README.md
+    template: README.md.tpl
+    module: github.com/getoutreach/stencil-base
+  - name: LICENSE
+    type: symlink
+    target: ../LICENSE
+`))
+}
+
+func TestServiceManifestForModules(t *testing.T) {
+	s := schema.ServiceManifestForModules([]*configuration.TemplateRepositoryManifest{
+		{
+			Name: "github.com/getoutreach/stencil-base",
+			Arguments: map[string]configuration.Argument{
+				"team":    {Description: "The team owning the service", Required: true, Schema: map[string]any{"type": "string"}},
+				"tier":    {Values: []string{"gold", "silver"}, Default: "silver"},
+				"oldName": {Deprecated: "Use name instead."},
+			},
+		},
+		{
+			Name: "github.com/getoutreach/stencil-golang",
+			Arguments: map[string]configuration.Argument{
+				"team": {From: "github.com/getoutreach/stencil-base"},
+			},
+		},
+	})
+	assert.Equal(t, s.ID, "")
+
+	args := s.Properties["arguments"]
+	assert.DeepEqual(t, args.Properties["team"].AnyOf[0].Raw, map[string]any{
+		"type":        "string",
+		"description": "The team owning the service\n\nDeclared by github.com/getoutreach/stencil-base.",
+	})
+	assert.DeepEqual(t, args.Properties["oldName"].AnyOf[0].Raw, map[string]any{
+		"deprecated":  true,
+		"description": "Deprecated: Use name instead.\n\nDeclared by github.com/getoutreach/stencil-base.",
+	})
+	assert.Equal(t, args.Properties["oldName"].Description,
+		"Deprecated: Use name instead.\n\nDeclared by github.com/getoutreach/stencil-base.")
+	assert.Assert(t, args.Properties["oldName"].Deprecated)
+
+	assert.NilError(t, validate(t, s, "name: a\narguments:\n  team: platform\n  tier: gold\n  other: true\n"))
+	assert.ErrorContains(t, validate(t, s, "name: a\narguments:\n  tier: gold\n"), "missing properties: 'team'")
+	assert.ErrorContains(t, validate(t, s, "name: a\narguments:\n  team: platform\n  tier: bronze\n"),
+		"value must be one of")
+
+	// Values containing references are only known once resolved
+	assert.NilError(t, validate(t, s, "name: a\narguments:\n  team: ${env:TEAM}\n  tier: ${secret:tier}\n"))
+	assert.NilError(t, validate(t, s, "name: a\narguments:\n  team: {$ref: team.yaml}\n  tier:\n    - ${env:TIER}\n"))
+	assert.ErrorContains(t, validate(t, s, "name: a\narguments:\n  team: platform\n  tier: $TIER\n"),
+		"value must be one of")
+
+	// Required arguments may be set by a base or a referenced file
+	assert.NilError(t, validate(t, s, "name: a\nextends: [../base.yaml]\narguments:\n  tier: gold\n"))
+	assert.NilError(t, validate(t, s, "name: a\narguments:\n  $ref: args.yaml\n"))
+	assert.NilError(t, validate(t, s, "name: a\narguments:\n  aws: {$ref: aws.yaml}\n"))
+	assert.ErrorContains(t, validate(t, s, "name: a\narguments:\n  tier: ${env:TIER}\n"),
+		"missing properties: 'team'")
+}
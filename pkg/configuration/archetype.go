@@ -0,0 +1,78 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains the archetypes used to create projects
+// with `stencil create project`.
+
+package configuration
+
+import (
+	"fmt"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Archetype is a starter project, the modules and arguments of a
+// service.yaml along with the questions to ask when creating a project
+// from it.
+type Archetype struct {
+	// Name is the name of the archetype.
+	Name string `yaml:"name"`
+
+	// Description is a description of the archetype.
+	Description string `yaml:"description,omitempty"`
+
+	// Modules are the modules used by projects created from this
+	// archetype.
+	Modules []*TemplateRepository `yaml:"modules"`
+
+	// Arguments are the arguments set in projects created from this
+	// archetype.
+	Arguments map[string]any `yaml:"arguments,omitempty"`
+
+	// Questions are asked when creating a project from this archetype,
+	// in order, and the answers are set as arguments.
+	Questions []*ArchetypeQuestion `yaml:"questions,omitempty"`
+}
+
+// ArchetypeQuestion is a question asked when creating a project from an
+// archetype.
+type ArchetypeQuestion struct {
+	// Argument is the argument, in dot notation, the answer is set as.
+	Argument string `yaml:"argument"`
+
+	// Message is the question shown to the user, defaults to the name
+	// of the argument.
+	Message string `yaml:"message,omitempty"`
+
+	// Description is shown underneath the question.
+	Description string `yaml:"description,omitempty"`
+
+	// Default is the default answer, used as-is when not running in a
+	// terminal.
+	Default any `yaml:"default,omitempty"`
+
+	// Schema is a JSON schema, in YAML, the answer is validated against.
+	// The values of its enum are offered as choices.
+	Schema map[string]any `yaml:"schema,omitempty"`
+}
+
+// ArchetypeCatalog is a file listing archetypes.
+type ArchetypeCatalog struct {
+	// Archetypes are the archetypes in the catalog.
+	Archetypes []*Archetype `yaml:"archetypes"`
+}
+
+// LoadArchetypeCatalog reads the archetype catalog at path.
+func LoadArchetypeCatalog(path string) (*ArchetypeCatalog, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c ArchetypeCatalog
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse archetype catalog %q: %w", path, err)
+	}
+	return &c, nil
+}
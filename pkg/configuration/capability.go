@@ -0,0 +1,103 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: capabilities requested by modules and granted by services
+
+package configuration
+
+import (
+	"slices"
+	"sort"
+)
+
+// Capability is a privileged operation that a module must request in its
+// manifest.yaml, and a service must grant in its service.yaml, before
+// stencil will perform it on the module's behalf.
+type Capability string
+
+// This block contains all of the Capability values.
+const (
+	// CapabilityPostRunCommands allows a module to run its postRunCommand
+	// entries, through bash, after rendering. Implied by a manifest that
+	// declares any postRunCommand.
+	CapabilityPostRunCommands Capability = "postRunCommands"
+
+	// CapabilityNativeExtensions allows a module to download and execute
+	// its native extension binary. Implied by a manifest whose type
+	// contains "extension".
+	CapabilityNativeExtensions Capability = "nativeExtensions"
+
+	// CapabilityDeleteFiles allows a module's templates to delete arbitrary
+	// paths from the project, e.g. via file.RemoveAll.
+	CapabilityDeleteFiles Capability = "deleteFiles"
+
+	// CapabilityWriteOutsideProject allows a module's templates to write files
+	// outside of the project directory (absolute paths, or paths containing
+	// "..").
+	CapabilityWriteOutsideProject Capability = "writeOutsideProject"
+)
+
+// capabilityDescriptions is a human-readable description of what each
+// capability allows, used when asking for consent.
+//
+//nolint:gochecknoglobals // Why: static lookup table of capability descriptions.
+var capabilityDescriptions = map[Capability]string{
+	CapabilityPostRunCommands:     "run post-run commands through bash after rendering",
+	CapabilityNativeExtensions:    "download and execute a native extension binary",
+	CapabilityDeleteFiles:         "delete arbitrary files and directories in the project",
+	CapabilityWriteOutsideProject: "write files outside of the project directory",
+}
+
+// IsValid reports whether c is one of the recognized capabilities.
+func (c Capability) IsValid() bool {
+	_, ok := capabilityDescriptions[c]
+	return ok
+}
+
+// Description returns a human-readable description of what c allows.
+func (c Capability) Description() string {
+	if d, ok := capabilityDescriptions[c]; ok {
+		return d
+	}
+	return "unknown capability"
+}
+
+// RequestedCapabilities returns the capabilities the module needs: the ones
+// declared in the capabilities field, plus the ones implied by the rest of the
+// manifest (post-run commands and native extensions). The result is sorted and
+// contains no duplicates.
+func (m *TemplateRepositoryManifest) RequestedCapabilities() []Capability {
+	caps := slices.Clone(m.Capabilities)
+	if len(m.PostRunCommand) > 0 {
+		caps = append(caps, CapabilityPostRunCommands)
+	}
+	if m.Type.Contains(TemplateRepositoryTypeExt) {
+		caps = append(caps, CapabilityNativeExtensions)
+	}
+
+	sort.Slice(caps, func(i, j int) bool { return caps[i] < caps[j] })
+	return slices.Compact(caps)
+}
+
+// Permissions grants capabilities to modules rendered into a service.
+type Permissions struct {
+	// TrustedModules is a list of module import paths that are granted
+	// every capability they request.
+	TrustedModules []string `yaml:"trustedModules,omitempty"`
+
+	// Modules is a map of module import path to the capabilities granted
+	// to that module.
+	Modules map[string][]Capability `yaml:"modules,omitempty"`
+}
+
+// Granted reports whether module has been granted c. A nil Permissions
+// grants nothing.
+func (p *Permissions) Granted(module string, c Capability) bool {
+	if p == nil {
+		return false
+	}
+
+	if slices.Contains(p.TrustedModules, module) {
+		return true
+	}
+	return slices.Contains(p.Modules[module], c)
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for module capabilities
+
+package configuration_test
+
+import (
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+	"gotest.tools/v3/assert"
+
+	"github.com/getoutreach/stencil/pkg/configuration"
+)
+
+func TestRequestedCapabilities(t *testing.T) {
+	var mf configuration.TemplateRepositoryManifest
+	err := yaml.Unmarshal([]byte(`name: testing
+type: templates,extension
+capabilities: [deleteFiles, postRunCommands]
+postRunCommand:
+- command: echo hello
+`), &mf)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, mf.RequestedCapabilities(), []configuration.Capability{
+		configuration.CapabilityDeleteFiles,
+		configuration.CapabilityNativeExtensions,
+		configuration.CapabilityPostRunCommands,
+	})
+}
+
+func TestRequestedCapabilitiesNone(t *testing.T) {
+	mf := configuration.TemplateRepositoryManifest{Name: "testing"}
+	assert.Equal(t, len(mf.RequestedCapabilities()), 0)
+}
+
+func TestPermissionsGranted(t *testing.T) {
+	var p *configuration.Permissions
+	assert.Assert(t, !p.Granted("testing", configuration.CapabilityPostRunCommands), "nil permissions grant nothing")
+
+	p = &configuration.Permissions{
+		TrustedModules: []string{"trusted"},
+		Modules: map[string][]configuration.Capability{
+			"testing": {configuration.CapabilityPostRunCommands},
+		},
+	}
+	assert.Assert(t, p.Granted("trusted", configuration.CapabilityDeleteFiles))
+	assert.Assert(t, p.Granted("testing", configuration.CapabilityPostRunCommands))
+	assert.Assert(t, !p.Granted("testing", configuration.CapabilityDeleteFiles))
+	assert.Assert(t, !p.Granted("other", configuration.CapabilityPostRunCommands))
+}
+
+func TestCapabilityIsValid(t *testing.T) {
+	assert.Assert(t, configuration.CapabilityWriteOutsideProject.IsValid())
+	assert.Assert(t, !configuration.Capability("everything").IsValid())
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"time"
 
 	"go.yaml.in/yaml/v3"
 )
@@ -33,6 +34,10 @@ type ServiceManifest struct {
 	// Name is the name of the service
 	Name string `yaml:"name"`
 
+	// Extends are the service manifests this service manifest inherits
+	// from, see LoadServiceManifest.
+	Extends []*ServiceManifestBase `yaml:"extends,omitempty"`
+
 	// Arguments is a map of arbitrary arguments to pass to the generator
 	Arguments map[string]any `yaml:"arguments"`
 
@@ -49,10 +54,20 @@ type ServiceManifest struct {
 	// - local file: file://path/to/module
 	// - remote file: https://github.com/getoutreach/stencil-base
 	Replacements map[string]string `yaml:"replacements,omitempty"`
+
+	// Permissions grants modules the capabilities they request in
+	// their manifest.yaml, see Capability.
+	Permissions *Permissions `yaml:"permissions,omitempty"`
+
+	// DefaultBranch is the default branch of the git repository, exposed
+	// to templates as .Git.DefaultBranch. Detected from the remote origin
+	// if not set.
+	DefaultBranch string `yaml:"defaultBranch,omitempty"`
 }
 
 // NewServiceManifest reads a service manifest from disk at the
-// specified path, parses it, and returns the output.
+// specified path, parses it, and returns the output. The service manifests
+// it extends aren't merged into it, see LoadServiceManifest.
 func NewServiceManifest(path string) (*ServiceManifest, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -127,6 +142,22 @@ type TemplateRepositoryManifest struct {
 
 	// Arguments are a declaration of arguments to the template generator
 	Arguments map[string]Argument `yaml:"arguments,omitempty"`
+
+	// Capabilities are the privileged operations this module needs, which
+	// must be granted by the service.yaml before stencil performs them.
+	// Capabilities implied by other fields (e.g. PostRunCommand) do not
+	// need to be listed, see RequestedCapabilities.
+	Capabilities []Capability `yaml:"capabilities,omitempty"`
+
+	// CommentStyles maps file extensions (e.g. ".clj"), or file names, to
+	// an additional comment prefix that block tags can be written after
+	// in files rendered by this module, e.g. ";;". These extend the
+	// default comment styles.
+	CommentStyles map[string]string `yaml:"commentStyles,omitempty"`
+
+	// Archetypes are starter projects offered by `stencil create project`
+	// when creating a project from this module.
+	Archetypes []*Archetype `yaml:"archetypes,omitempty"`
 }
 
 // PostRunCommandSpec is the spec of a command to be ran and its
@@ -138,6 +169,52 @@ type PostRunCommandSpec struct {
 	// Command is the command to be ran, note: this is ran inside
 	// of a bash shell.
 	Command string `yaml:"command"`
+
+	// When is a condition that must be met for this command to be ran,
+	// if not set the command is always ran.
+	When *PostRunCommandCondition `yaml:"when,omitempty"`
+
+	// Dir is the working directory, relative to the project root, that
+	// the command is ran in. Defaults to the project root.
+	Dir string `yaml:"dir,omitempty"`
+
+	// Env is a map of environment variables to set, in addition to the
+	// environment stencil was ran with, when running the command.
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// Timeout is the maximum duration (e.g. "5m") the command is allowed
+	// to run for. Defaults to no timeout.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
+	// After is a list of post-run commands, from any module, that must be
+	// ran before this command. By default commands are ran in module order.
+	After []*PostRunCommandRef `yaml:"after,omitempty"`
+
+	// ContinueOnError denotes that a failure of this command should be
+	// reported, but not stop the remaining commands from being ran or fail
+	// the stencil run.
+	ContinueOnError bool `yaml:"continueOnError,omitempty"`
+}
+
+// PostRunCommandCondition is a condition that determines if a post-run
+// command should be ran.
+type PostRunCommandCondition struct {
+	// FilesChanged is a list of globs, relative to the project root, the
+	// command is only ran if a file matching one of them was created,
+	// updated or deleted by the render. "**" matches any number of
+	// directories, and a glob without a "/" matches the file name in
+	// any directory.
+	FilesChanged []string `yaml:"filesChanged,omitempty"`
+}
+
+// PostRunCommandRef is a reference to the post-run command(s) of a module.
+type PostRunCommandRef struct {
+	// Module is the import path of the module that declares the command
+	Module string `yaml:"module"`
+
+	// Name is the name of the command, if not set all of the module's
+	// commands are referenced.
+	Name string `yaml:"name,omitempty"`
 }
 
 // DeprecationMessage is an argument's deprecation message. It is a string:
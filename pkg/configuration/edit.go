@@ -0,0 +1,329 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements editing a service manifest while
+// preserving its comments and the order of its keys.
+
+package configuration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/getoutreach/stencil/internal/lint/yamlfix"
+)
+
+// ErrInvalidServiceManifestDocument is returned when a service manifest
+// can't be edited, e.g. because it isn't a mapping.
+var ErrInvalidServiceManifestDocument = errors.New("invalid service manifest")
+
+// ServiceManifestDocument is a service manifest parsed into a yaml.Node
+// tree. Unlike ServiceManifest, editing it preserves the comments and the
+// order of the keys in the file.
+type ServiceManifestDocument struct {
+	doc yaml.Node
+}
+
+// LoadServiceManifestDocument reads the service manifest at path for
+// editing.
+func LoadServiceManifestDocument(path string) (*ServiceManifestDocument, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseServiceManifestDocument(b)
+}
+
+// ParseServiceManifestDocument parses b, the contents of a service
+// manifest, for editing.
+func ParseServiceManifestDocument(b []byte) (*ServiceManifestDocument, error) {
+	d := &ServiceManifestDocument{}
+	if err := yaml.Unmarshal(b, &d.doc); err != nil {
+		return nil, fmt.Errorf("failed to parse service manifest: %w", err)
+	}
+
+	// An empty file has no document, start with an empty mapping
+	if d.doc.Kind == 0 {
+		d.doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	if len(d.doc.Content) != 1 || d.doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: expected a mapping", ErrInvalidServiceManifestDocument)
+	}
+	return d, nil
+}
+
+// root returns the top-level mapping of the document.
+func (d *ServiceManifestDocument) root() *yaml.Node {
+	return d.doc.Content[0]
+}
+
+// SetArgument sets the argument at key, in dot notation, to value. Missing
+// parent mappings are created, and existing comments on the key are kept.
+func (d *ServiceManifestDocument) SetArgument(key string, value any) error {
+	var v yaml.Node
+	if err := v.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode argument %q: %w", key, err)
+	}
+
+	m := mappingFor(d.root(), "arguments")
+	if m == nil {
+		return fmt.Errorf("%w: arguments isn't a mapping", ErrInvalidServiceManifestDocument)
+	}
+
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		if m = mappingFor(m, part); m == nil {
+			return fmt.Errorf("%w: argument %q has a parent that isn't a mapping", ErrInvalidServiceManifestDocument, key)
+		}
+	}
+
+	setValue(m, parts[len(parts)-1], &v)
+	return nil
+}
+
+// Argument returns the value of the argument at key, in dot notation, and
+// whether it is set.
+func (d *ServiceManifestDocument) Argument(key string) (any, bool) {
+	n := d.argumentNode(key)
+	if n == nil {
+		return nil, false
+	}
+
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// RemoveArgument removes the argument at key, in dot notation. Returns
+// false if the argument isn't set.
+func (d *ServiceManifestDocument) RemoveArgument(key string) bool {
+	parts := strings.Split(key, ".")
+	parent := lookupValue(d.root(), "arguments")
+	if len(parts) > 1 {
+		parent = d.argumentNode(strings.Join(parts[:len(parts)-1], "."))
+	}
+	if parent == nil || parent.Kind != yaml.MappingNode {
+		return false
+	}
+	return yamlfix.RemoveKey(parent, parts[len(parts)-1]) != nil
+}
+
+// argumentNode returns the value node of the argument at key, in dot
+// notation, or nil if it isn't set.
+func (d *ServiceManifestDocument) argumentNode(key string) *yaml.Node {
+	n := lookupValue(d.root(), "arguments")
+	for _, part := range strings.Split(key, ".") {
+		if n == nil || n.Kind != yaml.MappingNode {
+			return nil
+		}
+		n = lookupValue(n, part)
+	}
+	return n
+}
+
+// AddModule adds m to the modules of the service manifest. If a module
+// with the same name already exists it is updated in place, keeping its
+// comments.
+func (d *ServiceManifestDocument) AddModule(m *TemplateRepository) error {
+	var v yaml.Node
+	if err := v.Encode(m); err != nil {
+		return fmt.Errorf("failed to encode module %q: %w", m.Name, err)
+	}
+
+	mods, err := d.modules()
+	if err != nil {
+		return err
+	}
+
+	existing := findModule(mods, m.Name)
+	if existing == nil {
+		mods.Content = append(mods.Content, &v)
+		return nil
+	}
+
+	// Remove the keys that are no longer set, and then set the rest
+	for _, key := range []string{"prerelease", "url", "channel", "version"} {
+		if yamlfix.FindKey(&v, key) < 0 {
+			yamlfix.RemoveKey(existing, key)
+		}
+	}
+	for i := 0; i+1 < len(v.Content); i += 2 {
+		setValue(existing, v.Content[i].Value, v.Content[i+1])
+	}
+	return nil
+}
+
+// RemoveModule removes the module with the provided name from the
+// modules of the service manifest. Returns false if it isn't present.
+func (d *ServiceManifestDocument) RemoveModule(name string) bool {
+	mods := lookupValue(d.root(), "modules")
+	if mods == nil || mods.Kind != yaml.SequenceNode {
+		return false
+	}
+
+	for i, n := range mods.Content {
+		if moduleName(n) == name {
+			mods.Content = append(mods.Content[:i], mods.Content[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// modules returns the sequence of modules, creating it if it doesn't
+// exist.
+func (d *ServiceManifestDocument) modules() (*yaml.Node, error) {
+	root := d.root()
+	i := yamlfix.FindKey(root, "modules")
+	if i < 0 || root.Content[i+1].ShortTag() == "!!null" {
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if i < 0 {
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "modules"}, seq)
+		} else {
+			root.Content[i+1] = seq
+		}
+		return seq, nil
+	}
+
+	mods := yamlfix.Deref(root.Content[i+1])
+	if mods.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%w: modules isn't a list", ErrInvalidServiceManifestDocument)
+	}
+	return mods, nil
+}
+
+// findModule returns the mapping of the module with the provided name in
+// mods, or nil.
+func findModule(mods *yaml.Node, name string) *yaml.Node {
+	for _, n := range mods.Content {
+		if moduleName(n) == name {
+			return yamlfix.Deref(n)
+		}
+	}
+	return nil
+}
+
+// moduleName returns the name of the module n, a mapping in the modules
+// list.
+func moduleName(n *yaml.Node) string {
+	n = yamlfix.Deref(n)
+	if n.Kind != yaml.MappingNode {
+		return ""
+	}
+	if name := lookupValue(n, "name"); name != nil {
+		return name.Value
+	}
+	return ""
+}
+
+// SetReplacement replaces the module with the provided name with target,
+// a path or URL.
+func (d *ServiceManifestDocument) SetReplacement(name, target string) error {
+	m := mappingFor(d.root(), "replacements")
+	if m == nil {
+		return fmt.Errorf("%w: replacements isn't a mapping", ErrInvalidServiceManifestDocument)
+	}
+
+	setValue(m, name, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: target})
+	return nil
+}
+
+// RemoveReplacement removes the replacement of the module with the
+// provided name. Returns false if there is none.
+func (d *ServiceManifestDocument) RemoveReplacement(name string) bool {
+	m := lookupValue(d.root(), "replacements")
+	if m == nil || m.Kind != yaml.MappingNode {
+		return false
+	}
+	return yamlfix.RemoveKey(m, name) != nil
+}
+
+// Bytes returns the encoded service manifest.
+func (d *ServiceManifestDocument) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&d.doc); err != nil {
+		return nil, fmt.Errorf("failed to encode service manifest: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode service manifest: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ServiceManifest returns the service manifest decoded from the document,
+// without the service manifests it extends merged into it.
+func (d *ServiceManifestDocument) ServiceManifest() (*ServiceManifest, error) {
+	var s ServiceManifest
+	if err := d.doc.Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to decode service manifest: %w", err)
+	}
+	if !ValidateName(s.Name) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, s.Name)
+	}
+	return &s, nil
+}
+
+// Save writes the service manifest to path, keeping the permissions of
+// the existing file.
+func (d *ServiceManifestDocument) Save(path string) error {
+	b, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.WriteFile(path, b, perm); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+	return nil
+}
+
+// lookupValue returns the value stored under key in mapping m, or nil.
+func lookupValue(m *yaml.Node, key string) *yaml.Node {
+	i := yamlfix.FindKey(m, key)
+	if i < 0 {
+		return nil
+	}
+	return yamlfix.Deref(m.Content[i+1])
+}
+
+// mappingFor returns the mapping stored under key in m, creating it if it
+// doesn't exist or is null. Returns nil if key holds any other value.
+func mappingFor(m *yaml.Node, key string) *yaml.Node {
+	i := yamlfix.FindKey(m, key)
+	if i >= 0 && m.Content[i+1].ShortTag() == "!!null" {
+		m.Content[i+1] = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	v := yamlfix.Deref(yamlfix.EnsureMapping(m, key))
+	if v.Kind != yaml.MappingNode {
+		return nil
+	}
+	return v
+}
+
+// setValue sets key in mapping m to v, replacing the existing value while
+// keeping its comments, or appending it if it doesn't exist.
+func setValue(m *yaml.Node, key string, v *yaml.Node) {
+	i := yamlfix.FindKey(m, key)
+	if i < 0 {
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, v)
+		return
+	}
+
+	old := m.Content[i+1]
+	v.HeadComment, v.LineComment, v.FootComment = old.HeadComment, old.LineComment, old.FootComment
+	m.Content[i+1] = v
+}
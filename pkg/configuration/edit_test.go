@@ -0,0 +1,126 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for editing service manifests.
+
+package configuration_test
+
+import (
+	"testing"
+
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"gotest.tools/v3/assert"
+)
+
+func TestServiceManifestDocumentSetArgument(t *testing.T) {
+	doc, err := configuration.ParseServiceManifestDocument([]byte(`# The name of the service
+name: test
+arguments:
+  # Set by the platform team
+  team: platform # don't change
+  nested: {}
+modules:
+  - name: github.com/getoutreach/stencil-base
+`))
+	assert.NilError(t, err)
+
+	assert.NilError(t, doc.SetArgument("team", "devx"))
+	assert.NilError(t, doc.SetArgument("nested.port", 8080))
+	assert.NilError(t, doc.SetArgument("list", []string{"a", "b"}))
+
+	b, err := doc.Bytes()
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), `# The name of the service
+name: test
+arguments:
+  # Set by the platform team
+  team: devx # don't change
+  nested: {port: 8080}
+  list:
+    - a
+    - b
+modules:
+  - name: github.com/getoutreach/stencil-base
+`)
+}
+
+func TestServiceManifestDocumentSetArgumentCreatesArguments(t *testing.T) {
+	doc, err := configuration.ParseServiceManifestDocument([]byte("name: test\n"))
+	assert.NilError(t, err)
+
+	assert.NilError(t, doc.SetArgument("a.b", true))
+
+	b, err := doc.Bytes()
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "name: test\narguments:\n  a:\n    b: true\n")
+}
+
+func TestServiceManifestDocumentSetArgumentScalarParent(t *testing.T) {
+	doc, err := configuration.ParseServiceManifestDocument([]byte("arguments:\n  a: 1\n"))
+	assert.NilError(t, err)
+
+	assert.ErrorIs(t, doc.SetArgument("a.b", true), configuration.ErrInvalidServiceManifestDocument)
+}
+
+func TestServiceManifestDocumentRemoveArgument(t *testing.T) {
+	doc, err := configuration.ParseServiceManifestDocument([]byte("arguments:\n  a:\n    b: 1\n    c: 2\n  d: 3\n"))
+	assert.NilError(t, err)
+
+	assert.Assert(t, doc.RemoveArgument("a.b"))
+	assert.Assert(t, doc.RemoveArgument("d"))
+	assert.Assert(t, !doc.RemoveArgument("missing"))
+	assert.Assert(t, !doc.RemoveArgument("a.c.d"))
+
+	v, ok := doc.Argument("a.c")
+	assert.Assert(t, ok)
+	assert.Equal(t, v, 2)
+
+	b, err := doc.Bytes()
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "arguments:\n  a:\n    c: 2\n")
+}
+
+func TestServiceManifestDocumentModules(t *testing.T) {
+	doc, err := configuration.ParseServiceManifestDocument([]byte(`name: test
+modules:
+  # Pinned until the migration is done
+  - name: github.com/getoutreach/stencil-base
+    version: v1.0.0 # see the migration doc
+  - name: github.com/getoutreach/stencil-golang
+replacements:
+  github.com/getoutreach/stencil-golang: ../stencil-golang
+`))
+	assert.NilError(t, err)
+
+	assert.NilError(t, doc.AddModule(&configuration.TemplateRepository{
+		Name: "github.com/getoutreach/stencil-base", Version: "v2.0.0", Channel: "rc",
+	}))
+	assert.NilError(t, doc.AddModule(&configuration.TemplateRepository{Name: "github.com/getoutreach/stencil-circleci"}))
+	assert.Assert(t, doc.RemoveModule("github.com/getoutreach/stencil-golang"))
+	assert.Assert(t, !doc.RemoveModule("github.com/getoutreach/stencil-golang"))
+	assert.Assert(t, doc.RemoveReplacement("github.com/getoutreach/stencil-golang"))
+	assert.NilError(t, doc.SetReplacement("github.com/getoutreach/stencil-base", "../stencil-base"))
+
+	b, err := doc.Bytes()
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), `name: test
+modules:
+  # Pinned until the migration is done
+  - name: github.com/getoutreach/stencil-base
+    version: v2.0.0 # see the migration doc
+    channel: rc
+  - name: github.com/getoutreach/stencil-circleci
+replacements:
+  github.com/getoutreach/stencil-base: ../stencil-base
+`)
+}
+
+func TestServiceManifestDocumentAddModuleCreatesModules(t *testing.T) {
+	doc, err := configuration.ParseServiceManifestDocument([]byte("name: test\nmodules:\n"))
+	assert.NilError(t, err)
+
+	assert.NilError(t, doc.AddModule(&configuration.TemplateRepository{Name: "github.com/getoutreach/stencil-base"}))
+
+	b, err := doc.Bytes()
+	assert.NilError(t, err)
+	assert.Equal(t, string(b), "name: test\nmodules:\n  - name: github.com/getoutreach/stencil-base\n")
+}
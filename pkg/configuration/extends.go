@@ -0,0 +1,431 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file implements layering service manifests, a service
+// manifest inheriting from the service manifests it extends.
+
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/getoutreach/stencil/internal/dotnotation"
+)
+
+// ArgumentRefKey is the key of a mapping, in the arguments of a service
+// manifest, that is replaced by the contents of the file it references.
+const ArgumentRefKey = "$ref"
+
+// ArgumentReferencePattern matches references to environment variables
+// and secrets in the strings of the arguments of a service manifest, e.g.
+// ${env:FOO}, ${env:FOO:-default} or ${secret:path/to/secret}.
+// Capture groups: 1=source, 2=name, 3=":-default" or "", 4=default.
+const ArgumentReferencePattern = `\$\{(env|secret):([^}:]+)(:-([^}]*))?\}`
+
+// ErrServiceManifestBase is returned when a service manifest extended by
+// another can't be loaded.
+var ErrServiceManifestBase = errors.New("failed to load extended service manifest")
+
+// ServiceManifestBase is a service manifest extended by another, either
+// a path on disk or a file inside of a module. In YAML it can be written
+// as a string, which is treated as Path.
+type ServiceManifestBase struct {
+	// Path is the path to the service manifest. Relative paths are
+	// relative to the extending service manifest, or to the root of
+	// Module if set.
+	Path string `yaml:"path"`
+
+	// Module is the import path of the module containing the service
+	// manifest, e.g. github.com/getoutreach/stencil-base.
+	Module string `yaml:"module,omitempty"`
+
+	// Version is the version of Module to read the service manifest
+	// from, the latest version if not set.
+	Version string `yaml:"version,omitempty"`
+}
+
+// UnmarshalYAML allows a ServiceManifestBase to be written as a path.
+func (b *ServiceManifestBase) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&b.Path)
+	}
+
+	// Use a type without UnmarshalYAML to avoid recursing
+	type base ServiceManifestBase
+	return value.Decode((*base)(b))
+}
+
+// String returns a human readable reference to the base.
+func (b *ServiceManifestBase) String() string {
+	if b.Module == "" {
+		return b.Path
+	}
+	if b.Version == "" {
+		return b.Module + "//" + b.Path
+	}
+	return b.Module + "//" + b.Path + "@" + b.Version
+}
+
+// ModuleFileReader returns the contents of the file at path, relative to
+// the root of the provided version of module. An empty version is the
+// latest version.
+type ModuleFileReader func(module, version, path string) ([]byte, error)
+
+// LoadServiceManifest reads the service manifest at path, like
+// NewServiceManifest, and merges the service manifests it extends into
+// it. readModuleFile is used to read the service manifests that are
+// inside of modules, and may be nil if none are.
+//
+// Bases are merged in order, with each later base, and finally the
+// extending service manifest, taking precedence:
+//
+//   - name is never inherited.
+//   - arguments are deep merged, maps are merged key by key and every
+//     other value, including lists, is replaced.
+//   - modules are merged by name, a module replaces a module of the same
+//     name from a base.
+//   - defaultBranch is replaced if set.
+//   - versions and replacements are merged key by key.
+//   - permissions are combined, except for the permissions of service
+//     manifests inside of modules, which are ignored so that a module
+//     can't grant capabilities to itself or other modules.
+//
+// The $ref references in the arguments of a base are made relative to
+// the base, not the extending service manifest. References in bases on
+// disk are made absolute, and references in bases inside of modules are
+// replaced by the contents of the file they reference.
+func LoadServiceManifest(path string, readModuleFile ModuleFileReader) (*ServiceManifest, error) {
+	s, err := NewServiceManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	return ResolveServiceManifestBases(s, path, readModuleFile)
+}
+
+// ResolveServiceManifestBases returns the result of merging the service
+// manifests s, read from path, extends into it, see LoadServiceManifest.
+// s is returned as-is if it doesn't extend any service manifest.
+func ResolveServiceManifestBases(s *ServiceManifest, path string, readModuleFile ModuleFileReader) (*ServiceManifest, error) {
+	if len(s.Extends) == 0 {
+		return s, nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := baseLocation{file: abs}
+	r := &baseResolver{readModuleFile: readModuleFile, stack: []baseLocation{loc}}
+	merged, err := r.resolve(s, loc)
+	if err != nil {
+		return nil, err
+	}
+	merged.Name = s.Name
+	return merged, nil
+}
+
+// baseLocation is where a service manifest was read from, used to
+// resolve the relative paths of the service manifests it extends.
+type baseLocation struct {
+	// module and version are the module the service manifest is in,
+	// empty if it's on disk.
+	module, version string
+
+	// file is the path to the service manifest, relative to the root of
+	// module if set.
+	file string
+}
+
+// dir returns the directory containing the service manifest.
+func (l baseLocation) dir() string {
+	if l.module != "" {
+		return path.Dir(l.file)
+	}
+	return filepath.Dir(l.file)
+}
+
+// baseResolver resolves the service manifests extended by a service
+// manifest.
+type baseResolver struct {
+	readModuleFile ModuleFileReader
+
+	// stack is the service manifests currently being resolved, used to
+	// detect cycles
+	stack []baseLocation
+
+	// refs are the files referenced by $ref, inside of modules, currently
+	// being read, used to detect cycles
+	refs []baseLocation
+}
+
+// resolve returns s with all of its bases, read relative to loc, merged
+// into it.
+func (r *baseResolver) resolve(s *ServiceManifest, loc baseLocation) (*ServiceManifest, error) {
+	merged := &ServiceManifest{}
+	for _, b := range s.Extends {
+		baseLoc := locate(b, loc)
+		if slices.Contains(r.stack, baseLoc) {
+			return nil, fmt.Errorf("%w: %s extends itself", ErrServiceManifestBase, b)
+		}
+
+		base, err := r.load(b, baseLoc)
+		if err != nil {
+			return nil, err
+		}
+
+		if base.Arguments != nil {
+			args, err := r.rebaseReferences(base.Arguments, baseLoc)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrServiceManifestBase, b, err)
+			}
+			base.Arguments, _ = args.(map[string]any)
+		}
+
+		r.stack = append(r.stack, baseLoc)
+		base, err = r.resolve(base, baseLoc)
+		r.stack = r.stack[:len(r.stack)-1]
+		if err != nil {
+			return nil, err
+		}
+
+		// Capabilities must be granted by the project, not by a module,
+		// every base extended by a base in a module is in a module too
+		if baseLoc.module != "" {
+			base.Permissions = nil
+		}
+
+		mergeServiceManifest(merged, base)
+	}
+
+	mergeServiceManifest(merged, s)
+	merged.Extends = nil
+	return merged, nil
+}
+
+// locate returns the location of b, extended by the service manifest at
+// loc.
+func locate(b *ServiceManifestBase, loc baseLocation) baseLocation {
+	switch {
+	case b.Module != "":
+		return baseLocation{module: b.Module, version: b.Version, file: path.Clean(b.Path)}
+	case loc.module != "":
+		// Relative to the root of the module containing the extending
+		// service manifest
+		return baseLocation{module: loc.module, version: loc.version, file: path.Join(loc.dir(), b.Path)}
+	case filepath.IsAbs(b.Path):
+		return baseLocation{file: b.Path}
+	default:
+		return baseLocation{file: filepath.Join(loc.dir(), b.Path)}
+	}
+}
+
+// load reads the service manifest b from loc.
+func (r *baseResolver) load(b *ServiceManifestBase, loc baseLocation) (*ServiceManifest, error) {
+	var data []byte
+	var err error
+	if loc.module != "" {
+		if r.readModuleFile == nil {
+			return nil, fmt.Errorf("%w: %s: reading service manifests from modules is not supported here",
+				ErrServiceManifestBase, b)
+		}
+		data, err = r.readModuleFile(loc.module, loc.version, loc.file)
+	} else {
+		data, err = os.ReadFile(loc.file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrServiceManifestBase, b, err)
+	}
+
+	var s ServiceManifest
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrServiceManifestBase, b, err)
+	}
+	return &s, nil
+}
+
+// rebaseReferences returns v, the arguments of the service manifest at
+// loc, with the relative paths of its $ref references made resolvable
+// from the extending service manifest. References in service manifests
+// on disk are made absolute. References in service manifests inside of
+// modules are replaced by the contents of the file, read from the module,
+// with the other keys of the mapping merged on top, since they can't be
+// read from disk.
+func (r *baseResolver) rebaseReferences(v any, loc baseLocation) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			if k == ArgumentRefKey {
+				continue
+			}
+
+			var err error
+			if out[k], err = r.rebaseReferences(e, loc); err != nil {
+				return nil, err
+			}
+		}
+
+		ref, ok := t[ArgumentRefKey].(string)
+		if !ok {
+			if refV, exists := t[ArgumentRefKey]; exists {
+				out[ArgumentRefKey] = refV
+			}
+			return out, nil
+		}
+
+		p, selector, hasSelector := strings.Cut(ref, "#")
+		if loc.module == "" {
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(loc.dir(), p)
+			}
+			if hasSelector {
+				p += "#" + selector
+			}
+			out[ArgumentRefKey] = p
+			return out, nil
+		}
+
+		refV, err := r.readReference(loc, p, selector)
+		if err != nil {
+			return nil, err
+		}
+		refMap, ok := refV.(map[string]any)
+		switch {
+		case len(out) == 0:
+			return refV, nil
+		case ok:
+			return mergeArguments(refMap, out), nil
+		default:
+			return out, nil
+		}
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			var err error
+			if out[i], err = r.rebaseReferences(e, loc); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// readReference returns the contents of the file at p, relative to the
+// service manifest at loc inside of a module, or the key selector of it
+// if set. References in the file are rebased relative to it.
+func (r *baseResolver) readReference(loc baseLocation, p, selector string) (any, error) {
+	refLoc := baseLocation{module: loc.module, version: loc.version, file: path.Join(loc.dir(), p)}
+	if slices.Contains(r.refs, refLoc) {
+		return nil, fmt.Errorf("%s references itself", refLoc.file)
+	}
+	r.refs = append(r.refs, refLoc)
+	defer func() { r.refs = r.refs[:len(r.refs)-1] }()
+
+	data, err := r.readModuleFile(refLoc.module, refLoc.version, refLoc.file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", refLoc.file, err)
+	}
+
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", refLoc.file, err)
+	}
+	if selector != "" {
+		if v, err = dotnotation.Get(v, selector); err != nil {
+			return nil, fmt.Errorf("%q in %s: %w", selector, refLoc.file, err)
+		}
+	}
+	return r.rebaseReferences(v, refLoc)
+}
+
+// mergeServiceManifest merges src into dst, with src taking precedence.
+// The name isn't merged.
+func mergeServiceManifest(dst, src *ServiceManifest) {
+	if src.Arguments != nil {
+		dst.Arguments = mergeArguments(dst.Arguments, src.Arguments)
+	}
+
+	for _, m := range src.Modules {
+		i := slices.IndexFunc(dst.Modules, func(e *TemplateRepository) bool { return e.Name == m.Name })
+		if i < 0 {
+			dst.Modules = append(dst.Modules, m)
+			continue
+		}
+		dst.Modules[i] = m
+	}
+
+	if src.DefaultBranch != "" {
+		dst.DefaultBranch = src.DefaultBranch
+	}
+
+	dst.Versions = mergeStrings(dst.Versions, src.Versions)
+	dst.Replacements = mergeStrings(dst.Replacements, src.Replacements)
+
+	if src.Permissions != nil {
+		if dst.Permissions == nil {
+			dst.Permissions = &Permissions{}
+		}
+		for _, m := range src.Permissions.TrustedModules {
+			if !slices.Contains(dst.Permissions.TrustedModules, m) {
+				dst.Permissions.TrustedModules = append(dst.Permissions.TrustedModules, m)
+			}
+		}
+		for m, caps := range src.Permissions.Modules {
+			if dst.Permissions.Modules == nil {
+				dst.Permissions.Modules = make(map[string][]Capability)
+			}
+			for _, c := range caps {
+				if !slices.Contains(dst.Permissions.Modules[m], c) {
+					dst.Permissions.Modules[m] = append(dst.Permissions.Modules[m], c)
+				}
+			}
+		}
+	}
+}
+
+// mergeArguments returns the result of deep merging src into dst, with
+// src taking precedence. Only maps are merged, every other value is
+// replaced.
+func mergeArguments(dst, src map[string]any) map[string]any {
+	out := make(map[string]any, len(dst)+len(src))
+	for k, v := range dst {
+		out[k] = v
+	}
+	for k, v := range src {
+		srcMap, srcOk := v.(map[string]any)
+		dstMap, dstOk := out[k].(map[string]any)
+		if srcOk && dstOk {
+			out[k] = mergeArguments(dstMap, srcMap)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// mergeStrings returns the result of merging src into dst, with src
+// taking precedence.
+func mergeStrings(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+
+	out := make(map[string]string, len(dst)+len(src))
+	for k, v := range dst {
+		out[k] = v
+	}
+	for k, v := range src {
+		out[k] = v
+	}
+	return out
+}
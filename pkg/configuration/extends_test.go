@@ -0,0 +1,224 @@
+// Copyright 2026 Outreach Corporation. Licensed under the Apache License 2.0.
+
+// Description: This file contains tests for layering service manifests.
+
+package configuration_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getoutreach/stencil/pkg/configuration"
+	"gotest.tools/v3/assert"
+)
+
+// writeFile writes contents to path, relative to dir, creating any
+// missing parent directories.
+func writeFile(t *testing.T, dir, path, contents string) {
+	t.Helper()
+
+	path = filepath.Join(dir, path)
+	assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NilError(t, os.WriteFile(path, []byte(contents), 0o644))
+}
+
+func TestLoadServiceManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "shared/base.yaml", `name: base
+extends:
+  - ./go.yaml
+arguments:
+  team: platform
+  ports: [80, 443]
+  aws:
+    region: us-east-1
+    account: "123"
+modules:
+  - name: github.com/getoutreach/stencil-base
+    version: ">=1.0.0"
+versions:
+  go: "1.22"
+permissions:
+  trustedModules:
+    - github.com/getoutreach/stencil-base
+`)
+	writeFile(t, dir, "shared/go.yaml", `modules:
+  - name: github.com/getoutreach/stencil-golang
+replacements:
+  github.com/getoutreach/stencil-golang: ../stencil-golang
+defaultBranch: develop
+`)
+	writeFile(t, dir, "services/a/service.yaml", `name: a
+extends:
+  - ../../shared/base.yaml
+arguments:
+  ports: [8080]
+  aws:
+    region: eu-west-1
+modules:
+  - name: github.com/getoutreach/stencil-base
+    version: ">=2.0.0"
+  - name: github.com/getoutreach/stencil-circleci
+versions:
+  node: "20"
+permissions:
+  trustedModules:
+    - github.com/getoutreach/stencil-golang
+`)
+
+	s, err := configuration.LoadServiceManifest(filepath.Join(dir, "services/a/service.yaml"), nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s, &configuration.ServiceManifest{
+		Name: "a",
+		Arguments: map[string]any{
+			"team":  "platform",
+			"ports": []any{8080},
+			"aws":   map[string]any{"region": "eu-west-1", "account": "123"},
+		},
+		Modules: []*configuration.TemplateRepository{
+			{Name: "github.com/getoutreach/stencil-golang"},
+			{Name: "github.com/getoutreach/stencil-base", Version: ">=2.0.0"},
+			{Name: "github.com/getoutreach/stencil-circleci"},
+		},
+		Versions:      map[string]string{"go": "1.22", "node": "20"},
+		Replacements:  map[string]string{"github.com/getoutreach/stencil-golang": "../stencil-golang"},
+		DefaultBranch: "develop",
+		Permissions: &configuration.Permissions{
+			TrustedModules: []string{"github.com/getoutreach/stencil-base", "github.com/getoutreach/stencil-golang"},
+		},
+	})
+}
+
+func TestLoadServiceManifestFromModule(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "service.yaml", `name: a
+extends:
+  - module: github.com/getoutreach/stencil-base
+    path: bases/service.yaml
+    version: v1.0.0
+`)
+
+	files := map[string]string{
+		"bases/service.yaml": "extends: [common.yaml]\narguments:\n  team: platform\n",
+		"bases/common.yaml":  "arguments:\n  lang: go\n",
+	}
+	var read []string
+	readModuleFile := func(module, version, path string) ([]byte, error) {
+		read = append(read, module+"@"+version+":"+path)
+		if v, ok := files[path]; ok {
+			return []byte(v), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	s, err := configuration.LoadServiceManifest(filepath.Join(dir, "service.yaml"), readModuleFile)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s.Arguments, map[string]any{"team": "platform", "lang": "go"})
+	assert.DeepEqual(t, read, []string{
+		"github.com/getoutreach/stencil-base@v1.0.0:bases/service.yaml",
+		"github.com/getoutreach/stencil-base@v1.0.0:bases/common.yaml",
+	})
+
+	_, err = configuration.LoadServiceManifest(filepath.Join(dir, "service.yaml"), nil)
+	assert.Assert(t, errors.Is(err, configuration.ErrServiceManifestBase))
+}
+
+func TestLoadServiceManifestModuleCannotGrantPermissions(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "service.yaml", `name: a
+extends:
+  - module: github.com/getoutreach/stencil-base
+    path: bases/service.yaml
+permissions:
+  modules:
+    github.com/getoutreach/stencil-golang: [postRunCommands]
+`)
+
+	files := map[string]string{
+		"bases/service.yaml": "extends: [common.yaml]\npermissions:\n  trustedModules: [github.com/getoutreach/stencil-base]\n",
+		"bases/common.yaml": "permissions:\n  modules:\n    github.com/getoutreach/stencil-golang: [nativeExtensions]\n" +
+			"    github.com/evil/module: [writeOutsideProject]\n",
+	}
+	readModuleFile := func(_, _, path string) ([]byte, error) {
+		if v, ok := files[path]; ok {
+			return []byte(v), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	s, err := configuration.LoadServiceManifest(filepath.Join(dir, "service.yaml"), readModuleFile)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s.Permissions, &configuration.Permissions{
+		Modules: map[string][]configuration.Capability{
+			"github.com/getoutreach/stencil-golang": {configuration.CapabilityPostRunCommands},
+		},
+	})
+}
+
+func TestLoadServiceManifestCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "service.yaml", "name: a\nextends: [base.yaml]\n")
+	writeFile(t, dir, "base.yaml", "extends: [service.yaml]\n")
+
+	_, err := configuration.LoadServiceManifest(filepath.Join(dir, "service.yaml"), nil)
+	assert.Assert(t, errors.Is(err, configuration.ErrServiceManifestBase))
+	assert.ErrorContains(t, err, "service.yaml extends itself")
+}
+
+func TestLoadServiceManifestBaseReferences(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "shared/base.yaml", "arguments:\n  db:\n    $ref: args.yaml#db\n  token: ${env:TOKEN}\n")
+	writeFile(t, dir, "service.yaml", `name: a
+extends:
+  - shared/base.yaml
+  - module: github.com/getoutreach/stencil-base
+    path: bases/service.yaml
+arguments:
+  local:
+    $ref: local.yaml
+`)
+
+	files := map[string]string{
+		"bases/service.yaml":    "arguments:\n  aws:\n    $ref: config/aws.yaml\n    account: \"456\"\n",
+		"bases/config/aws.yaml": "region: us-east-1\naccount: \"123\"\nlogs:\n  $ref: ../logs.yaml#bucket\n",
+		"bases/logs.yaml":       "bucket: logs\n",
+	}
+	readModuleFile := func(_, _, path string) ([]byte, error) {
+		if v, ok := files[path]; ok {
+			return []byte(v), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	s, err := configuration.LoadServiceManifest(filepath.Join(dir, "service.yaml"), readModuleFile)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s.Arguments, map[string]any{
+		"db":    map[string]any{"$ref": filepath.Join(dir, "shared", "args.yaml") + "#db"},
+		"token": "${env:TOKEN}",
+		"aws":   map[string]any{"region": "us-east-1", "account": "456", "logs": "logs"},
+		"local": map[string]any{"$ref": "local.yaml"},
+	})
+}
+
+func TestLoadServiceManifestBaseReferenceCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "service.yaml", `name: a
+extends:
+  - module: github.com/getoutreach/stencil-base
+    path: service.yaml
+`)
+
+	files := map[string]string{
+		"service.yaml": "arguments:\n  a:\n    $ref: a.yaml\n",
+		"a.yaml":       "$ref: a.yaml\n",
+	}
+	readModuleFile := func(_, _, path string) ([]byte, error) {
+		return []byte(files[path]), nil
+	}
+
+	_, err := configuration.LoadServiceManifest(filepath.Join(dir, "service.yaml"), readModuleFile)
+	assert.Assert(t, errors.Is(err, configuration.ErrServiceManifestBase))
+	assert.ErrorContains(t, err, "a.yaml references itself")
+}
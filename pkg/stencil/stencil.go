@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/getoutreach/stencil/pkg/configuration"
 	"go.yaml.in/yaml/v3"
 )
 
@@ -36,6 +37,10 @@ type LockfileModuleEntry struct {
 	// Version is the version of the module that was
 	// downloaded at the time.
 	Version string
+
+	// Capabilities are the capabilities that were granted to
+	// the module the last time stencil was ran.
+	Capabilities []configuration.Capability `yaml:",omitempty"`
 }
 
 // LockfileFileEntry is an entry in the lockfile for a file
@@ -52,8 +57,35 @@ type LockfileFileEntry struct {
 
 	// Module is the URL of the module that generated this file.
 	Module string
+
+	// Type is the type of the generated file, an empty type denotes
+	// a file rendered from a template.
+	Type LockfileFileType `yaml:",omitempty"`
+
+	// Target is the target of the symlink, only set when Type is
+	// LockfileFileTypeSymlink.
+	Target string `yaml:",omitempty"`
+
+	// Keys are the keys of a structured file merged via file.Merge that
+	// were rendered by its fragments, as JSON pointers (RFC 6901). Keys
+	// that are no longer rendered are removed from the file on the next
+	// run.
+	Keys []string `yaml:",omitempty"`
 }
 
+// LockfileFileType is the type of a file generated by stencil.
+type LockfileFileType string
+
+// This block contains all of the LockfileFileType values.
+const (
+	// LockfileFileTypeAsset denotes a non-templated asset that was
+	// copied verbatim from a module's assets/ directory.
+	LockfileFileTypeAsset LockfileFileType = "asset"
+
+	// LockfileFileTypeSymlink denotes a symlink created by file.Symlink.
+	LockfileFileTypeSymlink LockfileFileType = "symlink"
+)
+
 // Lockfile is generated by stencil on a ran to store version
 // information.
 type Lockfile struct {